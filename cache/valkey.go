@@ -1,8 +1,7 @@
 package cache
 
 import (
-	"log/slog"
-	"os"
+	"fmt"
 
 	"github.com/assaidy/url_shortener/config"
 	"github.com/valkey-io/valkey-go"
@@ -10,12 +9,13 @@ import (
 
 var Valkey valkey.Client
 
-func init() {
-	client, err := valkey.NewClient(valkey.ClientOption{InitAddress: []string{config.ValkeyAddr}})
+// Connect creates the Valkey client. It must be called before starting the services.
+func Connect() error {
+	client, err := valkey.NewClient(valkey.ClientOption{InitAddress: []string{config.Cfg.Valkey.Addr}})
 	if err != nil {
-		slog.Error("error connecting to valkey server", "err", err)
-		os.Exit(1)
+		return fmt.Errorf("error connecting to valkey server: %w", err)
 	}
 
 	Valkey = client
+	return nil
 }
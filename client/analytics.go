@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type DailyStats struct {
+	Day    time.Time `json:"day"`
+	Visits int64     `json:"visits"`
+}
+
+type VariantStats struct {
+	Name           string `json:"name"`
+	Visits         int64  `json:"visits"`
+	UniqueVisitors int64  `json:"uniqueVisitors"`
+}
+
+type SourceStats struct {
+	Source         string `json:"source"`
+	Visits         int64  `json:"visits"`
+	UniqueVisitors int64  `json:"uniqueVisitors"`
+}
+
+type ShortUrlStats struct {
+	ShortUrl       string         `json:"shortUrl"`
+	LongUrl        string         `json:"longUrl"`
+	Campaign       string         `json:"campaign"`
+	Visits         int64          `json:"visits"`
+	UniqueVisitors int64          `json:"uniqueVisitors"`
+	DailyVisits    []DailyStats   `json:"dailyVisits"`
+	Variants       []VariantStats `json:"variants"`
+	Sources        []SourceStats  `json:"sources"`
+}
+
+type CampaignStats struct {
+	Name           string `json:"name"`
+	ShortUrls      int64  `json:"shortUrls"`
+	Visits         int64  `json:"visits"`
+	UniqueVisitors int64  `json:"uniqueVisitors"`
+}
+
+// StatsParams filters the visits stats are computed from. Zero fields aren't filtered on.
+type StatsParams struct {
+	Domain   string
+	Campaign string // only for GetShortUrlsStats
+	From     time.Time
+	To       time.Time
+}
+
+func (me StatsParams) query() url.Values {
+	query := url.Values{}
+	if me.Domain != "" {
+		query.Set("domain", me.Domain)
+	}
+	if me.Campaign != "" {
+		query.Set("campaign", me.Campaign)
+	}
+	if !me.From.IsZero() {
+		query.Set("from", me.From.Format(time.RFC3339))
+	}
+	if !me.To.IsZero() {
+		query.Set("to", me.To.Format(time.RFC3339))
+	}
+	return query
+}
+
+func (me *Client) GetShortUrlStats(ctx context.Context, shortUrl string, params StatsParams) (ShortUrlStats, error) {
+	var stats ShortUrlStats
+	if err := me.do(ctx, http.MethodGet, "/analytics/urls/"+url.PathEscape(shortUrl), params.query(), nil, &stats); err != nil {
+		return ShortUrlStats{}, err
+	}
+	return stats, nil
+}
+
+func (me *Client) GetShortUrlsStats(ctx context.Context, params StatsParams) ([]ShortUrlStats, error) {
+	var stats []ShortUrlStats
+	if err := me.do(ctx, http.MethodGet, "/analytics/urls", params.query(), nil, &stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (me *Client) GetCampaignsStats(ctx context.Context, params StatsParams) ([]CampaignStats, error) {
+	var stats []CampaignStats
+	if err := me.do(ctx, http.MethodGet, "/analytics/campaigns", params.query(), nil, &stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
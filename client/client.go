@@ -0,0 +1,137 @@
+// Package client is a typed Go client of the http api, see openapi/openapi.json.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/assaidy/url_shortener/utils"
+)
+
+const apiPrefix = "/api/v1"
+
+type Client struct {
+	baseUrl    string
+	httpClient *http.Client
+	token      string
+}
+
+type Option func(*Client)
+
+// WithHttpClient sends requests with httpClient instead of http.DefaultClient.
+func WithHttpClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithToken authenticates requests with a JWT from an earlier login.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// New returns a client of the server at baseUrl, e.g. https://sho.rt.
+func New(baseUrl string, options ...Option) *Client {
+	c := &Client{
+		baseUrl:    strings.TrimSuffix(baseUrl, "/"),
+		httpClient: http.DefaultClient,
+	}
+	for _, it := range options {
+		it(c)
+	}
+	return c
+}
+
+// Token returns the JWT requests are authenticated with, set by Login or WithToken.
+func (me *Client) Token() string {
+	return me.token
+}
+
+// Error is the problem+json body of a failed request.
+type Error struct {
+	Status int                `json:"status"`
+	Code   string             `json:"code"` // e.g. validation_failed, stable across releases
+	Title  string             `json:"title"`
+	Detail string             `json:"detail"`
+	Errors []utils.FieldError `json:"errors"` // the invalid fields of validation_failed errors
+}
+
+func (me *Error) Error() string {
+	if me.Detail == "" {
+		return fmt.Sprintf("%d %s", me.Status, me.Title)
+	}
+	return fmt.Sprintf("%d %s: %s", me.Status, me.Title, me.Detail)
+}
+
+// do sends a request to the api with body encoded as json, and decodes the response into result
+// when it isn't nil. Responses with an error status are returned as *Error.
+func (me *Client) do(ctx context.Context, method string, path string, query url.Values, body any, result any) error {
+	var bodyReader io.Reader
+	contentType := ""
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("error encoding request body: %w", err)
+		}
+		bodyReader = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+
+	resp, err := me.send(ctx, method, path, query, contentType, bodyReader)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return fmt.Errorf("error decoding response body: %w", err)
+		}
+	}
+	return nil
+}
+
+// send sends a request to the api and returns the response to be read and closed by the caller.
+// Responses with an error status are returned as *Error.
+func (me *Client) send(ctx context.Context, method string, path string, query url.Values, contentType string, body io.Reader) (*http.Response, error) {
+	rawUrl := me.baseUrl + apiPrefix + path
+	if len(query) != 0 {
+		rawUrl += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, rawUrl, body)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if me.token != "" {
+		req.Header.Set("Authorization", "Bearer "+me.token)
+	}
+
+	resp, err := me.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error sending request: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		apiErr := &Error{Status: resp.StatusCode, Title: http.StatusText(resp.StatusCode)}
+		if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/problem+json") {
+			if err := json.NewDecoder(resp.Body).Decode(apiErr); err != nil {
+				return nil, fmt.Errorf("error decoding error body: %w", err)
+			}
+		}
+		return nil, apiErr
+	}
+
+	return resp, nil
+}
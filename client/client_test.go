@@ -0,0 +1,153 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/assaidy/url_shortener/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuth(t *testing.T) {
+	ctx := context.Background()
+	c, username := newUser(t)
+
+	err := New(testServerUrl).Register(ctx, username, "password")
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusConflict, apiErr.Status)
+	assert.Equal(t, "conflict", apiErr.Code)
+
+	_, err = New(testServerUrl).Login(ctx, username, "wrong password")
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "unauthorized", apiErr.Code)
+
+	_, err = New(testServerUrl).ListShortUrls(ctx, ListShortUrlsParams{})
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.Status)
+
+	_, err = New(testServerUrl, WithToken("token")).ListShortUrls(ctx, ListShortUrlsParams{})
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusUnauthorized, apiErr.Status)
+
+	_, err = New(testServerUrl, WithToken(c.Token())).ListShortUrls(ctx, ListShortUrlsParams{})
+	assert.NoError(t, err)
+}
+
+func TestShortUrls(t *testing.T) {
+	ctx := context.Background()
+	c, username := newUser(t)
+	shortUrl := username + "docs"
+
+	created, err := c.CreateShortUrl(ctx, CreateShortUrlRequest{LongUrl: "https://example.com", ShortUrl: shortUrl})
+	require.NoError(t, err)
+	assert.Equal(t, CreatedShortUrl{ShortUrl: shortUrl}, created)
+
+	_, err = c.CreateShortUrl(ctx, CreateShortUrlRequest{LongUrl: "https://example.com", ShortUrl: shortUrl})
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "conflict", apiErr.Code)
+
+	_, err = c.CreateShortUrl(ctx, CreateShortUrlRequest{})
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "validation_failed", apiErr.Code)
+	assert.Contains(t, apiErr.Errors, utils.FieldError{Field: "longUrl", Constraint: "required"})
+
+	longUrl := "https://example.com/docs"
+	require.NoError(t, c.UpdateShortUrl(ctx, "", shortUrl, UpdateShortUrlRequest{LongUrl: &longUrl}))
+
+	err = c.UpdateShortUrl(ctx, "", username+"missing", UpdateShortUrlRequest{LongUrl: &longUrl})
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "not_found", apiErr.Code)
+
+	shortUrls, err := c.ListShortUrls(ctx, ListShortUrlsParams{})
+	require.NoError(t, err)
+	if assert.Len(t, shortUrls, 1) {
+		assert.Equal(t, shortUrl, shortUrls[0].ShortUrl)
+		assert.Equal(t, longUrl, shortUrls[0].LongUrl)
+	}
+
+	shortUrls, err = c.ListShortUrls(ctx, ListShortUrlsParams{After: shortUrl})
+	require.NoError(t, err)
+	assert.Empty(t, shortUrls)
+}
+
+func TestImportExport(t *testing.T) {
+	ctx := context.Background()
+	c, username := newUser(t)
+
+	data := "short_url,long_url\n" + username + "docs,https://example.com/docs\n" + username + "blog,https://example.com/blog\n"
+	_, err := c.ImportShortUrls(ctx, ImportShortUrlsParams{Format: "xml"}, strings.NewReader(data))
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "validation_failed", apiErr.Code)
+
+	job, err := c.ImportShortUrls(ctx, ImportShortUrlsParams{Format: "csv"}, strings.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, 2, job.Total)
+	for i := 0; job.FinishedAt == nil && i < 50; i++ {
+		time.Sleep(100 * time.Millisecond)
+		job, err = c.GetImportJob(ctx, job.ID)
+		require.NoError(t, err)
+	}
+	require.NotNil(t, job.FinishedAt, "import job didn't finish")
+	assert.Equal(t, "completed", job.Status)
+	assert.Equal(t, 2, job.Created)
+
+	// jobs of other users aren't found
+	other, _ := newUser(t)
+	_, err = other.GetImportJob(ctx, job.ID)
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "not_found", apiErr.Code)
+
+	var exported bytes.Buffer
+	require.NoError(t, c.ExportShortUrls(ctx, ExportShortUrlsParams{}, &exported))
+	assert.Contains(t, exported.String(), username+"docs,https://example.com/docs,")
+	assert.Contains(t, exported.String(), username+"blog,https://example.com/blog,")
+}
+
+func TestGetShortUrlStats(t *testing.T) {
+	ctx := context.Background()
+	c, username := newUser(t)
+	shortUrl := username + "docs"
+
+	_, err := c.GetShortUrlStats(ctx, shortUrl, StatsParams{})
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "not_found", apiErr.Code)
+
+	_, err = c.CreateShortUrl(ctx, CreateShortUrlRequest{LongUrl: "https://example.com", ShortUrl: shortUrl})
+	require.NoError(t, err)
+
+	stats, err := c.GetShortUrlStats(ctx, shortUrl, StatsParams{From: time.Now().AddDate(0, 0, -7)})
+	require.NoError(t, err)
+	assert.Equal(t, shortUrl, stats.ShortUrl)
+	assert.Zero(t, stats.Visits)
+}
+
+func TestImportAllOrNone(t *testing.T) {
+	ctx := context.Background()
+	c, username := newUser(t)
+	params := ImportShortUrlsParams{Format: "csv", OnConflict: "fail"}
+
+	data := "short_url,long_url\n" + username + "docs,https://example.com/docs\n,https://example.com/random\n"
+	job, err := c.ImportShortUrls(ctx, params, strings.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, "completed", job.Status)
+	assert.Equal(t, 2, job.Created)
+
+	data = "short_url,long_url\n" + username + "blog,https://example.com/blog\n,https://example.com/random\n" + username + "docs,https://example.com/docs\n"
+	job, err = c.ImportShortUrls(ctx, params, strings.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, "failed", job.Status)
+	assert.Zero(t, job.Created)
+
+	shortUrls, err := c.ListShortUrls(ctx, ListShortUrlsParams{})
+	require.NoError(t, err)
+	assert.Len(t, shortUrls, 2)
+}
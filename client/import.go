@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type ExportShortUrlsParams struct {
+	Domain     string
+	Format     string // csv or ndjson, defaults to csv
+	WithVisits bool
+}
+
+// ExportShortUrls writes all short urls of the user to w, as the server streams them.
+func (me *Client) ExportShortUrls(ctx context.Context, params ExportShortUrlsParams, w io.Writer) error {
+	query := url.Values{}
+	if params.Domain != "" {
+		query.Set("domain", params.Domain)
+	}
+	if params.Format != "" {
+		query.Set("format", params.Format)
+	}
+	if params.WithVisits {
+		query.Set("visits", "true")
+	}
+
+	resp, err := me.send(ctx, http.MethodGet, "/urls/export", query, "", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("error reading export: %w", err)
+	}
+	return nil
+}
+
+type ImportJob struct {
+	ID         int64      `json:"id"`
+	Domain     string     `json:"domain"`
+	Status     string     `json:"status"` // pending, running, completed or failed
+	OnConflict string     `json:"onConflict"`
+	Total      int        `json:"total"`
+	Created    int        `json:"created"`
+	Skipped    int        `json:"skipped"`
+	Failed     int        `json:"failed"`
+	Error      string     `json:"error"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	FinishedAt *time.Time `json:"finishedAt"` // nil until the job is done
+}
+
+type ImportShortUrlsParams struct {
+	Domain     string
+	Format     string // csv or ndjson
+	OnConflict string // skip, fail or rename, defaults to skip
+}
+
+// ImportShortUrls uploads a csv or ndjson file of short urls. Large files are imported in the
+// background, poll GetImportJob until FinishedAt is set.
+func (me *Client) ImportShortUrls(ctx context.Context, params ImportShortUrlsParams, data io.Reader) (ImportJob, error) {
+	query := url.Values{"format": {params.Format}}
+	if params.Domain != "" {
+		query.Set("domain", params.Domain)
+	}
+	if params.OnConflict != "" {
+		query.Set("onConflict", params.OnConflict)
+	}
+
+	contentType := "text/csv"
+	if params.Format == "ndjson" {
+		contentType = "application/x-ndjson"
+	}
+
+	resp, err := me.send(ctx, http.MethodPost, "/urls/import", query, contentType, data)
+	if err != nil {
+		return ImportJob{}, err
+	}
+	defer resp.Body.Close()
+
+	var job ImportJob
+	if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+		return ImportJob{}, fmt.Errorf("error decoding response body: %w", err)
+	}
+	return job, nil
+}
+
+func (me *Client) GetImportJob(ctx context.Context, id int64) (ImportJob, error) {
+	var job ImportJob
+	if err := me.do(ctx, http.MethodGet, "/urls/import/"+strconv.FormatInt(id, 10), nil, nil, &job); err != nil {
+		return ImportJob{}, err
+	}
+	return job, nil
+}
@@ -0,0 +1,16 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// DisableShortUrl stops the redirects of a short url on domain, empty for the main domain. It needs
+// a moderator or admin, reason is one of phishing, malware, spam, illegal or other.
+func (me *Client) DisableShortUrl(ctx context.Context, domain string, shortUrl string, reason string) error {
+	req := struct {
+		Reason string `json:"reason"`
+	}{reason}
+	return me.do(ctx, http.MethodPost, "/admin/urls/"+url.PathEscape(shortUrl)+"/disable", domainQuery(domain), req, nil)
+}
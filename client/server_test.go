@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/assaidy/url_shortener/cache"
+	"github.com/assaidy/url_shortener/config"
+	"github.com/assaidy/url_shortener/db/postgres"
+	"github.com/assaidy/url_shortener/fakes"
+	"github.com/assaidy/url_shortener/handlers"
+	"github.com/assaidy/url_shortener/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// testConfigEnv names the config file of a db and cache the tests may write to. The tests run on
+// fakes.Postgres and fakes.Valkey when it isn't set.
+const testConfigEnv = "URLSHORTENER_TEST_CONFIG"
+
+// testServerUrl is the base url of the server TestMain starts for the tests.
+var testServerUrl string
+
+// TestMain serves the routes of handlers.RegisterRoutes once for all tests, the services are
+// singletons that can't be restarted while their workers are stopping.
+func TestMain(m *testing.M) {
+	stop, err := startServer()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error starting server:", err)
+		os.Exit(1)
+	}
+	code := m.Run()
+	stop()
+	os.Exit(code)
+}
+
+func startServer() (func(), error) {
+	var valkey *fakes.Valkey
+	if configPath := os.Getenv(testConfigEnv); configPath != "" {
+		if err := config.Load(configPath); err != nil {
+			return nil, err
+		}
+		if err := postgres_db.Connect(); err != nil {
+			return nil, err
+		}
+		if err := postgres_db.Migrate(context.Background()); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if valkey, err = fakes.NewValkey(); err != nil {
+			return nil, err
+		}
+
+		config.Cfg = config.Default()
+		config.Cfg.Auth.SecretKey = "secret"
+		config.Cfg.Destination.AllowPrivate = true
+		config.Cfg.RateLimit.Enabled = false
+		config.Cfg.LinkCheck.Enabled = false
+		config.Cfg.MetadataFetch.Enabled = false
+		config.Cfg.Valkey.Addr = valkey.Addr
+		postgres_db.DB = fakes.NewPostgres()
+	}
+	if err := cache.Connect(); err != nil {
+		return nil, err
+	}
+
+	started := []services.Service{
+		services.RateLimitServiceInstance,
+		services.GeoIpServiceInstance,
+		services.DestinationServiceInstance,
+		services.DomainServiceInstance,
+		services.IdempotencyServiceInstance,
+		services.UserServiceInstance,
+		services.UrlServiceInstance,
+		services.ImportServiceInstance,
+		services.CampaignServiceInstance,
+		services.AnalyticsServiceInstance,
+		services.ModerationServiceInstance,
+		services.LinkCheckServiceInstance,
+		services.MetadataServiceInstance,
+		services.QrCodeServiceInstance,
+	}
+	for _, it := range started {
+		if err := it.Start(); err != nil {
+			return nil, err
+		}
+	}
+
+	app := fiber.New(fiber.Config{ErrorHandler: handlers.ErrorHandler, DisableStartupMessage: true})
+	app.Server().HeaderReceived = handlers.ImportRequestConfig
+	handlers.RegisterRoutes(app)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	go app.Listener(listener)
+	testServerUrl = "http://" + listener.Addr().String()
+
+	return func() {
+		app.Shutdown()
+		for _, it := range slices.Backward(started) {
+			it.Stop()
+		}
+		cache.Valkey.Close()
+		if valkey != nil {
+			valkey.Close()
+		}
+	}, nil
+}
+
+// newUser registers a user with a name no other test uses and returns a client logged in as it.
+func newUser(t *testing.T) (*Client, string) {
+	ctx := context.Background()
+	c := New(testServerUrl)
+
+	username := "client" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	require.NoError(t, c.Register(ctx, username, "password"))
+	_, err := c.Login(ctx, username, "password")
+	require.NoError(t, err)
+	t.Cleanup(func() { c.DeleteUser(ctx) })
+
+	return c, username
+}
@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/assaidy/url_shortener/utils"
+)
+
+type CreateShortUrlRequest struct {
+	Domain          string                `json:"domain,omitempty"` // a verified custom domain, the main domain when empty
+	LongUrl         string                `json:"longUrl"`
+	ShortUrl        string                `json:"shortUrl,omitempty"` // generated when empty
+	RedirectStatus  int                   `json:"redirectStatus,omitempty"`
+	CachePolicy     string                `json:"cachePolicy,omitempty"`
+	ForwardPath     bool                  `json:"forwardPath,omitempty"`
+	QueryForwarding string                `json:"queryForwarding,omitempty"`
+	Campaign        string                `json:"campaign,omitempty"`
+	UtmTemplate     string                `json:"utmTemplate,omitempty"`
+	Rules           []utils.RoutingRule   `json:"rules,omitempty"`
+	Variants        []utils.Variant       `json:"variants,omitempty"`
+	Schedule        []utils.ScheduleEntry `json:"schedule,omitempty"`
+	Interstitial    bool                  `json:"interstitial,omitempty"`
+}
+
+type CreatedShortUrl struct {
+	Domain   string `json:"domain"`
+	ShortUrl string `json:"shortUrl"`
+}
+
+func (me *Client) CreateShortUrl(ctx context.Context, req CreateShortUrlRequest) (CreatedShortUrl, error) {
+	var created CreatedShortUrl
+	if err := me.do(ctx, http.MethodPost, "/urls", nil, req, &created); err != nil {
+		return CreatedShortUrl{}, err
+	}
+	return created, nil
+}
+
+// CreateShortUrlsResult is the result of an item of a batch, Error is nil when it was created.
+type CreateShortUrlsResult struct {
+	Index    int
+	ShortUrl string
+	Error    *Error
+}
+
+// CreateShortUrls creates the items of a batch on domain. It only fails as a whole when the batch is
+// invalid, errors of the items are in their results.
+func (me *Client) CreateShortUrls(ctx context.Context, domain string, items []CreateShortUrlRequest) ([]CreateShortUrlsResult, error) {
+	req := struct {
+		Domain string                  `json:"domain,omitempty"`
+		Items  []CreateShortUrlRequest `json:"items"`
+	}{domain, items}
+
+	var resp struct {
+		Results []struct {
+			Index    int                `json:"index"`
+			Status   int                `json:"status"`
+			ShortUrl string             `json:"shortUrl"`
+			Code     string             `json:"code"`
+			Error    string             `json:"error"`
+			Errors   []utils.FieldError `json:"errors"`
+		} `json:"results"`
+	}
+	if err := me.do(ctx, http.MethodPost, "/urls/batch", nil, req, &resp); err != nil {
+		return nil, err
+	}
+
+	results := make([]CreateShortUrlsResult, len(resp.Results))
+	for i, it := range resp.Results {
+		results[i] = CreateShortUrlsResult{Index: it.Index, ShortUrl: it.ShortUrl}
+		if it.Status >= 400 {
+			results[i].Error = &Error{
+				Status: it.Status,
+				Code:   it.Code,
+				Title:  http.StatusText(it.Status),
+				Detail: it.Error,
+				Errors: it.Errors,
+			}
+		}
+	}
+	return results, nil
+}
+
+type LinkCheck struct {
+	StatusCode int       `json:"statusCode"`
+	FinalUrl   string    `json:"finalUrl"`
+	Error      string    `json:"error"`
+	Broken     bool      `json:"broken"`
+	CheckedAt  time.Time `json:"checkedAt"`
+}
+
+type ShortUrl struct {
+	ShortUrl  string             `json:"shortUrl"`
+	LongUrl   string             `json:"longUrl"`
+	CreatedAt time.Time          `json:"createdAt"`
+	Disabled  bool               `json:"disabled"`
+	Metadata  utils.PageMetadata `json:"metadata"`
+	LinkCheck *LinkCheck         `json:"linkCheck"` // nil until the destination is checked
+}
+
+type ListShortUrlsParams struct {
+	Domain     string
+	After      string // the last short url of the previous page
+	BrokenOnly bool
+}
+
+// ListShortUrls returns a page of the short urls of the user.
+func (me *Client) ListShortUrls(ctx context.Context, params ListShortUrlsParams) ([]ShortUrl, error) {
+	query := url.Values{}
+	if params.Domain != "" {
+		query.Set("domain", params.Domain)
+	}
+	if params.After != "" {
+		query.Set("after", params.After)
+	}
+	if params.BrokenOnly {
+		query.Set("broken", "true")
+	}
+
+	var shortUrls []ShortUrl
+	if err := me.do(ctx, http.MethodGet, "/urls", query, nil, &shortUrls); err != nil {
+		return nil, err
+	}
+	return shortUrls, nil
+}
+
+// UpdateShortUrlRequest only changes the fields that aren't nil.
+type UpdateShortUrlRequest struct {
+	LongUrl         *string `json:"longUrl,omitempty"`
+	RedirectStatus  *int    `json:"redirectStatus,omitempty"`
+	CachePolicy     *string `json:"cachePolicy,omitempty"`
+	ForwardPath     *bool   `json:"forwardPath,omitempty"`
+	QueryForwarding *string `json:"queryForwarding,omitempty"`
+	Interstitial    *bool   `json:"interstitial,omitempty"`
+}
+
+// UpdateShortUrl updates a short url on domain, empty for the main domain.
+func (me *Client) UpdateShortUrl(ctx context.Context, domain string, shortUrl string, req UpdateShortUrlRequest) error {
+	return me.do(ctx, http.MethodPatch, "/urls/"+url.PathEscape(shortUrl), domainQuery(domain), req, nil)
+}
+
+func domainQuery(domain string) url.Values {
+	if domain == "" {
+		return nil
+	}
+	return url.Values{"domain": {domain}}
+}
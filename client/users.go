@@ -0,0 +1,32 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+type credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+func (me *Client) Register(ctx context.Context, username string, password string) error {
+	return me.do(ctx, http.MethodPost, "/users/register", nil, credentials{username, password}, nil)
+}
+
+// Login authenticates the next requests of the client as username, and returns the JWT.
+func (me *Client) Login(ctx context.Context, username string, password string) (string, error) {
+	var result struct {
+		JwtToken string `json:"jwtToken"`
+	}
+	if err := me.do(ctx, http.MethodPost, "/users/login", nil, credentials{username, password}, &result); err != nil {
+		return "", err
+	}
+	me.token = result.JwtToken
+	return result.JwtToken, nil
+}
+
+// DeleteUser deletes the authenticated user with all their short urls.
+func (me *Client) DeleteUser(ctx context.Context) error {
+	return me.do(ctx, http.MethodDelete, "/users", nil, nil, nil)
+}
@@ -1,60 +1,79 @@
 package main
 
 import (
-	"errors"
+	"context"
+	"flag"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/assaidy/url_shortener/cache"
 	"github.com/assaidy/url_shortener/config"
+	"github.com/assaidy/url_shortener/db/postgres"
 	"github.com/assaidy/url_shortener/handlers"
+	"github.com/assaidy/url_shortener/rpc"
 	"github.com/assaidy/url_shortener/services"
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/limiter"
 	"github.com/gofiber/fiber/v2/middleware/logger"
-	"github.com/gofiber/storage/valkey"
 )
 
-func errorHandler(c *fiber.Ctx, err error) error {
-	code := fiber.StatusInternalServerError
-	var fiberErr *fiber.Error
-	if errors.As(err, &fiberErr) {
-		code = fiberErr.Code
+func main() {
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "yaml config file, env vars override its settings")
+	printConfig := flag.Bool("print-config", false, "print the config with secrets redacted, and exit")
+	flag.Parse()
+
+	if err := config.Load(*configPath); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
-	c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
-	// NOTE: Logging occurs before this error handler is executed, so the internal error
-	// has already been logged. We avoid exposing internal error details to the client.
-	if code == fiber.StatusInternalServerError {
-		return c.SendStatus(code)
+	if *printConfig {
+		data, err := config.Cfg.Yaml()
+		if err != nil {
+			slog.Error("error printing config", "err", err)
+			os.Exit(1)
+		}
+		os.Stdout.Write(data)
+		return
 	}
-	return c.Status(code).SendString(err.Error())
-}
 
-func registerRoutes(router *fiber.App) {
-	withRedirectionRateLimit := limiter.New(limiter.Config{
-		Max:               config.RedirectionRateLimitMaxPerWindow,
-		Expiration:        config.RedirectionRateLimitWindow,
-		LimiterMiddleware: limiter.SlidingWindow{},
-		Storage:           valkey.New(valkey.Config{InitAddress: []string{config.ValkeyAddr}}),
-	})
-
-	router.Use(logger.New())
-
-	router.Post("/users/register", handlers.HandleRegister)
-	router.Post("/users/login", handlers.HandleLogin)
-	router.Delete("/users", handlers.WithJwt, handlers.HandleDeleteUser)
-
-	router.Post("/urls", handlers.WithJwt, handlers.HandleCreateShortUrl)
-	router.Get("/urls/:short_url", withRedirectionRateLimit, handlers.HandleRedirectShortUrl)
-	// NOTE: for now, i prefer to keep analytics only accecible form db
-}
+	if err := postgres_db.Connect(); err != nil {
+		slog.Error("error connecting to db", "err", err, "PID", os.Getpid())
+		os.Exit(1)
+	}
+	if config.Cfg.Postgres.AutoMigrate {
+		if err := postgres_db.Migrate(context.Background()); err != nil {
+			slog.Error("error migrating db", "err", err, "PID", os.Getpid())
+			os.Exit(1)
+		}
+	}
+	if err := postgres_db.CheckSchemaVersion(context.Background()); err != nil {
+		slog.Error("error checking db schema", "err", err, "PID", os.Getpid())
+		os.Exit(1)
+	}
+	if err := cache.Connect(); err != nil {
+		slog.Error("error connecting to cache", "err", err, "PID", os.Getpid())
+		os.Exit(1)
+	}
 
-func main() {
 	services := []services.Service{
+		services.RateLimitServiceInstance,
+		services.GeoIpServiceInstance,
+		services.DestinationServiceInstance,
+		services.DomainServiceInstance,
+		services.IdempotencyServiceInstance,
 		services.UserServiceInstance,
 		services.UrlServiceInstance,
+		services.ImportServiceInstance,
+		services.CampaignServiceInstance,
+		services.AnalyticsServiceInstance,
+		services.ModerationServiceInstance,
+		services.LinkCheckServiceInstance,
+		services.MetadataServiceInstance,
+		services.QrCodeServiceInstance,
+		rpc.ServerInstance,
 	}
 
 	slog.Info("starting all services...", "PID", os.Getpid())
@@ -68,14 +87,22 @@ func main() {
 	app := fiber.New(fiber.Config{
 		AppName:      "URL Shortener",
 		ServerHeader: "URL Shortener",
-		ErrorHandler: errorHandler,
+		ErrorHandler: handlers.ErrorHandler,
 		Prefork:      true,
+
+		ProxyHeader:             config.Cfg.Server.ProxyHeader,
+		EnableTrustedProxyCheck: config.Cfg.Server.ProxyHeader != "",
+		TrustedProxies:          config.Cfg.Server.TrustedProxies,
+		EnableIPValidation:      true, // c.IP() returns the first valid IP of the header
 	})
 
-	registerRoutes(app)
+	app.Server().HeaderReceived = handlers.ImportRequestConfig
+
+	app.Use(logger.New())
+	handlers.RegisterRoutes(app)
 
 	go func() {
-		if err := app.Listen(config.ServerAddr); err != nil {
+		if err := app.Listen(config.Cfg.Server.Addr); err != nil {
 			slog.Error("error starting server", "err", err)
 			os.Exit(1)
 		}
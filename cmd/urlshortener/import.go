@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/assaidy/url_shortener/client"
+	"github.com/assaidy/url_shortener/services"
+	"github.com/assaidy/url_shortener/utils"
+)
+
+// importPollInterval is how often the job of a background import is checked.
+const importPollInterval = time.Second
+
+// runExport writes the short urls of a user to a file, in the format of the export api.
+func runExport(ctx context.Context, args []string) error {
+	flagSet := flag.NewFlagSet("export", flag.ContinueOnError)
+	username := flagSet.String("user", "", "owner of the short urls (direct only)")
+	domain := flagSet.String("domain", "", "domain of the short urls, the main domain when empty")
+	format := flagSet.String("format", services.ImportFormatCsv, "csv or ndjson")
+	withVisits := flagSet.Bool("visits", false, "include the visit counts")
+	output := flagSet.String("o", "", "file to write to, stdout when empty")
+	if _, err := parseFlags(flagSet, args, 0); err != nil {
+		return err
+	}
+
+	if *format != services.ImportFormatCsv && *format != services.ImportFormatNdjson {
+		return fmt.Errorf("format must be csv or ndjson")
+	}
+	if !remote() && *username == "" {
+		return fmt.Errorf("-user is required with direct db access")
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("error creating output file: %w", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if remote() {
+		return newClient().ExportShortUrls(ctx, client.ExportShortUrlsParams{
+			Domain:     *domain,
+			Format:     *format,
+			WithVisits: *withVisits,
+		}, w)
+	}
+
+	stop, err := startServices(services.UrlServiceInstance)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	return services.UrlServiceInstance.WriteExportedShortUrls(ctx, w, *username, utils.NormalizeDomain(*domain), *format, *withVisits)
+}
+
+// runImport imports a csv or ndjson file of short urls and waits for the import to finish.
+func runImport(ctx context.Context, args []string) error {
+	flagSet := flag.NewFlagSet("import", flag.ContinueOnError)
+	username := flagSet.String("user", "", "owner of the imported short urls (direct only)")
+	domain := flagSet.String("domain", "", "a verified custom domain of the owner, the main domain when empty")
+	format := flagSet.String("format", "", "csv or ndjson, taken from the file extension when empty")
+	onConflict := flagSet.String("on-conflict", services.ImportOnConflictSkip, "skip, fail or rename taken short urls")
+	positional, err := parseFlags(flagSet, args, 1)
+	if err != nil {
+		return err
+	}
+	path := positional[0]
+
+	if *format == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".csv":
+			*format = services.ImportFormatCsv
+		case ".ndjson", ".jsonl":
+			*format = services.ImportFormatNdjson
+		default:
+			return fmt.Errorf("unknown format of %s, set -format", path)
+		}
+	}
+	if !remote() && *username == "" {
+		return fmt.Errorf("-user is required with direct db access")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading import file: %w", err)
+	}
+
+	if remote() {
+		c := newClient()
+		job, err := c.ImportShortUrls(ctx, client.ImportShortUrlsParams{Domain: *domain, Format: *format, OnConflict: *onConflict}, bytes.NewReader(data))
+		for err == nil && job.FinishedAt == nil {
+			if err = sleep(ctx, importPollInterval); err == nil {
+				job, err = c.GetImportJob(ctx, job.ID)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		return printJson(job)
+	}
+
+	stop, err := startServices(services.DestinationServiceInstance, services.DomainServiceInstance, services.UrlServiceInstance, services.ImportServiceInstance)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	job, err := services.ImportServiceInstance.CreateImportJob(ctx, services.CreateImportJobParams{
+		Username:   *username,
+		Domain:     utils.NormalizeDomain(*domain),
+		Format:     *format,
+		OnConflict: *onConflict,
+		Data:       data,
+	})
+	for err == nil && job.FinishedAt == nil {
+		if err = sleep(ctx, importPollInterval); err == nil {
+			job, err = services.ImportServiceInstance.GetImportJob(ctx, *username, job.ID)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	return printJson(job)
+}
+
+func sleep(ctx context.Context, duration time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(duration):
+		return nil
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/assaidy/url_shortener/client"
+	"github.com/assaidy/url_shortener/services"
+	"github.com/assaidy/url_shortener/utils"
+)
+
+func runLink(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("expected link create, get or disable")
+	}
+
+	switch args[0] {
+	case "create":
+		return runLinkCreate(ctx, args[1:])
+	case "get":
+		return runLinkGet(ctx, args[1:])
+	case "disable":
+		return runLinkDisable(ctx, args[1:])
+	}
+	return fmt.Errorf("unknown link command %q", args[0])
+}
+
+func runLinkCreate(ctx context.Context, args []string) error {
+	flagSet := flag.NewFlagSet("link create", flag.ContinueOnError)
+	username := flagSet.String("user", "", "owner of the short url (direct only)")
+	domain := flagSet.String("domain", "", "a verified custom domain of the owner, the main domain when empty")
+	shortUrl := flagSet.String("short", "", "the short url, generated when empty")
+	campaign := flagSet.String("campaign", "", "campaign of the short url")
+	positional, err := parseFlags(flagSet, args, 1)
+	if err != nil {
+		return err
+	}
+	longUrl := positional[0]
+
+	if remote() {
+		created, err := newClient().CreateShortUrl(ctx, client.CreateShortUrlRequest{
+			Domain:   *domain,
+			LongUrl:  longUrl,
+			ShortUrl: *shortUrl,
+			Campaign: *campaign,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Println(created.ShortUrl)
+		return nil
+	}
+
+	if *username == "" {
+		return fmt.Errorf("-user is required with direct db access")
+	}
+
+	stop, err := startServices(services.DestinationServiceInstance, services.DomainServiceInstance, services.UrlServiceInstance)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	created, err := services.UrlServiceInstance.CreateShortUrl(ctx, services.CreateShortUrlParams{
+		Username: *username,
+		Domain:   utils.NormalizeDomain(*domain),
+		LongUrl:  longUrl,
+		ShortUrl: *shortUrl,
+		Campaign: *campaign,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Println(created)
+
+	return nil
+}
+
+type linkPreview struct {
+	ShortUrl             string    `json:"shortUrl"`
+	LongUrl              string    `json:"longUrl"`
+	Owner                string    `json:"owner"`
+	CreatedAt            time.Time `json:"createdAt"`
+	Visits               int64     `json:"visits"`
+	Disabled             bool      `json:"disabled"`
+	DisabledReason       string    `json:"disabledReason,omitempty"`
+	HasOtherDestinations bool      `json:"hasOtherDestinations"`
+	State                string    `json:"state,omitempty"`
+}
+
+// runLinkGet prints any short url with its owner, there is no api for that.
+func runLinkGet(ctx context.Context, args []string) error {
+	flagSet := flag.NewFlagSet("link get", flag.ContinueOnError)
+	domain := flagSet.String("domain", "", "domain of the short url, the main domain when empty")
+	positional, err := parseFlags(flagSet, args, 1)
+	if err != nil {
+		return err
+	}
+
+	if remote() {
+		return errDirectOnly
+	}
+
+	stop, err := startServices(services.UrlServiceInstance)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	preview, err := services.UrlServiceInstance.GetShortUrlPreview(ctx, utils.NormalizeDomain(*domain), positional[0])
+	if err != nil {
+		return err
+	}
+
+	return printJson(linkPreview(preview))
+}
+
+func runLinkDisable(ctx context.Context, args []string) error {
+	flagSet := flag.NewFlagSet("link disable", flag.ContinueOnError)
+	domain := flagSet.String("domain", "", "domain of the short url, the main domain when empty")
+	reason := flagSet.String("reason", "", "phishing, malware, spam, illegal or other")
+	moderator := flagSet.String("moderator", "cli", "who the open reports of the short url are resolved by (direct only)")
+	positional, err := parseFlags(flagSet, args, 1)
+	if err != nil {
+		return err
+	}
+	shortUrl := positional[0]
+
+	if remote() {
+		return newClient().DisableShortUrl(ctx, *domain, shortUrl, *reason)
+	}
+
+	stop, err := startServices(services.UrlServiceInstance, services.ModerationServiceInstance)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	return services.ModerationServiceInstance.DisableShortUrl(ctx, services.DisableShortUrlParams{
+		Moderator: *moderator,
+		Domain:    utils.NormalizeDomain(*domain),
+		ShortUrl:  shortUrl,
+		Reason:    *reason,
+	})
+}
@@ -0,0 +1,175 @@
+// Command urlshortener manages a url shortener from the shell. Commands work on the db directly,
+// for operators, or on a running server through the http api when -server is set.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/assaidy/url_shortener/cache"
+	"github.com/assaidy/url_shortener/client"
+	"github.com/assaidy/url_shortener/config"
+	"github.com/assaidy/url_shortener/db/postgres"
+	"github.com/assaidy/url_shortener/services"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(ctx context.Context, args []string) error
+}
+
+var commands = []command{
+	{"migrate", "migrate up|down|status", runMigrate},
+	{"user", "user create|delete|list ...", runUser},
+	{"link", "link create|get|disable ...", runLink},
+	{"stats", "stats [-domain domain] [-from time] [-to time] <short url>", runStats},
+	{"export", "export [-user username] [-domain domain] [-format csv|ndjson] [-visits] [-o file]", runExport},
+	{"import", "import [-user username] [-domain domain] [-format csv|ndjson] [-on-conflict skip|fail|rename] <file>", runImport},
+}
+
+var (
+	serverUrl  string
+	token      string
+	configPath string
+)
+
+// errDirectOnly is returned by commands that have no http api.
+var errDirectOnly = errors.New("this command needs direct db access, run it without -server")
+
+func main() {
+	flag.StringVar(&serverUrl, "server", os.Getenv("URLSHORTENER_SERVER"), "base url of a running server, e.g. https://sho.rt, the db is used directly when empty")
+	flag.StringVar(&token, "token", os.Getenv("URLSHORTENER_TOKEN"), "JWT of the user remote commands act as")
+	flag.StringVar(&configPath, "config", os.Getenv("CONFIG_FILE"), "yaml config file of the server, for direct commands")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	for _, it := range commands {
+		if it.name != flag.Arg(0) {
+			continue
+		}
+		if err := it.run(ctx, flag.Args()[1:]); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				os.Exit(2)
+			}
+			fmt.Fprintln(os.Stderr, "error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "unknown command %q\n", flag.Arg(0))
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: urlshortener [-server url] [-token jwt] [-config file] <command> [args]")
+	fmt.Fprintln(os.Stderr, "\ncommands:")
+	for _, it := range commands {
+		fmt.Fprintln(os.Stderr, "  "+it.usage)
+	}
+	fmt.Fprintln(os.Stderr, "\nflags:")
+	flag.PrintDefaults()
+}
+
+func remote() bool {
+	return serverUrl != ""
+}
+
+func newClient() *client.Client {
+	return client.New(serverUrl, client.WithToken(token))
+}
+
+// connectDb loads the config of the server and connects to its db.
+func connectDb() error {
+	if err := config.Load(configPath); err != nil {
+		return err
+	}
+	return postgres_db.Connect()
+}
+
+// startServices connects to the db and cache and starts the given services, only those a command
+// uses, so commands don't run the background workers of the server, e.g. only import runs the
+// import worker. The returned function stops them, it flushes the pending work of the services
+// like the server does.
+func startServices(needed ...services.Service) (func(), error) {
+	if err := connectDb(); err != nil {
+		return nil, err
+	}
+	if err := cache.Connect(); err != nil {
+		return nil, err
+	}
+
+	started := []services.Service{}
+	stop := func() {
+		for _, it := range started {
+			it.Stop()
+		}
+	}
+	for _, it := range needed {
+		if err := it.Start(); err != nil {
+			stop()
+			return nil, fmt.Errorf("error starting service: %w", err)
+		}
+		started = append(started, it)
+	}
+
+	return stop, nil
+}
+
+func printJson(value any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// parseTime accepts the same RFC 3339 times and dates as the range of the stats api.
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		if t, err = time.Parse(time.DateOnly, value); err != nil {
+			return time.Time{}, fmt.Errorf("invalid time %q, expected a date or an RFC 3339 time", value)
+		}
+	}
+	return t, nil
+}
+
+// parseFlags parses args with flagSet, allowing flags after the positional args too, and checks
+// that exactly numArgs positional args are left.
+func parseFlags(flagSet *flag.FlagSet, args []string, numArgs int) ([]string, error) {
+	positional := []string{}
+	for {
+		if err := flagSet.Parse(args); err != nil {
+			return nil, err
+		}
+		if flagSet.NArg() == 0 {
+			break
+		}
+		positional = append(positional, flagSet.Arg(0))
+		args = flagSet.Args()[1:]
+	}
+	if len(positional) != numArgs {
+		flagSet.Usage()
+		return nil, fmt.Errorf("%s expects %d args, got %d", flagSet.Name(), numArgs, len(positional))
+	}
+	return positional, nil
+}
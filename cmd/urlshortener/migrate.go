@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/assaidy/url_shortener/db/postgres"
+)
+
+// runMigrate applies or rolls back the migrations embedded in the binary, like the server does on
+// start with postgres.autoMigrate.
+func runMigrate(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("expected migrate up, down or status")
+	}
+
+	flagSet := flag.NewFlagSet("migrate "+args[0], flag.ContinueOnError)
+	if _, err := parseFlags(flagSet, args[1:], 0); err != nil {
+		return err
+	}
+
+	if remote() {
+		return errDirectOnly
+	}
+
+	var migrate func(ctx context.Context) error
+	switch args[0] {
+	case "up":
+		migrate = postgres_db.Migrate
+	case "down": // only the last migration
+		migrate = postgres_db.MigrateDown
+	case "status":
+		migrate = printMigrationsStatus
+	default:
+		return fmt.Errorf("unknown migrate command %q", args[0])
+	}
+
+	if err := connectDb(); err != nil {
+		return err
+	}
+	defer postgres_db.DB.Close()
+
+	return migrate(ctx)
+}
+
+func printMigrationsStatus(ctx context.Context) error {
+	status, err := postgres_db.GetMigrationsStatus(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tSTATE\tAPPLIED AT\tSOURCE")
+	for _, it := range status {
+		appliedAt := ""
+		if !it.AppliedAt.IsZero() {
+			appliedAt = it.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", it.Source.Version, it.State, appliedAt, it.Source.Path)
+	}
+
+	return w.Flush()
+}
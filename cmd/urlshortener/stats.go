@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/assaidy/url_shortener/client"
+	"github.com/assaidy/url_shortener/services"
+	"github.com/assaidy/url_shortener/utils"
+)
+
+// runStats prints the visits of a short url. With direct db access it works for any short url, on
+// behalf of its owner.
+func runStats(ctx context.Context, args []string) error {
+	flagSet := flag.NewFlagSet("stats", flag.ContinueOnError)
+	domain := flagSet.String("domain", "", "domain of the short url, the main domain when empty")
+	from := flagSet.String("from", "", "start of the range, a date or an RFC 3339 time")
+	to := flagSet.String("to", "", "end of the range, a date or an RFC 3339 time")
+	positional, err := parseFlags(flagSet, args, 1)
+	if err != nil {
+		return err
+	}
+	shortUrl := positional[0]
+
+	var statsRange services.StatsRange
+	if statsRange.From, err = parseTime(*from); err != nil {
+		return err
+	}
+	if statsRange.To, err = parseTime(*to); err != nil {
+		return err
+	}
+
+	if remote() {
+		stats, err := newClient().GetShortUrlStats(ctx, shortUrl, client.StatsParams{
+			Domain: *domain,
+			From:   statsRange.From,
+			To:     statsRange.To,
+		})
+		if err != nil {
+			return err
+		}
+		return printJson(stats)
+	}
+
+	stop, err := startServices(services.UrlServiceInstance, services.AnalyticsServiceInstance)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	normalizedDomain := utils.NormalizeDomain(*domain)
+	preview, err := services.UrlServiceInstance.GetShortUrlPreview(ctx, normalizedDomain, shortUrl)
+	if err != nil {
+		return err
+	}
+	stats, err := services.AnalyticsServiceInstance.GetShortUrlStats(ctx, preview.Owner, normalizedDomain, shortUrl, statsRange)
+	if err != nil {
+		return err
+	}
+
+	return printJson(stats)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/assaidy/url_shortener/services"
+)
+
+func runUser(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("expected user create, delete or list")
+	}
+
+	switch args[0] {
+	case "create":
+		return runUserCreate(ctx, args[1:])
+	case "delete":
+		return runUserDelete(ctx, args[1:])
+	case "list":
+		return runUserList(ctx, args[1:])
+	}
+	return fmt.Errorf("unknown user command %q", args[0])
+}
+
+// runUserCreate creates a user, or registers one on the server. The password is read from stdin
+// when -password isn't set, so it doesn't end up in the shell history.
+func runUserCreate(ctx context.Context, args []string) error {
+	flagSet := flag.NewFlagSet("user create", flag.ContinueOnError)
+	password := flagSet.String("password", "", "password of the user, read from stdin when empty")
+	role := flagSet.String("role", services.RoleUser, "role of the user: user, moderator or admin (direct only)")
+	positional, err := parseFlags(flagSet, args, 1)
+	if err != nil {
+		return err
+	}
+	username := positional[0]
+
+	if *password == "" {
+		fmt.Fprint(os.Stderr, "password: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return fmt.Errorf("error reading password: %w", err)
+		}
+		*password = strings.TrimRight(line, "\r\n")
+	}
+
+	if remote() {
+		if *role != services.RoleUser {
+			return fmt.Errorf("roles can only be set with direct db access")
+		}
+		return newClient().Register(ctx, username, *password)
+	}
+
+	stop, err := startServices(services.UserServiceInstance)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	return services.UserServiceInstance.CreateUser(ctx, services.CreateUserParams{
+		Username: username,
+		Password: *password,
+		Role:     *role,
+	})
+}
+
+// runUserDelete deletes a user with all their short urls. Over the api, users can only delete
+// themselves, so it's direct only.
+func runUserDelete(ctx context.Context, args []string) error {
+	flagSet := flag.NewFlagSet("user delete", flag.ContinueOnError)
+	positional, err := parseFlags(flagSet, args, 1)
+	if err != nil {
+		return err
+	}
+
+	if remote() {
+		return errDirectOnly
+	}
+
+	stop, err := startServices(services.UserServiceInstance)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	return services.UserServiceInstance.DeleteUser(ctx, positional[0])
+}
+
+func runUserList(ctx context.Context, args []string) error {
+	flagSet := flag.NewFlagSet("user list", flag.ContinueOnError)
+	if _, err := parseFlags(flagSet, args, 0); err != nil {
+		return err
+	}
+
+	if remote() {
+		return errDirectOnly
+	}
+
+	stop, err := startServices(services.UserServiceInstance)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "USERNAME\tROLE\tCREATED AT")
+	afterUsername := ""
+	for {
+		users, err := services.UserServiceInstance.GetUsers(ctx, afterUsername)
+		if err != nil {
+			return err
+		}
+		for _, it := range users {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", it.Username, it.Role, it.CreatedAt.Format(time.RFC3339))
+		}
+		if len(users) == 0 {
+			break
+		}
+		afterUsername = users[len(users)-1].Username
+	}
+
+	return w.Flush()
+}
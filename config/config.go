@@ -1,56 +1,284 @@
 package config
 
 import (
-	"log/slog"
-	"os"
-	"strconv"
 	"time"
-
-	_ "github.com/joho/godotenv/autoload"
 )
 
-var (
-	ServerAddr = getEnvString("SERVER_ADDR", "localhost:8080")
-	SecretKey  = getEnvString("SECRET_KEY")
+// Cfg is the configuration of the process, set by Load.
+var Cfg *Config
 
-	PgHost     = getEnvString("PG_HOST", "localhost")
-	PgPort     = getEnvInt("PG_PORT", 5432)
-	PgUser     = getEnvString("PG_USER", "postgres")
-	PgPassword = getEnvString("PG_PASSWORD")
-	PgName     = getEnvString("PgName", "url_shortener")
-	PgSSL      = getEnvString("PG_SSL_MODE", "disable")
+// Config is read from a yaml file, and then from env vars which override the file. Each setting is
+// named by its yaml path (e.g. rateLimit.policies.login.max) and by its env var (env tag).
+// Durations are written like "90s", "5m" or "1h30m", and lists in env vars are comma separated.
+type Config struct {
+	Server        ServerConfig        `yaml:"server"`
+	Auth          AuthConfig          `yaml:"auth"`
+	Grpc          GrpcConfig          `yaml:"grpc"`
+	Postgres      PostgresConfig      `yaml:"postgres"`
+	Valkey        ValkeyConfig        `yaml:"valkey"`
+	Urls          UrlsConfig          `yaml:"urls"`
+	Domains       DomainsConfig       `yaml:"domains"`
+	GeoIp         GeoIpConfig         `yaml:"geoIp"`
+	Destination   DestinationConfig   `yaml:"destination"`
+	LinkCheck     LinkCheckConfig     `yaml:"linkCheck"`
+	MetadataFetch MetadataFetchConfig `yaml:"metadataFetch"`
+	Qr            QrConfig            `yaml:"qr"`
+	Import        ImportConfig        `yaml:"import"`
+	RateLimit     RateLimitConfig     `yaml:"rateLimit"`
+}
 
-	CacheTTL   = 10 * time.Minute
-	ValkeyAddr = getEnvString("VALKEY_ADDR", "localhost:6379")
+type ServerConfig struct {
+	Addr string `yaml:"addr" env:"SERVER_ADDR" validate:"required,hostname_port"`
 
-	JwtTokenExpiration               = 7 * 24 * time.Hour // 7 days
-	RandomUrlCollisionRetries        = 5
-	RedirectionRateLimitMaxPerWindow = 20
-	RedirectionRateLimitWindow       = 1 * time.Minute
-)
+	// the client IP is read from ProxyHeader (e.g. X-Forwarded-For) only for requests sent by TrustedProxies
+	ProxyHeader    string   `yaml:"proxyHeader" env:"PROXY_HEADER"`
+	TrustedProxies []string `yaml:"trustedProxies" env:"TRUSTED_PROXIES" validate:"dive,ip|cidr"`
 
-func getEnvInt(key string, defaultValue ...int) int {
-	if value, ok := os.LookupEnv(key); ok {
-		if intValue, err := strconv.Atoi(value); err == nil {
-			return intValue
-		}
-		slog.Error("invalid int env var", "key", key, "value", value)
-		os.Exit(1)
-	}
-	if len(defaultValue) == 0 {
-		slog.Error("env var not found", "key", key)
-		os.Exit(1)
-	}
-	return defaultValue[0]
+	// hosts short urls are served on, destinations on them are rejected to avoid redirect loops
+	OwnHosts []string `yaml:"ownHosts" env:"OWN_HOSTS"`
+
+	// also serves the api and redirects on their unversioned paths from before /api/v1, e.g. /urls and
+	// /urls/<short url>. Short urls on the old paths stop working once it's turned off.
+	LegacyRoutes bool `yaml:"legacyRoutes" env:"LEGACY_ROUTES"`
+
+	// encoded in QR codes, e.g. https://sho.rt, taken from the request when empty
+	PublicBaseUrl string `yaml:"publicBaseUrl" env:"PUBLIC_BASE_URL" validate:"omitempty,url"`
+}
+
+type AuthConfig struct {
+	SecretKey          string        `yaml:"secretKey" env:"SECRET_KEY" secret:"true" validate:"required"`
+	JwtTokenExpiration time.Duration `yaml:"jwtTokenExpiration" env:"JWT_TOKEN_EXPIRATION" validate:"gt=0"`
+
+	// always admins, whatever their role in the db. They can't register themselves, operators create
+	// them with the urlshortener command.
+	AdminUsernames []string `yaml:"adminUsernames" env:"ADMIN_USERNAMES"`
+}
+
+// GrpcConfig is the gRPC api for internal services, not served when Addr is empty. Every prefork
+// child listens on it.
+type GrpcConfig struct {
+	Addr    string   `yaml:"addr" env:"GRPC_ADDR" validate:"omitempty,hostname_port"`
+	ApiKeys []string `yaml:"apiKeys" env:"GRPC_API_KEYS" secret:"true"` // key=username pairs, calls with a key act as its user
 }
 
-func getEnvString(key string, defaultValue ...string) string {
-	if value, ok := os.LookupEnv(key); ok {
-		return value
+type PostgresConfig struct {
+	Host     string `yaml:"host" env:"PG_HOST" validate:"required"`
+	Port     int    `yaml:"port" env:"PG_PORT" validate:"min=1,max=65535"`
+	User     string `yaml:"user" env:"PG_USER" validate:"required"`
+	Password string `yaml:"password" env:"PG_PASSWORD" secret:"true" validate:"required"`
+	Name     string `yaml:"name" env:"PG_NAME" validate:"required"`
+	SslMode  string `yaml:"sslMode" env:"PG_SSL_MODE" validate:"oneof=disable allow prefer require verify-ca verify-full"`
+
+	// applies the embedded migrations on start, the server refuses to start on an older schema either way
+	AutoMigrate bool `yaml:"autoMigrate" env:"AUTO_MIGRATE"`
+}
+
+type ValkeyConfig struct {
+	Addr     string        `yaml:"addr" env:"VALKEY_ADDR" validate:"required,hostname_port"`
+	CacheTTL time.Duration `yaml:"cacheTTL" env:"CACHE_TTL" validate:"gt=0"` // of cached redirects and domains
+}
+
+type UrlsConfig struct {
+	RandomUrlCollisionRetries int           `yaml:"randomUrlCollisionRetries" env:"RANDOM_URL_COLLISION_RETRIES" validate:"min=1"`
+	InterstitialDelay         time.Duration `yaml:"interstitialDelay" env:"INTERSTITIAL_DELAY" validate:"min=0"`
+	IdempotencyKeyTTL         time.Duration `yaml:"idempotencyKeyTTL" env:"IDEMPOTENCY_KEY_TTL" validate:"gt=0"`
+	BatchCreateMaxItems       int           `yaml:"batchCreateMaxItems" env:"BATCH_CREATE_MAX_ITEMS" validate:"min=1"`
+
+	// how long a key stays reserved by a request that didn't finish, e.g. its process died. It should be
+	// longer than the slowest request, retries run again once it's over.
+	IdempotencyLockTTL time.Duration `yaml:"idempotencyLockTTL" env:"IDEMPOTENCY_LOCK_TTL" validate:"gte=1s"`
+}
+
+type DomainsConfig struct {
+	// name=value TXT records used instead of the dns to verify custom domains, for local setups
+	TxtRecords []string `yaml:"txtRecords" env:"DOMAIN_TXT_RECORDS"`
+}
+
+type GeoIpConfig struct {
+	DatabasePath string `yaml:"databasePath" env:"GEOIP_DATABASE_PATH"` // .mmdb file, country rules never match when empty
+}
+
+type DestinationConfig struct {
+	AllowedSchemes          []string      `yaml:"allowedSchemes" env:"DESTINATION_ALLOWED_SCHEMES" validate:"min=1"`
+	AllowPrivate            bool          `yaml:"allowPrivate" env:"DESTINATION_ALLOW_PRIVATE"`   // allows localhost and private IPs
+	BlocklistPath           string        `yaml:"blocklistPath" env:"DESTINATION_BLOCKLIST_PATH"` // one domain or url prefix per line
+	BlocklistReloadInterval time.Duration `yaml:"blocklistReloadInterval" env:"DESTINATION_BLOCKLIST_RELOAD_INTERVAL" validate:"gt=0"`
+}
+
+// LinkCheckConfig is about probing destinations in the background so owners can find broken links.
+type LinkCheckConfig struct {
+	Enabled      bool          `yaml:"enabled" env:"LINK_CHECK_ENABLED"`
+	Interval     time.Duration `yaml:"interval" env:"LINK_CHECK_INTERVAL" validate:"gt=0"` // between two checks of a link
+	PollInterval time.Duration `yaml:"pollInterval" env:"LINK_CHECK_POLL_INTERVAL" validate:"gt=0"`
+	BatchSize    int           `yaml:"batchSize" env:"LINK_CHECK_BATCH_SIZE" validate:"min=1"`
+	Concurrency  int           `yaml:"concurrency" env:"LINK_CHECK_CONCURRENCY" validate:"min=1"` // hosts checked at once, per process
+	Timeout      time.Duration `yaml:"timeout" env:"LINK_CHECK_TIMEOUT" validate:"gt=0"`
+	HostDelay    time.Duration `yaml:"hostDelay" env:"LINK_CHECK_HOST_DELAY" validate:"min=0"` // between two requests to the same host
+	UserAgent    string        `yaml:"userAgent" env:"LINK_CHECK_USER_AGENT"`
+}
+
+// MetadataFetchConfig is about the title, description and image of destinations, fetched in the
+// background for dashboards and link previews.
+type MetadataFetchConfig struct {
+	Enabled      bool          `yaml:"enabled" env:"METADATA_FETCH_ENABLED"`
+	PollInterval time.Duration `yaml:"pollInterval" env:"METADATA_FETCH_POLL_INTERVAL" validate:"gt=0"`
+	BatchSize    int           `yaml:"batchSize" env:"METADATA_FETCH_BATCH_SIZE" validate:"min=1"`
+	Concurrency  int           `yaml:"concurrency" env:"METADATA_FETCH_CONCURRENCY" validate:"min=1"` // per process
+	Timeout      time.Duration `yaml:"timeout" env:"METADATA_FETCH_TIMEOUT" validate:"gt=0"`
+	MaxBytes     int           `yaml:"maxBytes" env:"METADATA_FETCH_MAX_BYTES" validate:"min=1"` // of each page that is read
+	UserAgent    string        `yaml:"userAgent" env:"METADATA_FETCH_USER_AGENT"`
+}
+
+type QrConfig struct {
+	LogoFetchTimeout time.Duration `yaml:"logoFetchTimeout" env:"QR_LOGO_FETCH_TIMEOUT" validate:"gt=0"`
+	LogoMaxBytes     int           `yaml:"logoMaxBytes" env:"QR_LOGO_MAX_BYTES" validate:"min=1"`
+}
+
+type ImportConfig struct {
+	MaxFileSize  int           `yaml:"maxFileSize" env:"IMPORT_MAX_FILE_SIZE" validate:"min=1"`   // bytes
+	SyncMaxItems int           `yaml:"syncMaxItems" env:"IMPORT_SYNC_MAX_ITEMS" validate:"min=0"` // larger imports run as background jobs
+	PollInterval time.Duration `yaml:"pollInterval" env:"IMPORT_POLL_INTERVAL" validate:"gt=0"`
+}
+
+type RateLimitConfig struct {
+	Enabled       bool          `yaml:"enabled" env:"RATE_LIMIT_ENABLED"`
+	Allowlist     []string      `yaml:"allowlist" env:"RATE_LIMIT_ALLOWLIST" validate:"dive,ip|cidr"`
+	ApiKeyHeader  string        `yaml:"apiKeyHeader" env:"RATE_LIMIT_API_KEY_HEADER" validate:"required"`
+	ValkeyTimeout time.Duration `yaml:"valkeyTimeout" env:"RATE_LIMIT_VALKEY_TIMEOUT" validate:"gt=0"`
+	// how long the in-memory limiter is used after valkey fails, before valkey is tried again
+	FallbackCooldown time.Duration     `yaml:"fallbackCooldown" env:"RATE_LIMIT_FALLBACK_COOLDOWN" validate:"gt=0"`
+	Policies         RateLimitPolicies `yaml:"policies"`
+}
+
+// RateLimitPolicies has a policy for each rate limit group, its env vars are
+// RATE_LIMIT_<GROUP>_MAX, RATE_LIMIT_<GROUP>_WINDOW and RATE_LIMIT_<GROUP>_KEY_BY.
+type RateLimitPolicies struct {
+	Redirect  RateLimitPolicy `yaml:"redirect" env:"RATE_LIMIT_REDIRECT_"`
+	Create    RateLimitPolicy `yaml:"create" env:"RATE_LIMIT_CREATE_"`
+	Login     RateLimitPolicy `yaml:"login" env:"RATE_LIMIT_LOGIN_"`
+	Analytics RateLimitPolicy `yaml:"analytics" env:"RATE_LIMIT_ANALYTICS_"`
+	Report    RateLimitPolicy `yaml:"report" env:"RATE_LIMIT_REPORT_"`
+}
+
+// Get returns the policy of group, and false for unknown groups.
+func (me RateLimitPolicies) Get(group string) (RateLimitPolicy, bool) {
+	switch group {
+	case RateLimitGroupRedirect:
+		return me.Redirect, true
+	case RateLimitGroupCreate:
+		return me.Create, true
+	case RateLimitGroupLogin:
+		return me.Login, true
+	case RateLimitGroupAnalytics:
+		return me.Analytics, true
+	case RateLimitGroupReport:
+		return me.Report, true
 	}
-	if len(defaultValue) == 0 {
-		slog.Error("env var not found", "key", key)
-		os.Exit(1)
+	return RateLimitPolicy{}, false
+}
+
+const (
+	RateLimitGroupRedirect  = "redirect"
+	RateLimitGroupCreate    = "create"
+	RateLimitGroupLogin     = "login"
+	RateLimitGroupAnalytics = "analytics"
+	RateLimitGroupReport    = "report"
+
+	RateLimitKeyByIp       = "ip"
+	RateLimitKeyByUsername = "username"
+	RateLimitKeyByApiKey   = "apikey"
+)
+
+// RateLimitPolicy allows at most Max requests per Window (sliding) for each key.
+// A Max of 0 disables limiting for the group.
+type RateLimitPolicy struct {
+	Max    int           `yaml:"max" env:"MAX" validate:"min=0"`
+	Window time.Duration `yaml:"window" env:"WINDOW" validate:"gt=0"`
+	KeyBy  string        `yaml:"keyBy" env:"KEY_BY" validate:"oneof=ip username apikey"`
+}
+
+// Default returns the config used for the settings that are neither in the file nor in env vars.
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Addr:           "localhost:8080",
+			TrustedProxies: []string{},
+			OwnHosts:       []string{},
+			LegacyRoutes:   true,
+		},
+		Auth: AuthConfig{
+			JwtTokenExpiration: 7 * 24 * time.Hour,
+			AdminUsernames:     []string{},
+		},
+		Grpc: GrpcConfig{
+			ApiKeys: []string{},
+		},
+		Postgres: PostgresConfig{
+			Host:    "localhost",
+			Port:    5432,
+			User:    "postgres",
+			Name:    "url_shortener",
+			SslMode: "disable",
+		},
+		Valkey: ValkeyConfig{
+			Addr:     "localhost:6379",
+			CacheTTL: 10 * time.Minute,
+		},
+		Urls: UrlsConfig{
+			RandomUrlCollisionRetries: 5,
+			InterstitialDelay:         5 * time.Second,
+			IdempotencyKeyTTL:         24 * time.Hour,
+			BatchCreateMaxItems:       1000,
+			IdempotencyLockTTL:        time.Minute,
+		},
+		Domains: DomainsConfig{
+			TxtRecords: []string{},
+		},
+		Destination: DestinationConfig{
+			AllowedSchemes:          []string{"http", "https"},
+			BlocklistReloadInterval: 30 * time.Second,
+		},
+		LinkCheck: LinkCheckConfig{
+			Enabled:      true,
+			Interval:     24 * time.Hour,
+			PollInterval: time.Minute,
+			BatchSize:    100,
+			Concurrency:  10,
+			Timeout:      10 * time.Second,
+			HostDelay:    time.Second,
+			UserAgent:    "URL Shortener link checker",
+		},
+		MetadataFetch: MetadataFetchConfig{
+			Enabled:      true,
+			PollInterval: time.Minute,
+			BatchSize:    50,
+			Concurrency:  5,
+			Timeout:      5 * time.Second,
+			MaxBytes:     512 * 1024,
+			UserAgent:    "Mozilla/5.0 (compatible; URL Shortener preview fetcher)",
+		},
+		Qr: QrConfig{
+			LogoFetchTimeout: 5 * time.Second,
+			LogoMaxBytes:     1024 * 1024,
+		},
+		Import: ImportConfig{
+			MaxFileSize:  32 * 1024 * 1024,
+			SyncMaxItems: 1000,
+			PollInterval: 10 * time.Second,
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:          true,
+			Allowlist:        []string{},
+			ApiKeyHeader:     "X-API-Key",
+			ValkeyTimeout:    200 * time.Millisecond,
+			FallbackCooldown: 5 * time.Second,
+			Policies: RateLimitPolicies{
+				Redirect:  RateLimitPolicy{Max: 20, Window: time.Minute, KeyBy: RateLimitKeyByIp},
+				Create:    RateLimitPolicy{Max: 60, Window: time.Minute, KeyBy: RateLimitKeyByUsername},
+				Login:     RateLimitPolicy{Max: 10, Window: time.Minute, KeyBy: RateLimitKeyByIp},
+				Analytics: RateLimitPolicy{Max: 30, Window: time.Minute, KeyBy: RateLimitKeyByUsername},
+				Report:    RateLimitPolicy{Max: 5, Window: time.Hour, KeyBy: RateLimitKeyByIp},
+			},
+		},
 	}
-	return defaultValue[0]
 }
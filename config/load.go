@@ -0,0 +1,180 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/assaidy/url_shortener/utils"
+	_ "github.com/joho/godotenv/autoload"
+	"gopkg.in/yaml.v3"
+)
+
+// Load sets Cfg to the defaults, overridden by the yaml file at path when it isn't empty, and then
+// by env vars. All invalid settings are reported together.
+func Load(path string) error {
+	cfg := Default()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("error reading config file: %w", err)
+		}
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true) // typos aren't silently ignored
+		if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("error parsing config file: %w", err)
+		}
+	}
+
+	errs := applyEnv(reflect.ValueOf(cfg).Elem(), "")
+	errs = append(errs, applyRenamedEnv(cfg)...)
+	if err := utils.ValidateStruct(cfg); err != nil {
+		for _, it := range err.(utils.ValidationErrors) {
+			constraint := it.Constraint
+			if it.Param != "" {
+				constraint += "=" + it.Param
+			}
+			errs = append(errs, fmt.Errorf("%s: violation in constraint '%s'", it.Field, constraint))
+		}
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("invalid config:\n%w", errors.Join(errs...))
+	}
+
+	Cfg = cfg
+	return nil
+}
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+// applyEnv overrides the fields of v with the env vars of their env tags. The env tags of struct
+// fields are prefixes of the env vars of their own fields.
+func applyEnv(v reflect.Value, prefix string) []error {
+	errs := []error{}
+	for i := range v.NumField() {
+		field := v.Field(i)
+		tag, ok := v.Type().Field(i).Tag.Lookup("env")
+		if field.Kind() == reflect.Struct {
+			errs = append(errs, applyEnv(field, prefix+tag)...)
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		key := prefix + tag
+		value, ok := os.LookupEnv(key)
+		if !ok {
+			continue
+		}
+		if err := setField(field, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+	return errs
+}
+
+// applyRenamedEnv reads the env vars settings had before they were renamed, so existing .env files
+// keep working. The new env vars win when both are set.
+func applyRenamedEnv(cfg *Config) []error {
+	errs := []error{}
+	if value, ok := os.LookupEnv("JWT_TOKEN_EXPIRATION_DAYS"); ok {
+		if _, ok := os.LookupEnv("JWT_TOKEN_EXPIRATION"); !ok {
+			days, err := strconv.Atoi(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("JWT_TOKEN_EXPIRATION_DAYS: invalid int %q, it's renamed to JWT_TOKEN_EXPIRATION, a duration like 168h", value))
+			} else {
+				cfg.Auth.JwtTokenExpiration = time.Duration(days) * 24 * time.Hour
+			}
+		}
+	}
+	return errs
+}
+
+func setField(field reflect.Value, value string) error {
+	switch {
+	case field.Type() == durationType:
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", value)
+		}
+		field.SetInt(int64(duration))
+	case field.Kind() == reflect.Int:
+		intValue, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid int %q", value)
+		}
+		field.SetInt(int64(intValue))
+	case field.Kind() == reflect.Bool:
+		boolValue, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid bool %q", value)
+		}
+		field.SetBool(boolValue)
+	case field.Kind() == reflect.String:
+		field.SetString(value)
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+		// a comma separated list, ignoring empty items
+		items := []string{}
+		for _, it := range strings.Split(value, ",") {
+			if it = strings.TrimSpace(it); it != "" {
+				items = append(items, it)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+const redacted = "<redacted>"
+
+// Yaml returns the config as a yaml file, with the values of secret settings redacted.
+func (me *Config) Yaml() ([]byte, error) {
+	cfg := *me
+	redact(reflect.ValueOf(&cfg).Elem())
+
+	var b bytes.Buffer
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(cfg); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// redact replaces the values of the fields of v with a secret tag. Slices are copied, so redacting
+// a copy of a config leaves the original untouched.
+func redact(v reflect.Value) {
+	for i := range v.NumField() {
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			redact(field)
+			continue
+		}
+		if v.Type().Field(i).Tag.Get("secret") != "true" {
+			continue
+		}
+
+		switch field.Kind() {
+		case reflect.String:
+			if field.String() != "" {
+				field.SetString(redacted)
+			}
+		case reflect.Slice:
+			items := make([]string, field.Len())
+			for i := range items {
+				items[i] = redacted
+			}
+			field.Set(reflect.ValueOf(items))
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// clearEnv unsets all env vars for the test, so the settings of the shell don't override the tested ones.
+func clearEnv(t *testing.T) {
+	environ := os.Environ()
+	os.Clearenv()
+	t.Cleanup(func() {
+		os.Clearenv()
+		for _, it := range environ {
+			key, value, _ := strings.Cut(it, "=")
+			os.Setenv(key, value)
+		}
+	})
+}
+
+func writeConfigFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	clearEnv(t)
+	path := writeConfigFile(t, `
+auth:
+  secretKey: secret
+  jwtTokenExpiration: 1h
+postgres:
+  password: file-password
+  port: 5433
+rateLimit:
+  policies:
+    login:
+      max: 3
+      window: 10m
+`)
+	t.Setenv("PG_PASSWORD", "env-password")
+	t.Setenv("RATE_LIMIT_LOGIN_KEY_BY", "username")
+	t.Setenv("ADMIN_USERNAMES", "alice, ,bob")
+
+	assert.NoError(t, Load(path))
+	assert.Equal(t, "secret", Cfg.Auth.SecretKey)
+	assert.Equal(t, time.Hour, Cfg.Auth.JwtTokenExpiration)
+	assert.Equal(t, "env-password", Cfg.Postgres.Password)
+	assert.Equal(t, 5433, Cfg.Postgres.Port)
+	assert.Equal(t, []string{"alice", "bob"}, Cfg.Auth.AdminUsernames)
+	assert.Equal(t, RateLimitPolicy{Max: 3, Window: 10 * time.Minute, KeyBy: RateLimitKeyByUsername}, Cfg.RateLimit.Policies.Login)
+
+	// the other settings keep their defaults
+	assert.Equal(t, Default().Server, Cfg.Server)
+	assert.Equal(t, Default().RateLimit.Policies.Redirect, Cfg.RateLimit.Policies.Redirect)
+
+	policy, ok := Cfg.RateLimit.Policies.Get(RateLimitGroupLogin)
+	assert.True(t, ok)
+	assert.Equal(t, 3, policy.Max)
+	_, ok = Cfg.RateLimit.Policies.Get("unknown")
+	assert.False(t, ok)
+}
+
+func TestLoadErrors(t *testing.T) {
+	clearEnv(t)
+	path := writeConfigFile(t, `
+server:
+  addr: localhost:99999
+  trustedProxies: [10.0.0.0/8, proxy]
+postgres:
+  sslMode: maybe
+`)
+	t.Setenv("LINK_CHECK_TIMEOUT", "10")
+	t.Setenv("RATE_LIMIT_REPORT_KEY_BY", "cookie")
+
+	err := Load(path)
+	if assert.Error(t, err) {
+		// all invalid settings are reported at once
+		for _, it := range []string{
+			`LINK_CHECK_TIMEOUT: invalid duration "10"`,
+			"server.addr: violation in constraint 'hostname_port'",
+			"server.trustedProxies[1]: violation in constraint 'ip|cidr'",
+			"auth.secretKey: violation in constraint 'required'",
+			"postgres.password: violation in constraint 'required'",
+			"postgres.sslMode: violation in constraint 'oneof=disable allow prefer require verify-ca verify-full'",
+			"rateLimit.policies.report.keyBy: violation in constraint 'oneof=ip username apikey'",
+		} {
+			assert.Contains(t, err.Error(), it)
+		}
+	}
+
+	err = Load(writeConfigFile(t, "server:\n  adress: localhost:8080\n"))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "field adress not found")
+	}
+
+	err = Load(writeConfigFile(t, "valkey:\n  cacheTTL: 600\n"))
+	assert.Error(t, err, "durations need a unit")
+}
+
+func TestLoadRenamedEnv(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("SECRET_KEY", "secret")
+	t.Setenv("PG_PASSWORD", "password")
+	path := writeConfigFile(t, "auth:\n  jwtTokenExpiration: 1h\n")
+
+	t.Setenv("JWT_TOKEN_EXPIRATION_DAYS", "3")
+	assert.NoError(t, Load(path))
+	assert.Equal(t, 3*24*time.Hour, Cfg.Auth.JwtTokenExpiration)
+
+	t.Setenv("JWT_TOKEN_EXPIRATION", "2h")
+	assert.NoError(t, Load(path))
+	assert.Equal(t, 2*time.Hour, Cfg.Auth.JwtTokenExpiration, "the new env var wins")
+
+	os.Unsetenv("JWT_TOKEN_EXPIRATION")
+	t.Setenv("JWT_TOKEN_EXPIRATION_DAYS", "168h")
+	err := Load(path)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "renamed to JWT_TOKEN_EXPIRATION")
+	}
+}
+
+func TestYaml(t *testing.T) {
+	clearEnv(t)
+	cfg := Default()
+	cfg.Auth.SecretKey = "secret"
+	cfg.Grpc.ApiKeys = []string{"key=alice"}
+
+	data, err := cfg.Yaml()
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "secretKey: <redacted>")
+	assert.Contains(t, string(data), "- <redacted>")
+	assert.Contains(t, string(data), "password: \"\"") // empty secrets are shown as such
+	assert.Contains(t, string(data), "jwtTokenExpiration: 168h0m0s")
+	assert.NotContains(t, string(data), "secret\n")
+	assert.NotContains(t, string(data), "alice")
+
+	// the config itself isn't redacted
+	assert.Equal(t, "secret", cfg.Auth.SecretKey)
+	assert.Equal(t, []string{"key=alice"}, cfg.Grpc.ApiKeys)
+
+	// the dump loads back as the same config
+	t.Setenv("SECRET_KEY", "secret")
+	t.Setenv("PG_PASSWORD", "password")
+	t.Setenv("GRPC_API_KEYS", "key=alice")
+	assert.NoError(t, Load(writeConfigFile(t, string(data))))
+	cfg.Postgres.Password = "password"
+	assert.Equal(t, cfg, Cfg)
+}
@@ -4,8 +4,6 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"log/slog"
-	"os"
 	"time"
 
 	"github.com/assaidy/url_shortener/config"
@@ -14,23 +12,24 @@ import (
 
 var DB *sql.DB
 
-func init() {
+// Connect opens DB and checks that the server is reachable. It must be called before starting the services.
+func Connect() error {
 	conn, err := sql.Open("postgres", fmt.Sprintf(
 		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		config.PgHost, config.PgPort, config.PgUser, config.PgPassword, config.PgName, config.PgSSL,
+		config.Cfg.Postgres.Host, config.Cfg.Postgres.Port, config.Cfg.Postgres.User, config.Cfg.Postgres.Password, config.Cfg.Postgres.Name, config.Cfg.Postgres.SslMode,
 	))
 	if err != nil {
-		slog.Error("error connecting to postgres db", "err", err)
-		os.Exit(1)
+		return fmt.Errorf("error connecting to postgres db: %w", err)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if err := conn.PingContext(ctx); err != nil {
-		slog.Error("error pinging postgres db", "err", err)
-		os.Exit(1)
+		conn.Close()
+		return fmt.Errorf("error pinging postgres db: %w", err)
 	}
 
 	DB = conn
+	return nil
 }
@@ -0,0 +1,104 @@
+package postgres_db
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// newMigrationProvider returns a goose provider of the embedded migrations. Migrating takes a
+// session advisory lock, so when prefork children or replicas start together only one of them
+// applies the migrations and the others wait for it.
+func newMigrationProvider() (*goose.Provider, error) {
+	fsys, err := fs.Sub(migrations, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("error reading embedded migrations: %w", err)
+	}
+
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, fmt.Errorf("error creating migrations lock: %w", err)
+	}
+
+	provider, err := goose.NewProvider(goose.DialectPostgres, DB, fsys, goose.WithSessionLocker(locker))
+	if err != nil {
+		return nil, fmt.Errorf("error creating migration provider: %w", err)
+	}
+	return provider, nil
+}
+
+// Migrate applies the pending migrations to DB.
+func Migrate(ctx context.Context) error {
+	provider, err := newMigrationProvider()
+	if err != nil {
+		return err
+	}
+
+	results, err := provider.Up(ctx)
+	if err != nil {
+		return fmt.Errorf("error applying migrations: %w", err)
+	}
+	for _, it := range results {
+		slog.Info("applied migration", "version", it.Source.Version, "duration", it.Duration, "PID", os.Getpid())
+	}
+
+	return nil
+}
+
+// MigrateDown rolls back the last applied migration.
+func MigrateDown(ctx context.Context) error {
+	provider, err := newMigrationProvider()
+	if err != nil {
+		return err
+	}
+
+	result, err := provider.Down(ctx)
+	if err != nil {
+		return fmt.Errorf("error rolling back migration: %w", err)
+	}
+	slog.Info("rolled back migration", "version", result.Source.Version, "duration", result.Duration)
+
+	return nil
+}
+
+// GetMigrationsStatus returns all embedded migrations, oldest first, with whether they are applied.
+func GetMigrationsStatus(ctx context.Context) ([]*goose.MigrationStatus, error) {
+	provider, err := newMigrationProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := provider.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting migrations status: %w", err)
+	}
+	return status, nil
+}
+
+// CheckSchemaVersion fails when DB is behind the last embedded migration. A newer schema is fine, so
+// the previous release keeps serving during a rollout.
+func CheckSchemaVersion(ctx context.Context) error {
+	provider, err := newMigrationProvider()
+	if err != nil {
+		return err
+	}
+
+	current, target, err := provider.GetVersions(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting schema version: %w", err)
+	}
+	if current < target {
+		return fmt.Errorf("schema version %d is behind the expected %d, migrate the db first", current, target)
+	}
+
+	return nil
+}
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+create index short_urls_username_idx on short_urls (username);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+create index url_visits_short_url_idx on url_visits (short_url);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index url_visits_short_url_idx;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+drop index short_urls_username_idx;
+-- +goose StatementEnd
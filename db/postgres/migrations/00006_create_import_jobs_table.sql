@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+create table import_jobs (
+    id bigserial,
+    username varchar(20) not null,
+    status varchar(20) not null default 'pending', -- pending, running, completed, failed
+    on_conflict varchar(20) not null, -- skip, fail, rename
+    items jsonb not null, -- rows to import, cleared when the job finishes
+    report jsonb not null default '[]',
+    total int not null,
+    created int not null default 0,
+    skipped int not null default 0,
+    failed int not null default 0,
+    error varchar not null default '',
+    created_at timestamp not null default now(),
+    finished_at timestamp,
+
+    primary key (id),
+    foreign key (username) references users (username) on delete cascade
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table import_jobs;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table short_urls
+    add column redirect_status int not null default 302 check (redirect_status in (301, 302, 307, 308)),
+    add column cache_policy varchar(20) not null default ''; -- sent as Cache-Control, empty sends nothing
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table short_urls
+    drop column cache_policy,
+    drop column redirect_status;
+-- +goose StatementEnd
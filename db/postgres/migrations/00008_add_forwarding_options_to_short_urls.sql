@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table short_urls
+    add column forward_path boolean not null default false,
+    add column query_forwarding varchar(20) not null default ''; -- empty, incoming-wins, destination-wins or keep-both
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table short_urls
+    drop column query_forwarding,
+    drop column forward_path;
+-- +goose StatementEnd
@@ -0,0 +1,55 @@
+-- +goose Up
+-- +goose StatementBegin
+create table utm_templates (
+    id bigserial,
+    username varchar(20) not null,
+    name varchar(50) not null,
+    utm_source varchar(100) not null,
+    utm_medium varchar(100) not null default '',
+    utm_campaign varchar(100) not null default '',
+    utm_term varchar(100) not null default '',
+    utm_content varchar(100) not null default '',
+    created_at timestamp not null default now(),
+
+    primary key (id),
+    unique (username, name),
+    foreign key (username) references users (username) on delete cascade
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+create table campaigns (
+    id bigserial,
+    username varchar(20) not null,
+    name varchar(50) not null,
+    utm_template_id bigint,
+    created_at timestamp not null default now(),
+
+    primary key (id),
+    unique (username, name),
+    foreign key (username) references users (username) on delete cascade,
+    foreign key (utm_template_id) references utm_templates (id) on delete set null
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+alter table short_urls
+    add column campaign_id bigint references campaigns (id) on delete set null;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+create index short_urls_campaign_id_idx on short_urls (campaign_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table short_urls drop column campaign_id;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+drop table campaigns;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+drop table utm_templates;
+-- +goose StatementEnd
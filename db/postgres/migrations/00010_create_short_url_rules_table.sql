@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+create table short_url_rules (
+    short_url varchar not null,
+    position int not null, -- rules are evaluated in ascending position
+    device varchar(10) not null default '', -- empty matches any device
+    os varchar(10) not null default '', -- empty matches any os
+    language varchar(35) not null default '', -- empty matches any language
+    long_url varchar not null,
+
+    primary key (short_url, position),
+    foreign key (short_url) references short_urls (short_url) on delete cascade
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table short_url_rules;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table short_url_rules
+    add column countries varchar(2)[] not null default '{}'; -- ISO 3166-1 alpha-2 codes, empty matches any country
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table short_url_rules drop column countries;
+-- +goose StatementEnd
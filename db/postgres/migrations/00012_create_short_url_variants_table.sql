@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+create table short_url_variants (
+    short_url varchar not null,
+    name varchar(50) not null,
+    long_url varchar not null,
+    weight int not null, -- share of visitors is weight / sum of weights
+
+    primary key (short_url, name),
+    foreign key (short_url) references short_urls (short_url) on delete cascade
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+alter table url_visits
+    add column variant varchar(50) not null default ''; -- empty when the short url had no variants
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table url_visits drop column variant;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+drop table short_url_variants;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+create table short_url_schedules (
+    short_url varchar not null,
+    at timestamp not null,
+    state varchar(20) not null, -- active, coming-soon or expired
+    long_url varchar not null default '', -- replaces the short url destination while active when set
+
+    primary key (short_url, at),
+    foreign key (short_url) references short_urls (short_url) on delete cascade
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table short_url_schedules;
+-- +goose StatementEnd
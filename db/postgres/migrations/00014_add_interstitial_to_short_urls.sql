@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table short_urls
+    add column interstitial boolean not null default false; -- visitors see the destination with a countdown before the redirect
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table short_urls drop column interstitial;
+-- +goose StatementEnd
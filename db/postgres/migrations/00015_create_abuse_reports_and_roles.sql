@@ -0,0 +1,52 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table users
+    add column role varchar(20) not null default 'user' check (role in ('user', 'moderator', 'admin'));
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+alter table short_urls
+    add column disabled_at timestamp, -- disabled short urls show a page instead of redirecting
+    add column disabled_reason varchar(20) not null default '';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+create table abuse_reports (
+    id bigserial,
+    short_url varchar not null,
+    reason varchar(20) not null, -- phishing, malware, spam, illegal or other
+    details varchar(1000) not null default '',
+    reporter_ip varchar(50) not null, -- IPv4/IPv6
+    status varchar(20) not null default 'open', -- open, dismissed or actioned
+    created_at timestamp not null default now(),
+    resolved_at timestamp,
+    resolved_by varchar(20) not null default '',
+
+    primary key (id),
+    foreign key (short_url) references short_urls (short_url) on delete cascade
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- one open report per reporter and short url, so the queue can't be flooded
+create unique index abuse_reports_open_idx on abuse_reports (short_url, reporter_ip) where status = 'open';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+create index abuse_reports_status_idx on abuse_reports (status, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table abuse_reports;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+alter table short_urls
+    drop column disabled_reason,
+    drop column disabled_at;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+alter table users drop column role;
+-- +goose StatementEnd
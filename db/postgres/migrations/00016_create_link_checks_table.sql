@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+create table link_checks (
+    short_url varchar,
+    checked_url varchar not null, -- the long url when it was claimed, results of an older long url are stale
+    status_code int not null default 0, -- of the final response, 0 when there was none
+    final_url varchar not null default '', -- after following redirects
+    error varchar not null default '',
+    broken boolean not null default false,
+    checked_at timestamp, -- null until the first check finishes
+    next_check_at timestamp not null, -- also pushed forward while a check runs, so no other worker claims it
+
+    primary key (short_url),
+    foreign key (short_url) references short_urls (short_url) on delete cascade
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+create index link_checks_next_check_at_idx on link_checks (next_check_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table link_checks;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+create table short_url_metadata (
+    short_url varchar,
+    fetched_url varchar not null default '', -- the long url the fetched fields are from, they are fetched again when it changes
+    title varchar(300) not null default '',
+    description varchar(1000) not null default '',
+    image_url varchar not null default '',
+    fetch_error varchar not null default '',
+    fetched_at timestamp,
+    claimed_until timestamp, -- while a worker fetches the page
+    -- set by the owner, used instead of the fetched fields when not null
+    custom_title varchar(300),
+    custom_description varchar(1000),
+    custom_image_url varchar,
+
+    primary key (short_url),
+    foreign key (short_url) references short_urls (short_url) on delete cascade
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table short_url_metadata;
+-- +goose StatementEnd
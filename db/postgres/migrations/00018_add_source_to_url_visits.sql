@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table url_visits
+    add column source varchar(20) not null default ''; -- how the visitor got the short url, e.g. qr, empty when unknown
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table url_visits drop column source;
+-- +goose StatementEnd
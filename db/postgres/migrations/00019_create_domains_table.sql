@@ -0,0 +1,192 @@
+-- +goose Up
+-- +goose StatementBegin
+create table domains (
+    domain varchar(253),
+    username varchar(20) not null,
+    verification_token varchar(64) not null, -- expected in a TXT record of the domain
+    verified_at timestamp, -- null until the TXT record is found
+    created_at timestamp not null default now(),
+
+    primary key (domain, username),
+    foreign key (username) references users (username) on delete cascade
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- a domain can be claimed by many users until one of them verifies it
+create unique index domains_verified_idx on domains (domain) where verified_at is not null;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- short urls are unique per domain, so everything referencing them gets a domain too
+alter table url_visits drop constraint url_visits_short_url_fkey;
+alter table short_url_rules drop constraint short_url_rules_short_url_fkey;
+alter table short_url_variants drop constraint short_url_variants_short_url_fkey;
+alter table short_url_schedules drop constraint short_url_schedules_short_url_fkey;
+alter table abuse_reports drop constraint abuse_reports_short_url_fkey;
+alter table link_checks drop constraint link_checks_short_url_fkey;
+alter table short_url_metadata drop constraint short_url_metadata_short_url_fkey;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- short urls of the main domain have an empty domain
+alter table short_urls
+    add column domain varchar(253) not null default '',
+    drop constraint short_urls_pkey,
+    add primary key (domain, short_url);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+alter table url_visits
+    add column domain varchar(253) not null default '',
+    add foreign key (domain, short_url) references short_urls (domain, short_url) on delete cascade;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+alter table short_url_rules
+    add column domain varchar(253) not null default '',
+    drop constraint short_url_rules_pkey,
+    add primary key (domain, short_url, position),
+    add foreign key (domain, short_url) references short_urls (domain, short_url) on delete cascade;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+alter table short_url_variants
+    add column domain varchar(253) not null default '',
+    drop constraint short_url_variants_pkey,
+    add primary key (domain, short_url, name),
+    add foreign key (domain, short_url) references short_urls (domain, short_url) on delete cascade;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+alter table short_url_schedules
+    add column domain varchar(253) not null default '',
+    drop constraint short_url_schedules_pkey,
+    add primary key (domain, short_url, at),
+    add foreign key (domain, short_url) references short_urls (domain, short_url) on delete cascade;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+alter table abuse_reports
+    add column domain varchar(253) not null default '',
+    add foreign key (domain, short_url) references short_urls (domain, short_url) on delete cascade;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+alter table link_checks
+    add column domain varchar(253) not null default '',
+    drop constraint link_checks_pkey,
+    add primary key (domain, short_url),
+    add foreign key (domain, short_url) references short_urls (domain, short_url) on delete cascade;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+alter table short_url_metadata
+    add column domain varchar(253) not null default '',
+    drop constraint short_url_metadata_pkey,
+    add primary key (domain, short_url),
+    add foreign key (domain, short_url) references short_urls (domain, short_url) on delete cascade;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+drop index url_visits_short_url_idx;
+create index url_visits_short_url_idx on url_visits (domain, short_url);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+drop index abuse_reports_open_idx;
+create unique index abuse_reports_open_idx on abuse_reports (domain, short_url, reporter_ip) where status = 'open';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+delete from short_urls where domain <> '';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+drop index abuse_reports_open_idx;
+create unique index abuse_reports_open_idx on abuse_reports (short_url, reporter_ip) where status = 'open';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+drop index url_visits_short_url_idx;
+create index url_visits_short_url_idx on url_visits (short_url);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+alter table short_url_metadata drop column domain;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+alter table link_checks drop column domain;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+alter table abuse_reports drop column domain;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+alter table short_url_schedules drop column domain;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+alter table short_url_variants drop column domain;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+alter table short_url_rules drop column domain;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+alter table url_visits drop column domain;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+alter table short_urls
+    drop column domain,
+    add primary key (short_url);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+alter table url_visits
+    add foreign key (short_url) references short_urls (short_url) on delete cascade;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+alter table short_url_rules
+    add primary key (short_url, position),
+    add foreign key (short_url) references short_urls (short_url) on delete cascade;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+alter table short_url_variants
+    add primary key (short_url, name),
+    add foreign key (short_url) references short_urls (short_url) on delete cascade;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+alter table short_url_schedules
+    add primary key (short_url, at),
+    add foreign key (short_url) references short_urls (short_url) on delete cascade;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+alter table abuse_reports
+    add foreign key (short_url) references short_urls (short_url) on delete cascade;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+alter table link_checks
+    add primary key (short_url),
+    add foreign key (short_url) references short_urls (short_url) on delete cascade;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+alter table short_url_metadata
+    add primary key (short_url),
+    add foreign key (short_url) references short_urls (short_url) on delete cascade;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+drop table domains;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table import_jobs
+    add column claimed_until timestamp; -- while a worker imports the items, the job is taken over by another one after it
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- report rows of the imported batches of a running job, they are committed with the short urls of the batch so
+-- a job that is taken over resumes after them
+create table import_job_batches (
+    job_id bigint,
+    start_index int, -- of the first item of the batch
+    report jsonb not null,
+
+    primary key (job_id, start_index),
+    foreign key (job_id) references import_jobs (id) on delete cascade
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table import_job_batches;
+alter table import_jobs drop column claimed_until;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table import_jobs
+    add column domain varchar(253) not null default ''; -- of the imported short urls, empty for the main domain
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table import_jobs drop column domain;
+-- +goose StatementEnd
@@ -0,0 +1,62 @@
+-- name: GetShortUrlsStats :many
+select
+    s.short_url,
+    s.long_url,
+    coalesce(c.name, '')::varchar as campaign,
+    count(v.short_url) as visits,
+    count(distinct v.visitor_ip) as unique_visitors
+from short_urls s
+left join campaigns c on c.id = s.campaign_id
+left join url_visits v on v.domain = s.domain and v.short_url = s.short_url and v.visited_at >= @from_time and v.visited_at < @to_time
+where s.username = @username and s.domain = @domain and (@campaign::varchar = '' or c.name = @campaign::varchar)
+group by s.short_url, s.long_url, c.name
+order by visits desc, s.short_url;
+
+-- name: GetShortUrlStats :one
+select
+    count(*) as visits,
+    count(distinct visitor_ip) as unique_visitors
+from url_visits
+where domain = @domain and short_url = @short_url and visited_at >= @from_time and visited_at < @to_time;
+
+-- name: GetShortUrlDailyVisits :many
+select
+    date_trunc('day', visited_at)::timestamp as day,
+    count(*) as visits
+from url_visits
+where domain = @domain and short_url = @short_url and visited_at >= @from_time and visited_at < @to_time
+group by day
+order by day;
+
+-- name: GetCampaignsStats :many
+select
+    c.name,
+    count(distinct (s.domain, s.short_url)) as short_urls,
+    count(v.short_url) as visits,
+    count(distinct v.visitor_ip) as unique_visitors
+from campaigns c
+left join short_urls s on s.campaign_id = c.id
+left join url_visits v on v.domain = s.domain and v.short_url = s.short_url and v.visited_at >= @from_time and v.visited_at < @to_time
+where c.username = @username
+group by c.id, c.name
+order by c.name;
+
+-- name: GetShortUrlVariantVisits :many
+select
+    variant,
+    count(*) as visits,
+    count(distinct visitor_ip) as unique_visitors
+from url_visits
+where domain = @domain and short_url = @short_url and variant <> '' and visited_at >= @from_time and visited_at < @to_time
+group by variant
+order by variant;
+
+-- name: GetShortUrlSourceVisits :many
+select
+    source,
+    count(*) as visits,
+    count(distinct visitor_ip) as unique_visitors
+from url_visits
+where domain = @domain and short_url = @short_url and source <> '' and visited_at >= @from_time and visited_at < @to_time
+group by source
+order by source;
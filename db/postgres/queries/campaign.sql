@@ -0,0 +1,40 @@
+-- name: InsertUtmTemplate :execrows
+insert into utm_templates (username, name, utm_source, utm_medium, utm_campaign, utm_term, utm_content)
+values ($1, $2, $3, $4, $5, $6, $7)
+on conflict (username, name) do nothing;
+
+-- name: GetUtmTemplatesByUsername :many
+select * from utm_templates where username = $1 order by name;
+
+-- name: GetUtmTemplateByName :one
+select * from utm_templates where username = $1 and name = $2;
+
+-- name: GetUtmTemplateById :one
+select * from utm_templates where id = $1;
+
+-- name: DeleteUtmTemplate :execrows
+delete from utm_templates where username = $1 and name = $2;
+
+-- name: InsertCampaign :execrows
+insert into campaigns (username, name, utm_template_id)
+values ($1, $2, $3)
+on conflict (username, name) do nothing;
+
+-- name: GetCampaignsByUsername :many
+select
+    c.name,
+    coalesce(t.name, '')::varchar as utm_template,
+    c.created_at
+from campaigns c
+left join utm_templates t on t.id = c.utm_template_id
+where c.username = $1
+order by c.name;
+
+-- name: GetCampaignByName :one
+select * from campaigns where username = $1 and name = $2;
+
+-- name: GetCampaignById :one
+select * from campaigns where id = $1;
+
+-- name: DeleteCampaign :execrows
+delete from campaigns where username = $1 and name = $2;
@@ -0,0 +1,33 @@
+-- name: InsertDomain :execrows
+insert into domains (domain, username, verification_token)
+values ($1, $2, $3)
+on conflict (domain, username) do nothing;
+
+-- name: GetDomain :one
+select * from domains where domain = $1 and username = $2;
+
+-- name: GetDomainForUpdate :one
+select * from domains where domain = $1 and username = $2 for update;
+
+-- name: GetDomainsByUsername :many
+select * from domains where username = $1 order by domain;
+
+-- name: GetVerifiedDomainOwner :one
+select username from domains where domain = $1 and verified_at is not null;
+
+-- name: GetVerifiedDomains :many
+select domain from domains where verified_at is not null;
+
+-- name: VerifyDomain :exec
+update domains
+set verified_at = now()
+where domain = $1 and username = $2;
+
+-- name: DeletePendingDomainClaims :exec
+delete from domains where domain = $1 and verified_at is null;
+
+-- name: DeleteDomain :exec
+delete from domains where domain = $1 and username = $2;
+
+-- name: DeleteDomainShortUrls :exec
+delete from short_urls where domain = $1;
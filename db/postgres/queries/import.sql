@@ -0,0 +1,51 @@
+-- name: InsertImportJob :one
+insert into import_jobs (username, domain, on_conflict, items, total)
+values ($1, $2, $3, $4, $5)
+returning id;
+
+-- name: ClaimImportJob :one
+update import_jobs
+set status = 'running', claimed_until = @claimed_until
+where id = @id
+    and (status = 'pending' or (status = 'running' and (claimed_until is null or claimed_until <= @now)))
+returning username, domain, items, on_conflict;
+
+-- name: GetClaimableImportJobIds :many
+select id from import_jobs
+where status = 'pending' or (status = 'running' and (claimed_until is null or claimed_until <= @now))
+order by id;
+
+-- name: ExtendImportJobClaim :exec
+update import_jobs set claimed_until = @claimed_until where id = @id and status = 'running';
+
+-- name: InsertImportJobBatch :exec
+insert into import_job_batches (job_id, start_index, report)
+values ($1, $2, $3);
+
+-- name: GetImportJobBatches :many
+select start_index, report from import_job_batches where job_id = $1 order by start_index;
+
+-- name: FinishImportJob :exec
+with deleted_batches as (
+    delete from import_job_batches where job_id = $1
+)
+update import_jobs
+set
+    status = $2,
+    report = $3,
+    created = $4,
+    skipped = $5,
+    failed = $6,
+    error = $7,
+    items = '[]',
+    claimed_until = null,
+    finished_at = now()
+where id = $1;
+
+-- name: GetImportJob :one
+select id, username, domain, status, on_conflict, total, created, skipped, failed, error, created_at, finished_at
+from import_jobs
+where id = $1 and username = $2;
+
+-- name: GetImportJobReport :one
+select report from import_jobs where id = $1 and username = $2;
@@ -0,0 +1,31 @@
+-- name: ClaimLinkChecks :many
+with due as (
+    select s.domain, s.short_url, s.long_url
+    from short_urls s
+    left join link_checks c on c.domain = s.domain and c.short_url = s.short_url
+    where s.disabled_at is null and (c.short_url is null or c.next_check_at <= @now)
+    order by c.next_check_at nulls first
+    limit @batch_size
+    for update of s skip locked
+)
+insert into link_checks (domain, short_url, checked_url, next_check_at)
+select domain, short_url, long_url, @lease_until from due
+on conflict (domain, short_url) do update
+set
+    checked_url = excluded.checked_url,
+    next_check_at = excluded.next_check_at
+returning domain, short_url, checked_url;
+
+-- name: FinishLinkCheck :exec
+update link_checks
+set
+    status_code = @status_code,
+    final_url = @final_url,
+    error = @error,
+    broken = @broken,
+    checked_at = @checked_at,
+    next_check_at = @next_check_at
+where domain = @domain and short_url = @short_url and checked_url = @checked_url;
+
+-- name: DeleteLinkCheck :exec
+delete from link_checks where domain = $1 and short_url = $2;
@@ -0,0 +1,63 @@
+-- name: ClaimMetadataFetches :many
+with due as (
+    select s.domain, s.short_url, s.long_url
+    from short_urls s
+    left join short_url_metadata m on m.domain = s.domain and m.short_url = s.short_url
+    where s.disabled_at is null
+        and (m.short_url is null or m.fetched_url <> s.long_url)
+        and (m.claimed_until is null or m.claimed_until <= @now)
+    order by s.created_at desc
+    limit @batch_size
+    for update of s skip locked
+), claimed as (
+    insert into short_url_metadata (domain, short_url, claimed_until)
+    select domain, short_url, @claimed_until from due
+    on conflict (domain, short_url) do update
+    set claimed_until = excluded.claimed_until
+)
+select domain, short_url, long_url from due;
+
+-- name: FinishMetadataFetch :exec
+update short_url_metadata
+set
+    fetched_url = @fetched_url,
+    title = @title,
+    description = @description,
+    image_url = @image_url,
+    fetch_error = @fetch_error,
+    fetched_at = @fetched_at,
+    claimed_until = null
+where domain = @domain and short_url = @short_url;
+
+-- name: GetShortUrlMetadata :one
+select
+    title,
+    description,
+    image_url,
+    fetch_error,
+    fetched_at,
+    custom_title,
+    custom_description,
+    custom_image_url
+from short_url_metadata
+where domain = $1 and short_url = $2;
+
+-- name: SetShortUrlCustomMetadata :exec
+insert into short_url_metadata (domain, short_url, custom_title, custom_description, custom_image_url)
+values ($1, $2, $3, $4, $5)
+on conflict (domain, short_url) do update
+set
+    custom_title = excluded.custom_title,
+    custom_description = excluded.custom_description,
+    custom_image_url = excluded.custom_image_url;
+
+-- name: ClearFetchedMetadata :exec
+update short_url_metadata
+set
+    fetched_url = '',
+    title = '',
+    description = '',
+    image_url = '',
+    fetch_error = '',
+    fetched_at = null
+where domain = $1 and short_url = $2;
@@ -0,0 +1,54 @@
+-- name: InsertAbuseReport :execrows
+insert into abuse_reports (domain, short_url, reason, details, reporter_ip)
+values ($1, $2, $3, $4, $5)
+on conflict (domain, short_url, reporter_ip) where status = 'open' do nothing;
+
+-- name: GetAbuseReports :many
+select
+    r.id,
+    r.domain,
+    r.short_url,
+    s.long_url,
+    s.username as owner,
+    (s.disabled_at is not null)::boolean as disabled,
+    r.reason,
+    r.details,
+    r.status,
+    r.created_at,
+    r.resolved_at,
+    r.resolved_by
+from abuse_reports r
+join short_urls s on s.domain = r.domain and s.short_url = r.short_url
+where r.status = @status and r.id > @after_id
+order by r.id
+limit @page_size;
+
+-- name: ResolveAbuseReport :execrows
+update abuse_reports
+set
+    status = $2,
+    resolved_at = now(),
+    resolved_by = $3
+where id = $1 and status = 'open';
+
+-- name: ResolveShortUrlAbuseReports :exec
+update abuse_reports
+set
+    status = $3,
+    resolved_at = now(),
+    resolved_by = $4
+where domain = $1 and short_url = $2 and status = 'open';
+
+-- name: DisableShortUrl :execrows
+update short_urls
+set
+    disabled_at = now(),
+    disabled_reason = $3
+where domain = $1 and short_url = $2;
+
+-- name: EnableShortUrl :execrows
+update short_urls
+set
+    disabled_at = null,
+    disabled_reason = ''
+where domain = $1 and short_url = $2;
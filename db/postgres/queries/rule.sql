@@ -0,0 +1,68 @@
+-- name: GetShortUrlRules :many
+select device, os, language, countries, long_url
+from short_url_rules
+where domain = $1 and short_url = $2
+order by position;
+
+-- name: DeleteShortUrlRules :exec
+delete from short_url_rules where domain = $1 and short_url = $2;
+
+-- name: InsertShortUrlRules :exec
+with rules_data as (
+    select v, position from jsonb_array_elements(@json_rules::jsonb) with ordinality as t(v, position)
+)
+insert into short_url_rules (domain, short_url, position, device, os, language, countries, long_url)
+select
+    @domain::varchar,
+    @short_url::varchar,
+    position,
+    v ->> 'device',
+    v ->> 'os',
+    v ->> 'language',
+    array(select jsonb_array_elements_text(coalesce(v -> 'countries', '[]'))),
+    v ->> 'longUrl'
+from rules_data;
+
+-- name: GetShortUrlVariants :many
+select name, long_url, weight
+from short_url_variants
+where domain = $1 and short_url = $2
+order by name;
+
+-- name: DeleteShortUrlVariants :exec
+delete from short_url_variants where domain = $1 and short_url = $2;
+
+-- name: InsertShortUrlVariants :exec
+with variants_data as (
+    select jsonb_array_elements(@json_variants::jsonb) as v
+)
+insert into short_url_variants (domain, short_url, name, long_url, weight)
+select
+    @domain::varchar,
+    @short_url::varchar,
+    v ->> 'name',
+    v ->> 'longUrl',
+    (v ->> 'weight')::int
+from variants_data;
+
+-- name: GetShortUrlSchedule :many
+select at, state, long_url
+from short_url_schedules
+where domain = $1 and short_url = $2
+order by at;
+
+-- name: DeleteShortUrlSchedule :exec
+delete from short_url_schedules where domain = $1 and short_url = $2;
+
+-- name: InsertShortUrlSchedule :exec
+with schedule_data as (
+    select jsonb_array_elements(@json_schedule::jsonb) as v
+)
+insert into short_url_schedules (domain, short_url, at, state, long_url)
+select
+    @domain::varchar,
+    @short_url::varchar,
+    (v ->> 'at')::timestamptz at time zone 'utc',
+    v ->> 'state',
+    coalesce(v ->> 'longUrl', '')
+from schedule_data;
@@ -1,12 +1,117 @@
 -- name: CheckShortUrl :one
-select exists (select 1 from short_urls where short_url = $1 for update);
+select exists (select 1 from short_urls where domain = $1 and short_url = $2 for update);
 
 -- name: InsertShortUrl :exec
-insert into short_urls (username, long_url, short_url)
-values ($1, $2, $3);
+insert into short_urls (username, domain, long_url, short_url, redirect_status, cache_policy, forward_path, query_forwarding, campaign_id, interstitial)
+values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+
+-- name: GetExistingShortUrls :many
+select short_url from short_urls where domain = @domain and short_url = any(@short_urls::varchar[]) for update;
+
+-- name: InsertShortUrls :many
+with short_urls_data as (
+    select jsonb_array_elements(@json_short_urls::jsonb) as v
+)
+insert into short_urls (username, domain, long_url, short_url, redirect_status, cache_policy, forward_path, query_forwarding, campaign_id, interstitial)
+select
+    v ->> 'username',
+    v ->> 'domain',
+    v ->> 'longUrl',
+    v ->> 'shortUrl',
+    (v ->> 'redirectStatus')::int,
+    v ->> 'cachePolicy',
+    (v ->> 'forwardPath')::boolean,
+    v ->> 'queryForwarding',
+    (v ->> 'campaignId')::bigint,
+    (v ->> 'interstitial')::boolean
+from short_urls_data
+on conflict (domain, short_url) do nothing
+returning short_url;
 
 -- name: GetLongUrl :one
-select long_url from short_urls where short_url = $1;
+select
+    s.long_url,
+    s.redirect_status,
+    s.cache_policy,
+    s.forward_path,
+    s.query_forwarding,
+    s.interstitial,
+    (s.disabled_at is not null)::boolean as disabled,
+    s.disabled_reason,
+    coalesce(m.custom_title, m.title, '')::varchar as title,
+    coalesce(m.custom_description, m.description, '')::varchar as description,
+    coalesce(m.custom_image_url, m.image_url, '')::varchar as image_url
+from short_urls s
+left join short_url_metadata m on m.domain = s.domain and m.short_url = s.short_url
+where s.domain = $1 and s.short_url = $2;
+
+-- name: GetShortUrlForUpdate :one
+select * from short_urls where domain = $1 and short_url = $2 for update;
+
+-- name: UpdateShortUrl :exec
+update short_urls
+set
+    long_url = $3,
+    redirect_status = $4,
+    cache_policy = $5,
+    forward_path = $6,
+    query_forwarding = $7,
+    interstitial = $8
+where domain = $1 and short_url = $2;
+
+-- name: GetShortUrlPreview :one
+select
+    s.username,
+    s.long_url,
+    s.created_at,
+    (s.disabled_at is not null)::boolean as disabled,
+    s.disabled_reason,
+    (select count(*) from url_visits v where v.domain = s.domain and v.short_url = s.short_url) as visits,
+    (exists (select 1 from short_url_rules r where r.domain = s.domain and r.short_url = s.short_url)
+        or exists (select 1 from short_url_variants r where r.domain = s.domain and r.short_url = s.short_url))::boolean as has_other_destinations
+from short_urls s
+where s.domain = $1 and s.short_url = $2;
+
+-- name: GetShortUrlOwner :one
+select username from short_urls where domain = $1 and short_url = $2;
+
+-- name: GetShortUrlsByUsername :many
+select
+    s.short_url,
+    s.long_url,
+    s.created_at,
+    (case
+        when @with_visits::boolean then (select count(*) from url_visits v where v.domain = s.domain and v.short_url = s.short_url)
+        else 0
+    end)::bigint as visits
+from short_urls s
+where s.username = @username and s.domain = @domain and s.short_url > @after_short_url
+order by s.short_url
+limit @page_size;
+
+-- name: GetShortUrlsForListing :many
+select
+    s.short_url,
+    s.long_url,
+    s.created_at,
+    (s.disabled_at is not null)::boolean as disabled,
+    coalesce(m.custom_title, m.title, '')::varchar as title,
+    coalesce(m.custom_description, m.description, '')::varchar as description,
+    coalesce(m.custom_image_url, m.image_url, '')::varchar as image_url,
+    coalesce(c.status_code, 0)::int as status_code,
+    coalesce(c.final_url, '')::varchar as final_url,
+    coalesce(c.error, '')::varchar as error,
+    coalesce(c.broken, false)::boolean as broken,
+    c.checked_at
+from short_urls s
+left join link_checks c on c.domain = s.domain and c.short_url = s.short_url and c.checked_url = s.long_url
+left join short_url_metadata m on m.domain = s.domain and m.short_url = s.short_url
+where s.username = @username
+    and s.domain = @domain
+    and s.short_url > @after_short_url
+    and (not @broken_only::boolean or c.broken)
+order by s.short_url
+limit @page_size;
 
 -- name: GetShortUrlLength :one
 select length from short_url_length for update;
@@ -22,9 +127,12 @@ returning length;
 with visits_data as (
     select jsonb_array_elements(@json_visits::jsonb) as v
 )
-insert into url_visits (short_url, visitor_ip, visited_at) 
+insert into url_visits (domain, short_url, visitor_ip, visited_at, variant, source) 
 select 
+    v ->> 'domain',
     v ->> 'shortUrl',
     v ->> 'visitorIp',
-    (v ->> 'visitedAt')::timestamp
+    (v ->> 'visitedAt')::timestamp,
+    coalesce(v ->> 'variant', ''),
+    coalesce(v ->> 'source', '')
 from visits_data;
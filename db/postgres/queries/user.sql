@@ -1,6 +1,6 @@
 -- name: InsertUser :execrows
-insert into users (username, hashed_password)
-values ($1, $2)
+insert into users (username, hashed_password, role)
+values ($1, $2, $3)
 on conflict (username) do nothing;
 
 -- name: GetUserByUsername :one
@@ -11,3 +11,15 @@ delete from users where username = $1;
 
 -- name: CheckUsername :one
 select exists (select 1 from users where username = $1 for update);
+
+-- name: GetUserRole :one
+select role from users where username = $1;
+
+-- name: SetUserRole :execrows
+update users set role = $2 where username = $1;
+
+-- name: GetUsers :many
+select username, role, created_at from users
+where username > @after_username
+order by username
+limit @page_size;
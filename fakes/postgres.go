@@ -0,0 +1,149 @@
+package fakes
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"regexp"
+	"sync"
+)
+
+// Postgres runs the sqlc queries of repository/postgres from memory. Queries are told apart by
+// their "-- name:" comments, each one is answered by a handler that does what its sql does to
+// the tables the routes use, other queries fail. Transactions aren't isolated, a rollback restores
+// the tables as they were when the transaction began.
+type Postgres struct {
+	mu     sync.Mutex
+	tables *tables
+}
+
+// NewPostgres returns a db with empty tables.
+func NewPostgres() *sql.DB {
+	return sql.OpenDB(&Postgres{tables: newTables()})
+}
+
+func (me *Postgres) Connect(context.Context) (driver.Conn, error) {
+	return &postgresConn{db: me}, nil
+}
+
+func (me *Postgres) Driver() driver.Driver {
+	return postgresDriver{}
+}
+
+type postgresDriver struct{}
+
+func (postgresDriver) Open(string) (driver.Conn, error) {
+	return nil, fmt.Errorf("fake postgres is opened with sql.OpenDB")
+}
+
+var queryNameRegexp = regexp.MustCompile(`^-- name: (\w+) :`)
+
+// run answers a query by the handler of its name.
+func (me *Postgres) run(query string, args []driver.NamedValue) (*queryResult, error) {
+	match := queryNameRegexp.FindStringSubmatch(query)
+	if match == nil {
+		return nil, fmt.Errorf("fake postgres: query without a name: %s", query)
+	}
+	handler, ok := queryHandlers[match[1]]
+	if !ok {
+		return nil, fmt.Errorf("fake postgres: unsupported query %s", match[1])
+	}
+
+	values := make([]any, len(args))
+	for i, it := range args {
+		values[i] = it.Value
+	}
+
+	me.mu.Lock()
+	defer me.mu.Unlock()
+	return handler(me.tables, values)
+}
+
+type postgresConn struct {
+	db *Postgres
+}
+
+func (me *postgresConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("fake postgres doesn't prepare statements")
+}
+
+func (me *postgresConn) Close() error {
+	return nil
+}
+
+func (me *postgresConn) Begin() (driver.Tx, error) {
+	return me.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (me *postgresConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	me.db.mu.Lock()
+	defer me.db.mu.Unlock()
+	return &postgresTx{db: me.db, snapshot: me.db.tables.clone()}, nil
+}
+
+func (me *postgresConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	result, err := me.db.run(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &postgresRows{rows: result.rows}, nil
+}
+
+func (me *postgresConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	result, err := me.db.run(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(result.affected), nil
+}
+
+type postgresTx struct {
+	db       *Postgres
+	snapshot *tables
+}
+
+func (me *postgresTx) Commit() error {
+	return nil
+}
+
+func (me *postgresTx) Rollback() error {
+	me.db.mu.Lock()
+	defer me.db.mu.Unlock()
+	me.db.tables = me.snapshot
+	return nil
+}
+
+type postgresRows struct {
+	rows [][]any
+}
+
+// Columns only has the count of the columns right, sqlc scans them by position.
+func (me *postgresRows) Columns() []string {
+	if len(me.rows) == 0 {
+		return nil
+	}
+	return make([]string, len(me.rows[0]))
+}
+
+func (me *postgresRows) Close() error {
+	return nil
+}
+
+func (me *postgresRows) Next(dest []driver.Value) error {
+	if len(me.rows) == 0 {
+		return io.EOF
+	}
+	for i, it := range me.rows[0] {
+		dest[i] = it
+	}
+	me.rows = me.rows[1:]
+	return nil
+}
+
+// queryResult is what a query returns, rows for queries and the count of affected rows for execs.
+type queryResult struct {
+	rows     [][]any
+	affected int64
+}
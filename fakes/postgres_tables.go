@@ -0,0 +1,400 @@
+package fakes
+
+import (
+	"database/sql"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/assaidy/url_shortener/repository/postgres"
+	"github.com/lib/pq"
+)
+
+// tables has the rows of the tables the handlers of the queries read and write.
+type tables struct {
+	users            []postgres_repo.User
+	shortUrls        []postgres_repo.ShortUrl
+	domains          []postgres_repo.Domain
+	shortUrlLength   int32
+	importJobs       []postgres_repo.ImportJob
+	importJobBatches []postgres_repo.ImportJobBatch
+	lastImportJobId  int64
+}
+
+func newTables() *tables {
+	return &tables{shortUrlLength: 6}
+}
+
+func (me *tables) clone() *tables {
+	clone := *me
+	clone.users = slices.Clone(me.users)
+	clone.shortUrls = slices.Clone(me.shortUrls)
+	clone.domains = slices.Clone(me.domains)
+	clone.importJobs = slices.Clone(me.importJobs)
+	clone.importJobBatches = slices.Clone(me.importJobBatches)
+	return &clone
+}
+
+// deleteShortUrls drops the short urls del returns true for.
+func (me *tables) deleteShortUrls(del func(postgres_repo.ShortUrl) bool) int64 {
+	count := len(me.shortUrls)
+	me.shortUrls = slices.DeleteFunc(me.shortUrls, del)
+	return int64(count - len(me.shortUrls))
+}
+
+// shortUrl returns the index of a short url.
+func (me *tables) shortUrl(domain any, shortUrl any) (int, bool) {
+	i := slices.IndexFunc(me.shortUrls, func(it postgres_repo.ShortUrl) bool { return it.Domain == domain && it.ShortUrl == shortUrl })
+	return i, i != -1
+}
+
+// shortUrlsPage returns a page of the short urls of username on domain ordered by short url.
+func (me *tables) shortUrlsPage(username any, domain any, after any, pageSize any) []postgres_repo.ShortUrl {
+	shortUrls := []postgres_repo.ShortUrl{}
+	for _, it := range me.shortUrls {
+		if it.Username == username && it.Domain == domain && it.ShortUrl > after.(string) {
+			shortUrls = append(shortUrls, it)
+		}
+	}
+	slices.SortFunc(shortUrls, func(a, b postgres_repo.ShortUrl) int { return strings.Compare(a.ShortUrl, b.ShortUrl) })
+	return shortUrls[:min(len(shortUrls), int(pageSize.(int64)))]
+}
+
+type queryHandler func(tables *tables, args []any) (*queryResult, error)
+
+// queryHandlers has a handler for each query the routes the tests call run, by query name.
+var queryHandlers = map[string]queryHandler{
+	"InsertUser": func(tables *tables, args []any) (*queryResult, error) {
+		username := args[0].(string)
+		if slices.ContainsFunc(tables.users, func(it postgres_repo.User) bool { return it.Username == username }) {
+			return &queryResult{}, nil
+		}
+		tables.users = append(tables.users, postgres_repo.User{
+			Username:       username,
+			HashedPassword: args[1].(string),
+			CreatedAt:      time.Now(),
+			Role:           args[2].(string),
+		})
+		return &queryResult{affected: 1}, nil
+	},
+	"GetUserByUsername": func(tables *tables, args []any) (*queryResult, error) {
+		result := &queryResult{}
+		for _, it := range tables.users {
+			if it.Username == args[0] {
+				result.rows = append(result.rows, []any{it.Username, it.HashedPassword, it.CreatedAt, it.Role})
+			}
+		}
+		return result, nil
+	},
+	"GetUserRole": func(tables *tables, args []any) (*queryResult, error) {
+		result := &queryResult{}
+		for _, it := range tables.users {
+			if it.Username == args[0] {
+				result.rows = append(result.rows, []any{it.Role})
+			}
+		}
+		return result, nil
+	},
+	"DeleteUserByUsername": func(tables *tables, args []any) (*queryResult, error) {
+		count := len(tables.users)
+		tables.users = slices.DeleteFunc(tables.users, func(it postgres_repo.User) bool { return it.Username == args[0] })
+		tables.deleteShortUrls(func(it postgres_repo.ShortUrl) bool { return it.Username == args[0] })
+		tables.domains = slices.DeleteFunc(tables.domains, func(it postgres_repo.Domain) bool { return it.Username == args[0] })
+		tables.importJobs = slices.DeleteFunc(tables.importJobs, func(it postgres_repo.ImportJob) bool { return it.Username == args[0] })
+		return &queryResult{affected: int64(count - len(tables.users))}, nil
+	},
+	"CheckUsername": func(tables *tables, args []any) (*queryResult, error) {
+		exists := slices.ContainsFunc(tables.users, func(it postgres_repo.User) bool { return it.Username == args[0] })
+		return &queryResult{rows: [][]any{{exists}}}, nil
+	},
+
+	"GetShortUrlLength": func(tables *tables, args []any) (*queryResult, error) {
+		return &queryResult{rows: [][]any{{int64(tables.shortUrlLength)}}}, nil
+	},
+	"IncrementShortUrlLength": func(tables *tables, args []any) (*queryResult, error) {
+		tables.shortUrlLength++
+		return &queryResult{rows: [][]any{{int64(tables.shortUrlLength)}}}, nil
+	},
+	"CheckShortUrl": func(tables *tables, args []any) (*queryResult, error) {
+		_, exists := tables.shortUrl(args[0], args[1])
+		return &queryResult{rows: [][]any{{exists}}}, nil
+	},
+	"GetExistingShortUrls": func(tables *tables, args []any) (*queryResult, error) {
+		result := &queryResult{}
+		var shortUrls pq.StringArray
+		if err := shortUrls.Scan(args[1]); err != nil {
+			return nil, err
+		}
+		for _, it := range shortUrls {
+			if _, ok := tables.shortUrl(args[0], it); ok {
+				result.rows = append(result.rows, []any{it})
+			}
+		}
+		return result, nil
+	},
+	"InsertShortUrl": func(tables *tables, args []any) (*queryResult, error) {
+		if _, ok := tables.shortUrl(args[1], args[3]); ok {
+			return nil, &pq.Error{Code: "23505", Message: "duplicate key value violates unique constraint"}
+		}
+		tables.shortUrls = append(tables.shortUrls, postgres_repo.ShortUrl{
+			Username:        args[0].(string),
+			Domain:          args[1].(string),
+			LongUrl:         args[2].(string),
+			ShortUrl:        args[3].(string),
+			CreatedAt:       time.Now(),
+			RedirectStatus:  int32(args[4].(int64)),
+			CachePolicy:     args[5].(string),
+			ForwardPath:     args[6].(bool),
+			QueryForwarding: args[7].(string),
+			CampaignID:      nullInt64(args[8]),
+			Interstitial:    args[9].(bool),
+		})
+		return &queryResult{affected: 1}, nil
+	},
+	"InsertShortUrls": func(tables *tables, args []any) (*queryResult, error) {
+		var items []struct {
+			Username        string `json:"username"`
+			Domain          string `json:"domain"`
+			LongUrl         string `json:"longUrl"`
+			ShortUrl        string `json:"shortUrl"`
+			RedirectStatus  int32  `json:"redirectStatus"`
+			CachePolicy     string `json:"cachePolicy"`
+			ForwardPath     bool   `json:"forwardPath"`
+			QueryForwarding string `json:"queryForwarding"`
+			CampaignID      *int64 `json:"campaignId"`
+			Interstitial    bool   `json:"interstitial"`
+		}
+		if err := json.Unmarshal(args[0].([]byte), &items); err != nil {
+			return nil, err
+		}
+		result := &queryResult{}
+		for _, it := range items {
+			if _, ok := tables.shortUrl(it.Domain, it.ShortUrl); ok {
+				continue
+			}
+			shortUrl := postgres_repo.ShortUrl{
+				Username:        it.Username,
+				Domain:          it.Domain,
+				LongUrl:         it.LongUrl,
+				ShortUrl:        it.ShortUrl,
+				CreatedAt:       time.Now(),
+				RedirectStatus:  it.RedirectStatus,
+				CachePolicy:     it.CachePolicy,
+				ForwardPath:     it.ForwardPath,
+				QueryForwarding: it.QueryForwarding,
+				Interstitial:    it.Interstitial,
+			}
+			if it.CampaignID != nil {
+				shortUrl.CampaignID = sql.NullInt64{Int64: *it.CampaignID, Valid: true}
+			}
+			tables.shortUrls = append(tables.shortUrls, shortUrl)
+			result.rows = append(result.rows, []any{it.ShortUrl})
+		}
+		return result, nil
+	},
+	"GetShortUrlForUpdate": func(tables *tables, args []any) (*queryResult, error) {
+		result := &queryResult{}
+		if i, ok := tables.shortUrl(args[0], args[1]); ok {
+			it := tables.shortUrls[i]
+			result.rows = append(result.rows, []any{
+				it.Username, it.LongUrl, it.ShortUrl, it.CreatedAt, int64(it.RedirectStatus), it.CachePolicy,
+				it.ForwardPath, it.QueryForwarding, nullInt64Value(it.CampaignID), it.Interstitial,
+				nullTimeValue(it.DisabledAt), it.DisabledReason, it.Domain,
+			})
+		}
+		return result, nil
+	},
+	"GetShortUrlOwner": func(tables *tables, args []any) (*queryResult, error) {
+		result := &queryResult{}
+		if i, ok := tables.shortUrl(args[0], args[1]); ok {
+			result.rows = append(result.rows, []any{tables.shortUrls[i].Username})
+		}
+		return result, nil
+	},
+	"UpdateShortUrl": func(tables *tables, args []any) (*queryResult, error) {
+		if i, ok := tables.shortUrl(args[0], args[1]); ok {
+			tables.shortUrls[i].LongUrl = args[2].(string)
+			tables.shortUrls[i].RedirectStatus = int32(args[3].(int64))
+			tables.shortUrls[i].CachePolicy = args[4].(string)
+			tables.shortUrls[i].ForwardPath = args[5].(bool)
+			tables.shortUrls[i].QueryForwarding = args[6].(string)
+			tables.shortUrls[i].Interstitial = args[7].(bool)
+		}
+		return &queryResult{}, nil
+	},
+	"GetShortUrlsByUsername": func(tables *tables, args []any) (*queryResult, error) {
+		result := &queryResult{}
+		for _, it := range tables.shortUrlsPage(args[1], args[2], args[3], args[4]) {
+			result.rows = append(result.rows, []any{it.ShortUrl, it.LongUrl, it.CreatedAt, int64(0)})
+		}
+		return result, nil
+	},
+	"GetShortUrlsForListing": func(tables *tables, args []any) (*queryResult, error) {
+		result := &queryResult{}
+		for _, it := range tables.shortUrlsPage(args[0], args[1], args[2], args[4]) {
+			result.rows = append(result.rows, []any{
+				it.ShortUrl, it.LongUrl, it.CreatedAt, it.DisabledAt.Valid, "", "", "", int64(0), "", "", false, nil,
+			})
+		}
+		return result, nil
+	},
+
+	"GetVerifiedDomainOwner": func(tables *tables, args []any) (*queryResult, error) {
+		result := &queryResult{}
+		for _, it := range tables.domains {
+			if it.Domain == args[0] && it.VerifiedAt.Valid {
+				result.rows = append(result.rows, []any{it.Username})
+			}
+		}
+		return result, nil
+	},
+	"GetVerifiedDomains": func(tables *tables, args []any) (*queryResult, error) {
+		result := &queryResult{}
+		for _, it := range tables.domains {
+			if it.VerifiedAt.Valid {
+				result.rows = append(result.rows, []any{it.Domain})
+			}
+		}
+		return result, nil
+	},
+
+	// link checks, metadata and visits aren't stored, the link checker and the metadata fetcher are
+	// off and visits are only stored when the url service stops
+	"DeleteLinkCheck":      noRows,
+	"ClearFetchedMetadata": noRows,
+	"GetShortUrlStats": func(tables *tables, args []any) (*queryResult, error) {
+		return &queryResult{rows: [][]any{{int64(0), int64(0)}}}, nil
+	},
+	"GetShortUrlDailyVisits":   noRows,
+	"GetShortUrlVariantVisits": noRows,
+	"GetShortUrlSourceVisits":  noRows,
+
+	"InsertImportJob": func(tables *tables, args []any) (*queryResult, error) {
+		tables.lastImportJobId++
+		tables.importJobs = append(tables.importJobs, postgres_repo.ImportJob{
+			ID:         tables.lastImportJobId,
+			Username:   args[0].(string),
+			Domain:     args[1].(string),
+			OnConflict: args[2].(string),
+			Items:      json.RawMessage(args[3].([]byte)),
+			Total:      int32(args[4].(int64)),
+			Status:     "pending",
+			Report:     json.RawMessage("[]"),
+			CreatedAt:  time.Now(),
+		})
+		return &queryResult{rows: [][]any{{tables.lastImportJobId}}}, nil
+	},
+	"GetClaimableImportJobIds": func(tables *tables, args []any) (*queryResult, error) {
+		result := &queryResult{}
+		for _, it := range tables.importJobs {
+			if importJobClaimable(it, args[0]) {
+				result.rows = append(result.rows, []any{it.ID})
+			}
+		}
+		return result, nil
+	},
+	"ClaimImportJob": func(tables *tables, args []any) (*queryResult, error) {
+		result := &queryResult{}
+		for i, it := range tables.importJobs {
+			if it.ID == args[1] && importJobClaimable(it, args[2]) {
+				tables.importJobs[i].Status = "running"
+				tables.importJobs[i].ClaimedUntil = nullTime(args[0])
+				result.rows = append(result.rows, []any{it.Username, it.Domain, []byte(it.Items), it.OnConflict})
+			}
+		}
+		return result, nil
+	},
+	"ExtendImportJobClaim": func(tables *tables, args []any) (*queryResult, error) {
+		for i, it := range tables.importJobs {
+			if it.ID == args[1] && it.Status == "running" {
+				tables.importJobs[i].ClaimedUntil = nullTime(args[0])
+			}
+		}
+		return &queryResult{}, nil
+	},
+	"InsertImportJobBatch": func(tables *tables, args []any) (*queryResult, error) {
+		tables.importJobBatches = append(tables.importJobBatches, postgres_repo.ImportJobBatch{
+			JobID:      args[0].(int64),
+			StartIndex: int32(args[1].(int64)),
+			Report:     json.RawMessage(args[2].([]byte)),
+		})
+		return &queryResult{}, nil
+	},
+	"GetImportJobBatches": func(tables *tables, args []any) (*queryResult, error) {
+		result := &queryResult{}
+		for _, it := range tables.importJobBatches {
+			if it.JobID == args[0] {
+				result.rows = append(result.rows, []any{int64(it.StartIndex), []byte(it.Report)})
+			}
+		}
+		slices.SortFunc(result.rows, func(a, b []any) int { return int(a[0].(int64) - b[0].(int64)) })
+		return result, nil
+	},
+	"FinishImportJob": func(tables *tables, args []any) (*queryResult, error) {
+		tables.importJobBatches = slices.DeleteFunc(tables.importJobBatches, func(it postgres_repo.ImportJobBatch) bool { return it.JobID == args[0] })
+		for i, it := range tables.importJobs {
+			if it.ID == args[0] {
+				tables.importJobs[i].Status = args[1].(string)
+				tables.importJobs[i].Report = json.RawMessage(args[2].([]byte))
+				tables.importJobs[i].Created = int32(args[3].(int64))
+				tables.importJobs[i].Skipped = int32(args[4].(int64))
+				tables.importJobs[i].Failed = int32(args[5].(int64))
+				tables.importJobs[i].Error = args[6].(string)
+				tables.importJobs[i].Items = json.RawMessage("[]")
+				tables.importJobs[i].ClaimedUntil = sql.NullTime{}
+				tables.importJobs[i].FinishedAt = sql.NullTime{Time: time.Now(), Valid: true}
+			}
+		}
+		return &queryResult{}, nil
+	},
+	"GetImportJob": func(tables *tables, args []any) (*queryResult, error) {
+		result := &queryResult{}
+		for _, it := range tables.importJobs {
+			if it.ID == args[0] && it.Username == args[1] {
+				result.rows = append(result.rows, []any{
+					it.ID, it.Username, it.Domain, it.Status, it.OnConflict, int64(it.Total), int64(it.Created),
+					int64(it.Skipped), int64(it.Failed), it.Error, it.CreatedAt, nullTimeValue(it.FinishedAt),
+				})
+			}
+		}
+		return result, nil
+	},
+}
+
+func noRows(*tables, []any) (*queryResult, error) {
+	return &queryResult{}, nil
+}
+
+func importJobClaimable(job postgres_repo.ImportJob, now any) bool {
+	return job.Status == "pending" ||
+		(job.Status == "running" && (!job.ClaimedUntil.Valid || !job.ClaimedUntil.Time.After(now.(time.Time))))
+}
+
+func nullTime(value any) sql.NullTime {
+	if value == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: value.(time.Time), Valid: true}
+}
+
+func nullInt64(value any) sql.NullInt64 {
+	if value == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: value.(int64), Valid: true}
+}
+
+func nullInt64Value(value sql.NullInt64) any {
+	if !value.Valid {
+		return nil
+	}
+	return value.Int64
+}
+
+func nullTimeValue(value sql.NullTime) any {
+	if !value.Valid {
+		return nil
+	}
+	return value.Time
+}
@@ -0,0 +1,218 @@
+// Package fakes has in memory stand-ins of the db and cache for tests of the services and the
+// routes that run without them.
+package fakes
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Valkey serves the few commands the services and the valkey client use from memory. Keys expire
+// by a clock that only moves with Advance.
+type Valkey struct {
+	Addr     string
+	listener net.Listener
+
+	mu        sync.Mutex
+	now       time.Time
+	values    map[string]string
+	sets      map[string]map[string]bool
+	expiresAt map[string]time.Time
+}
+
+func (me *Valkey) Advance(d time.Duration) {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+	me.now = me.now.Add(d)
+	for key, it := range me.expiresAt {
+		if !me.now.Before(it) {
+			me.delete(key)
+		}
+	}
+}
+
+func (me *Valkey) delete(key string) bool {
+	_, isValue := me.values[key]
+	_, isSet := me.sets[key]
+	delete(me.values, key)
+	delete(me.sets, key)
+	delete(me.expiresAt, key)
+	return isValue || isSet
+}
+
+func (me *Valkey) handle(args []string) string {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+	values := me.values
+	switch strings.ToUpper(args[0]) {
+	case "HELLO":
+		return "%2\r\n$5\r\nproto\r\n:3\r\n$7\r\nversion\r\n$5\r\n8.0.0\r\n"
+	case "CLIENT":
+		return "+OK\r\n"
+	case "PING":
+		return "+PONG\r\n"
+	case "GET":
+		value, ok := values[args[1]]
+		if !ok {
+			return "_\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+	case "SET":
+		nx := false
+		ttl := time.Duration(0)
+		for i := 3; i < len(args); i++ {
+			switch strings.ToUpper(args[i]) {
+			case "NX":
+				nx = true
+			case "EX":
+				seconds, _ := strconv.Atoi(args[i+1])
+				ttl = time.Duration(seconds) * time.Second
+				i++
+			case "PX":
+				milliseconds, _ := strconv.Atoi(args[i+1])
+				ttl = time.Duration(milliseconds) * time.Millisecond
+				i++
+			}
+		}
+		if _, ok := values[args[1]]; ok && nx {
+			return "_\r\n"
+		}
+		values[args[1]] = args[2]
+		delete(me.expiresAt, args[1])
+		if ttl > 0 {
+			me.expiresAt[args[1]] = me.now.Add(ttl)
+		}
+		return "+OK\r\n"
+	case "DEL":
+		if me.delete(args[1]) {
+			return ":1\r\n"
+		}
+		return ":0\r\n"
+	case "PEXPIRE":
+		if _, ok := values[args[1]]; !ok && me.sets[args[1]] == nil {
+			return ":0\r\n"
+		}
+		milliseconds, _ := strconv.Atoi(args[2])
+		me.expiresAt[args[1]] = me.now.Add(time.Duration(milliseconds) * time.Millisecond)
+		return ":1\r\n"
+	case "SADD":
+		set := me.sets[args[1]]
+		if set == nil {
+			set = map[string]bool{}
+			me.sets[args[1]] = set
+		}
+		added := 0
+		for _, it := range args[2:] {
+			if !set[it] {
+				set[it] = true
+				added++
+			}
+		}
+		return fmt.Sprintf(":%d\r\n", added)
+	case "SMISMEMBER":
+		reply := fmt.Sprintf("*%d\r\n", len(args)-2)
+		for _, it := range args[2:] {
+			if me.sets[args[1]][it] {
+				reply += ":1\r\n"
+			} else {
+				reply += ":0\r\n"
+			}
+		}
+		return reply
+	}
+	return fmt.Sprintf("-ERR unknown command '%s'\r\n", args[0])
+}
+
+// NewValkey serves a Valkey on a random port until Close.
+func NewValkey() (*Valkey, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	me := &Valkey{
+		Addr:      listener.Addr().String(),
+		listener:  listener,
+		now:       time.Now(),
+		values:    map[string]string{},
+		sets:      map[string]map[string]bool{},
+		expiresAt: map[string]time.Time{},
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					args, err := readRespCommand(reader)
+					if err != nil {
+						return
+					}
+					if _, err := io.WriteString(conn, me.handle(args)); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+
+	return me, nil
+}
+
+// StartValkey serves a Valkey until the test ends.
+func StartValkey(t *testing.T) *Valkey {
+	me, err := NewValkey()
+	require.NoError(t, err)
+	t.Cleanup(me.Close)
+	return me
+}
+
+func (me *Valkey) Close() {
+	me.listener.Close()
+}
+
+// readRespCommand reads a command sent as an array of bulk strings.
+func readRespCommand(reader *bufio.Reader) ([]string, error) {
+	readLine := func(prefix byte) (int, error) {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return 0, err
+		}
+		if line[0] != prefix {
+			return 0, fmt.Errorf("unexpected line %q", line)
+		}
+		return strconv.Atoi(strings.TrimSpace(line[1:]))
+	}
+
+	count, err := readLine('*')
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, count)
+	for i := range args {
+		size, err := readLine('$')
+		if err != nil {
+			return nil, err
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		args[i] = string(data[:size])
+	}
+	return args, nil
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/assaidy/url_shortener/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// statsRangeFromQuery reads ?from and ?to as RFC 3339 timestamps or YYYY-MM-DD dates.
+func statsRangeFromQuery(c *fiber.Ctx) (services.StatsRange, error) {
+	var statsRange services.StatsRange
+	for _, it := range []struct {
+		name string
+		dst  *time.Time
+	}{
+		{"from", &statsRange.From},
+		{"to", &statsRange.To},
+	} {
+		value := c.Query(it.name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			if t, err = time.Parse(time.DateOnly, value); err != nil {
+				return services.StatsRange{}, fiber.NewError(fiber.StatusBadRequest, "invalid "+it.name+" time")
+			}
+		}
+		*it.dst = t
+	}
+	return statsRange, nil
+}
+
+// HandleGetShortUrlsStats reports visits of all short urls of the user on ?domain, or only
+// those of ?campaign.
+func HandleGetShortUrlsStats(c *fiber.Ctx) error {
+	username := c.Locals(AuthedUsername).(string)
+	statsRange, err := statsRangeFromQuery(c)
+	if err != nil {
+		return err
+	}
+
+	stats, err := services.AnalyticsServiceInstance.GetShortUrlsStats(context.Background(), services.GetShortUrlsStatsParams{
+		Username: username,
+		Domain:   queryDomain(c),
+		Campaign: c.Query("campaign"),
+		Range:    statsRange,
+	})
+	if err != nil {
+		return fromServiceError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(stats)
+}
+
+func HandleGetShortUrlStats(c *fiber.Ctx) error {
+	username := c.Locals(AuthedUsername).(string)
+	statsRange, err := statsRangeFromQuery(c)
+	if err != nil {
+		return err
+	}
+
+	stats, err := services.AnalyticsServiceInstance.GetShortUrlStats(context.Background(), username, queryDomain(c), c.Params("short_url"), statsRange)
+	if err != nil {
+		return fromServiceError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(stats)
+}
+
+func HandleGetCampaignsStats(c *fiber.Ctx) error {
+	username := c.Locals(AuthedUsername).(string)
+	statsRange, err := statsRangeFromQuery(c)
+	if err != nil {
+		return err
+	}
+
+	stats, err := services.AnalyticsServiceInstance.GetCampaignsStats(context.Background(), username, statsRange)
+	if err != nil {
+		return fromServiceError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(stats)
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/assaidy/url_shortener/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type CreateUtmTemplateRequest struct {
+	Name        string `json:"name"`
+	UtmSource   string `json:"utmSource"`
+	UtmMedium   string `json:"utmMedium"`
+	UtmCampaign string `json:"utmCampaign"`
+	UtmTerm     string `json:"utmTerm"`
+	UtmContent  string `json:"utmContent"`
+}
+
+func HandleCreateUtmTemplate(c *fiber.Ctx) error {
+	var req CreateUtmTemplateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json body")
+	}
+
+	username := c.Locals(AuthedUsername).(string)
+
+	if err := services.CampaignServiceInstance.CreateUtmTemplate(context.Background(), services.CreateUtmTemplateParams{
+		Username:    username,
+		Name:        req.Name,
+		UtmSource:   req.UtmSource,
+		UtmMedium:   req.UtmMedium,
+		UtmCampaign: req.UtmCampaign,
+		UtmTerm:     req.UtmTerm,
+		UtmContent:  req.UtmContent,
+	}); err != nil {
+		return fromServiceError(err)
+	}
+
+	return c.SendStatus(fiber.StatusCreated)
+}
+
+func HandleGetUtmTemplates(c *fiber.Ctx) error {
+	username := c.Locals(AuthedUsername).(string)
+
+	templates, err := services.CampaignServiceInstance.GetUtmTemplates(context.Background(), username)
+	if err != nil {
+		return fromServiceError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(templates)
+}
+
+func HandleDeleteUtmTemplate(c *fiber.Ctx) error {
+	username := c.Locals(AuthedUsername).(string)
+
+	if err := services.CampaignServiceInstance.DeleteUtmTemplate(context.Background(), username, c.Params("name")); err != nil {
+		return fromServiceError(err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+type CreateCampaignRequest struct {
+	Name        string `json:"name"`
+	UtmTemplate string `json:"utmTemplate"`
+}
+
+func HandleCreateCampaign(c *fiber.Ctx) error {
+	var req CreateCampaignRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json body")
+	}
+
+	username := c.Locals(AuthedUsername).(string)
+
+	if err := services.CampaignServiceInstance.CreateCampaign(context.Background(), services.CreateCampaignParams{
+		Username:    username,
+		Name:        req.Name,
+		UtmTemplate: req.UtmTemplate,
+	}); err != nil {
+		return fromServiceError(err)
+	}
+
+	return c.SendStatus(fiber.StatusCreated)
+}
+
+func HandleGetCampaigns(c *fiber.Ctx) error {
+	username := c.Locals(AuthedUsername).(string)
+
+	campaigns, err := services.CampaignServiceInstance.GetCampaigns(context.Background(), username)
+	if err != nil {
+		return fromServiceError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(campaigns)
+}
+
+func HandleDeleteCampaign(c *fiber.Ctx) error {
+	username := c.Locals(AuthedUsername).(string)
+
+	if err := services.CampaignServiceInstance.DeleteCampaign(context.Background(), username, c.Params("name")); err != nil {
+		return fromServiceError(err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -4,14 +4,17 @@ import (
 	"errors"
 
 	"github.com/assaidy/url_shortener/services"
+	"github.com/assaidy/url_shortener/utils"
 	"github.com/gofiber/fiber/v2"
 )
 
 const (
 	AuthedUsername = "middleware.jwt.AuthedUsername"
+	ApiPrefix      = "/api/v1" // of the management api, short urls are served at the root
 )
 
-func fromServiceError(err error) error {
+// fromServiceError returns the problem of a service error, with the invalid fields of validation errors.
+func fromServiceError(err error) *Problem {
 	if err == nil {
 		panic("function should not be called on nil errors")
 	}
@@ -22,11 +25,21 @@ func fromServiceError(err error) error {
 
 	status := fiber.StatusInternalServerError
 	switch {
-	case is(services.ConflictErr):     status = fiber.StatusConflict
-	case is(services.NotFoundErr):     status = fiber.StatusNotFound
-	case is(services.UnauthorizedErr): status = fiber.StatusUnauthorized
-	case is(services.ValidationErr):   status = fiber.StatusBadRequest
+	case is(services.ConflictErr):      status = fiber.StatusConflict
+	case is(services.NotFoundErr):      status = fiber.StatusNotFound
+	case is(services.UnauthorizedErr):  status = fiber.StatusUnauthorized
+	case is(services.ValidationErr):    status = fiber.StatusBadRequest
+	case is(services.UnprocessableErr): status = fiber.StatusUnprocessableEntity
+	case is(services.ForbiddenErr):     status = fiber.StatusForbidden
 	}
 
-	return fiber.NewError(status, err.Error())
+	problem := NewProblem(status, err.Error())
+	if is(services.ValidationErr) {
+		problem.Code = ProblemValidationFailed
+		var fieldErrs utils.ValidationErrors
+		if errors.As(err, &fieldErrs) {
+			problem.Errors = fieldErrs
+		}
+	}
+	return problem
 }
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/assaidy/url_shortener/services"
+	"github.com/assaidy/url_shortener/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// queryDomain returns the custom domain of the short urls a request manages, from ?domain.
+// It's empty for the main domain.
+func queryDomain(c *fiber.Ctx) string {
+	return utils.NormalizeDomain(c.Query("domain"))
+}
+
+// hostDomain returns the custom domain a visitor request was sent to, from the Host header.
+// It's empty for the main domain.
+func hostDomain(c *fiber.Ctx) (string, error) {
+	return services.DomainServiceInstance.ResolveHost(context.Background(), c.Hostname())
+}
+
+type AddDomainRequest struct {
+	Domain string `json:"domain"`
+}
+
+// HandleAddDomain claims a domain and responds with the TXT record to add to its dns.
+func HandleAddDomain(c *fiber.Ctx) error {
+	var req AddDomainRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json body")
+	}
+
+	domain, err := services.DomainServiceInstance.AddDomain(context.Background(), services.AddDomainParams{
+		Username: c.Locals(AuthedUsername).(string),
+		Domain:   req.Domain,
+	})
+	if err != nil {
+		return fromServiceError(err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(domain)
+}
+
+func HandleGetDomains(c *fiber.Ctx) error {
+	username := c.Locals(AuthedUsername).(string)
+
+	domains, err := services.DomainServiceInstance.GetDomains(context.Background(), username)
+	if err != nil {
+		return fromServiceError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(domains)
+}
+
+// HandleVerifyDomain looks up the TXT record of a domain, it responds with 422 until it's found.
+func HandleVerifyDomain(c *fiber.Ctx) error {
+	username := c.Locals(AuthedUsername).(string)
+
+	domain, err := services.DomainServiceInstance.VerifyDomain(context.Background(), username, c.Params("domain"))
+	if err != nil {
+		return fromServiceError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(domain)
+}
+
+// HandleDeleteDomain also deletes the short urls of the domain.
+func HandleDeleteDomain(c *fiber.Ctx) error {
+	username := c.Locals(AuthedUsername).(string)
+
+	if err := services.DomainServiceInstance.DeleteDomain(context.Background(), username, c.Params("domain")); err != nil {
+		return fromServiceError(err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
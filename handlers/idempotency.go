@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"slices"
+
+	"github.com/assaidy/url_shortener/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+	idempotencyKeyMaxLength  = 255
+)
+
+// WithIdempotencyKey replays the stored response when a request is retried with the same
+// Idempotency-Key header. Only successful responses are stored; on failure the key is freed.
+// It must be mounted after WithJwt since keys are scoped per user. A key is only replayed for the
+// same method, url and body, reusing it for another request fails with 422.
+func WithIdempotencyKey(c *fiber.Ctx) error {
+	key := c.Get(HeaderIdempotencyKey)
+	if key == "" {
+		return c.Next()
+	}
+	if len(key) > idempotencyKeyMaxLength {
+		return fiber.NewError(fiber.StatusBadRequest, "Idempotency-Key header is too long")
+	}
+
+	username := c.Locals(AuthedUsername).(string)
+	requestHash := idempotencyRequestHash(c.Method(), c.OriginalURL(), c.Body())
+
+	stored, err := services.IdempotencyServiceInstance.BeginIdempotentRequest(context.Background(), username, key, requestHash)
+	if err != nil {
+		return fromServiceError(err)
+	}
+	if stored != nil {
+		c.Set(HeaderIdempotentReplayed, "true")
+		c.Set(fiber.HeaderContentType, stored.ContentType)
+		return c.Status(stored.Status).Send(stored.Body)
+	}
+
+	if err := c.Next(); err != nil || c.Response().StatusCode() >= fiber.StatusMultipleChoices {
+		if err := services.IdempotencyServiceInstance.ReleaseIdempotentRequest(context.Background(), username, key); err != nil {
+			slog.Error("error releasing idempotency key", "err", err)
+		}
+		return err
+	}
+
+	if err := services.IdempotencyServiceInstance.CompleteIdempotentRequest(context.Background(), username, key, requestHash, services.IdempotentResponse{
+		Status:      c.Response().StatusCode(),
+		ContentType: string(c.Response().Header.ContentType()),
+		Body:        bytes.Clone(c.Response().Body()),
+	}); err != nil {
+		slog.Error("error storing idempotent response", "err", err)
+	}
+
+	return nil
+}
+
+// idempotencyRequestHash identifies a request by its method, url with the query, and body.
+func idempotencyRequestHash(method string, url string, body []byte) string {
+	sum := sha256.Sum256(slices.Concat([]byte(method+" "+url+"\n"), body))
+	return hex.EncodeToString(sum[:])
+}
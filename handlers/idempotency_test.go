@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/assaidy/url_shortener/cache"
+	"github.com/assaidy/url_shortener/config"
+	"github.com/assaidy/url_shortener/fakes"
+	"github.com/assaidy/url_shortener/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type idempotencyTestApp struct {
+	app     *fiber.App
+	valkey  *fakes.Valkey
+	mu      sync.Mutex
+	calls   int
+	started chan struct{}
+	release chan struct{}
+}
+
+// newIdempotencyTestApp mounts WithIdempotencyKey on handlers that count their calls. The user is
+// taken from the X-User header, /slow waits for release before responding and /fail responds with 400.
+func newIdempotencyTestApp(t *testing.T) *idempotencyTestApp {
+	config.Cfg = config.Default()
+	valkey := fakes.StartValkey(t)
+	config.Cfg.Valkey.Addr = valkey.Addr
+	require.NoError(t, cache.Connect())
+	t.Cleanup(cache.Valkey.Close)
+	require.NoError(t, services.IdempotencyServiceInstance.Start())
+
+	me := &idempotencyTestApp{
+		app:     fiber.New(fiber.Config{ErrorHandler: ErrorHandler}),
+		valkey:  valkey,
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	handler := func(c *fiber.Ctx) error {
+		me.mu.Lock()
+		me.calls++
+		calls := me.calls
+		me.mu.Unlock()
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{"call": calls})
+	}
+
+	me.app.Use(func(c *fiber.Ctx) error {
+		c.Locals(AuthedUsername, c.Get("X-User", "user"))
+		return c.Next()
+	})
+	me.app.Post("/urls", WithIdempotencyKey, handler)
+	me.app.Post("/urls/batch", WithIdempotencyKey, handler)
+	me.app.Post("/slow", WithIdempotencyKey, func(c *fiber.Ctx) error {
+		close(me.started)
+		<-me.release
+		return handler(c)
+	})
+	me.app.Post("/fail", WithIdempotencyKey, func(c *fiber.Ctx) error {
+		handler(c)
+		return fiber.NewError(fiber.StatusBadRequest, "failed")
+	})
+
+	return me
+}
+
+type idempotencyTestResponse struct {
+	status   int
+	body     string
+	replayed bool
+}
+
+func (me *idempotencyTestApp) post(t *testing.T, target string, key string, body string, headers ...string) idempotencyTestResponse {
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	if key != "" {
+		req.Header.Set(HeaderIdempotencyKey, key)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+
+	resp, err := me.app.Test(req, -1)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return idempotencyTestResponse{
+		status:   resp.StatusCode,
+		body:     string(data),
+		replayed: resp.Header.Get(HeaderIdempotentReplayed) == "true",
+	}
+}
+
+func TestIdempotencyKeyReplay(t *testing.T) {
+	app := newIdempotencyTestApp(t)
+
+	first := app.post(t, "/urls", "key", `{"longUrl":"https://example.com"}`)
+	assert.Equal(t, idempotencyTestResponse{status: fiber.StatusCreated, body: `{"call":1}`}, first)
+
+	retry := app.post(t, "/urls", "key", `{"longUrl":"https://example.com"}`)
+	assert.Equal(t, idempotencyTestResponse{status: fiber.StatusCreated, body: `{"call":1}`, replayed: true}, retry)
+
+	// keys are scoped per user
+	other := app.post(t, "/urls", "key", `{"longUrl":"https://example.com"}`, "X-User", "other")
+	assert.Equal(t, idempotencyTestResponse{status: fiber.StatusCreated, body: `{"call":2}`}, other)
+
+	withoutKey := app.post(t, "/urls", "", `{"longUrl":"https://example.com"}`)
+	assert.Equal(t, idempotencyTestResponse{status: fiber.StatusCreated, body: `{"call":3}`}, withoutKey)
+}
+
+func TestIdempotencyKeyReusedForAnotherRequest(t *testing.T) {
+	app := newIdempotencyTestApp(t)
+	body := `{"longUrl":"https://example.com"}`
+
+	assert.Equal(t, fiber.StatusCreated, app.post(t, "/urls", "key", body).status)
+
+	for _, tt := range []struct {
+		name   string
+		target string
+		body   string
+	}{
+		{"another body", "/urls", `{"longUrl":"https://example.org"}`},
+		{"another route", "/urls/batch", body},
+		{"another query", "/urls?domain=sho.rt", body},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := app.post(t, tt.target, "key", tt.body)
+			assert.Equal(t, fiber.StatusUnprocessableEntity, resp.status)
+			assert.False(t, resp.replayed)
+		})
+	}
+
+	assert.Equal(t, 1, app.calls)
+}
+
+func TestIdempotencyKeyInProgress(t *testing.T) {
+	app := newIdempotencyTestApp(t)
+	body := `{"longUrl":"https://example.com"}`
+
+	done := make(chan idempotencyTestResponse)
+	go func() { done <- app.post(t, "/slow", "key", body) }()
+	<-app.started
+
+	assert.Equal(t, fiber.StatusConflict, app.post(t, "/slow", "key", body).status)
+
+	close(app.release)
+	assert.Equal(t, idempotencyTestResponse{status: fiber.StatusCreated, body: `{"call":1}`}, <-done)
+
+	retry := app.post(t, "/slow", "key", body)
+	assert.Equal(t, idempotencyTestResponse{status: fiber.StatusCreated, body: `{"call":1}`, replayed: true}, retry)
+}
+
+func TestIdempotencyKeyReleasedOnFailure(t *testing.T) {
+	app := newIdempotencyTestApp(t)
+	body := `{"longUrl":"https://example.com"}`
+
+	assert.Equal(t, fiber.StatusBadRequest, app.post(t, "/fail", "key", body).status)
+
+	retry := app.post(t, "/fail", "key", body)
+	assert.Equal(t, fiber.StatusBadRequest, retry.status)
+	assert.False(t, retry.replayed)
+	assert.Equal(t, 2, app.calls)
+}
+
+func TestIdempotencyKeyOfUnfinishedRequestExpires(t *testing.T) {
+	app := newIdempotencyTestApp(t)
+	body := `{"longUrl":"https://example.com"}`
+
+	// the process handling the first request died before completing or releasing the key
+	stored, err := services.IdempotencyServiceInstance.BeginIdempotentRequest(context.Background(), "user", "key", idempotencyRequestHash(fiber.MethodPost, "/urls", []byte(body)))
+	require.NoError(t, err)
+	require.Nil(t, stored)
+
+	assert.Equal(t, fiber.StatusConflict, app.post(t, "/urls", "key", body).status)
+
+	app.valkey.Advance(config.Cfg.Urls.IdempotencyLockTTL)
+	retry := app.post(t, "/urls", "key", body)
+	assert.Equal(t, idempotencyTestResponse{status: fiber.StatusCreated, body: `{"call":1}`}, retry)
+
+	// completed responses are kept longer than the reservation
+	app.valkey.Advance(config.Cfg.Urls.IdempotencyLockTTL)
+	replay := app.post(t, "/urls", "key", body)
+	assert.Equal(t, idempotencyTestResponse{status: fiber.StatusCreated, body: `{"call":1}`, replayed: true}, replay)
+
+	app.valkey.Advance(config.Cfg.Urls.IdempotencyKeyTTL)
+	assert.False(t, app.post(t, "/urls", "key", body).replayed)
+}
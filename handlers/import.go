@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/assaidy/url_shortener/config"
+	"github.com/assaidy/url_shortener/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+const MIMEApplicationNdjson = "application/x-ndjson"
+
+// importFormOverhead is allowed on top of config.Cfg.Import.MaxFileSize for the rest of multipart forms.
+const importFormOverhead = 64 * 1024
+
+// ImportRequestConfig is the HeaderReceived hook of the server, which runs before bodies are read.
+// It raises the body limit of imports to fit config.Cfg.Import.MaxFileSize, the other routes keep
+// the default limit of the app.
+func ImportRequestConfig(header *fasthttp.RequestHeader) fasthttp.RequestConfig {
+	if string(header.Method()) != fiber.MethodPost {
+		return fasthttp.RequestConfig{}
+	}
+
+	path, _, _ := strings.Cut(string(header.RequestURI()), "?")
+	path = strings.TrimSuffix(path, "/")
+	if strings.EqualFold(path, ApiPrefix+"/urls/import") || (config.Cfg.Server.LegacyRoutes && strings.EqualFold(path, "/urls/import")) {
+		return fasthttp.RequestConfig{MaxRequestBodySize: config.Cfg.Import.MaxFileSize + importFormOverhead}
+	}
+	return fasthttp.RequestConfig{}
+}
+
+// HandleExportShortUrls streams all short urls of the user on ?domain as csv (default) or ndjson.
+// Visit counts are included with ?visits=true.
+func HandleExportShortUrls(c *fiber.Ctx) error {
+	username := c.Locals(AuthedUsername).(string)
+	format := c.Query("format", services.ImportFormatCsv)
+	withVisits := c.QueryBool("visits", false)
+	domain := queryDomain(c)
+
+	if format != services.ImportFormatCsv && format != services.ImportFormatNdjson {
+		return fiber.NewError(fiber.StatusBadRequest, "format must be csv or ndjson")
+	}
+	c.Attachment("short_urls." + format)
+	if format == services.ImportFormatCsv {
+		c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	} else {
+		c.Set(fiber.HeaderContentType, MIMEApplicationNdjson)
+	}
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		// the status is already sent, so errors can only cut the stream short
+		if err := services.UrlServiceInstance.WriteExportedShortUrls(context.Background(), w, username, domain, format, withVisits); err != nil {
+			slog.Error("error exporting short urls", "username", username, "err", err)
+		}
+	})
+
+	return nil
+}
+
+// HandleImportShortUrls accepts a csv or ndjson file either as the "file" field of a multipart form
+// or as the raw body. The format is taken from ?format, or else from the file name or content type.
+// The short urls are created on ?domain.
+// It responds with 201 when the import is done, and with 202 when it runs in the background.
+func HandleImportShortUrls(c *fiber.Ctx) error {
+	username := c.Locals(AuthedUsername).(string)
+	format := c.Query("format")
+
+	var data []byte
+	if fileHeader, err := c.FormFile("file"); err == nil {
+		if fileHeader.Size > int64(config.Cfg.Import.MaxFileSize) {
+			return fiber.NewError(fiber.StatusRequestEntityTooLarge, fmt.Sprintf("file is larger than %d bytes", config.Cfg.Import.MaxFileSize))
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			return fmt.Errorf("error opening uploaded file: %w", err)
+		}
+		defer file.Close()
+
+		if data, err = io.ReadAll(file); err != nil {
+			return fmt.Errorf("error reading uploaded file: %w", err)
+		}
+		if format == "" {
+			format = importFormatFromFilename(fileHeader.Filename)
+		}
+	} else {
+		data = c.Body()
+		if len(data) > config.Cfg.Import.MaxFileSize {
+			return fiber.NewError(fiber.StatusRequestEntityTooLarge, fmt.Sprintf("file is larger than %d bytes", config.Cfg.Import.MaxFileSize))
+		}
+		if format == "" {
+			format = importFormatFromContentType(c.Get(fiber.HeaderContentType))
+		}
+	}
+
+	job, err := services.ImportServiceInstance.CreateImportJob(context.Background(), services.CreateImportJobParams{
+		Username:   username,
+		Domain:     queryDomain(c),
+		Format:     format,
+		OnConflict: c.Query("onConflict", services.ImportOnConflictSkip),
+		Data:       data,
+	})
+	if err != nil {
+		return fromServiceError(err)
+	}
+
+	status := fiber.StatusCreated
+	if job.FinishedAt == nil {
+		status = fiber.StatusAccepted
+	}
+	c.Location(fmt.Sprintf("%s/urls/import/%d", ApiPrefix, job.ID))
+
+	return c.Status(status).JSON(job)
+}
+
+func importFormatFromFilename(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return services.ImportFormatCsv
+	case ".ndjson", ".jsonl":
+		return services.ImportFormatNdjson
+	}
+	return ""
+}
+
+func importFormatFromContentType(contentType string) string {
+	switch {
+	case strings.HasPrefix(contentType, "text/csv"):
+		return services.ImportFormatCsv
+	case strings.HasPrefix(contentType, MIMEApplicationNdjson), strings.HasPrefix(contentType, "application/jsonl"):
+		return services.ImportFormatNdjson
+	}
+	return ""
+}
+
+func HandleGetImportJob(c *fiber.Ctx) error {
+	username := c.Locals(AuthedUsername).(string)
+	jobId, err := strconv.ParseInt(c.Params("job_id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid job id")
+	}
+
+	job, err := services.ImportServiceInstance.GetImportJob(context.Background(), username, jobId)
+	if err != nil {
+		return fromServiceError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(job)
+}
+
+// HandleGetImportJobReport sends the result of each imported item as a csv file, or as json with ?format=json.
+func HandleGetImportJobReport(c *fiber.Ctx) error {
+	username := c.Locals(AuthedUsername).(string)
+	jobId, err := strconv.ParseInt(c.Params("job_id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid job id")
+	}
+
+	report, err := services.ImportServiceInstance.GetImportJobReport(context.Background(), username, jobId)
+	if err != nil {
+		return fromServiceError(err)
+	}
+
+	if c.Query("format") == "json" {
+		return c.Status(fiber.StatusOK).JSON(report)
+	}
+
+	c.Attachment(fmt.Sprintf("import_%d_report.csv", jobId))
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+
+	csvWriter := csv.NewWriter(c)
+	csvWriter.Write([]string{"line", "short_url", "long_url", "result", "created_short_url", "error"})
+	for _, it := range report {
+		csvWriter.Write([]string{strconv.Itoa(it.Line), it.ShortUrl, it.LongUrl, it.Result, it.CreatedShortUrl, it.Error})
+	}
+	csvWriter.Flush()
+
+	return csvWriter.Error()
+}
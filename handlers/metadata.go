@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/assaidy/url_shortener/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type SetShortUrlMetadataRequest struct {
+	Title       *string `json:"title"`
+	Description *string `json:"description"`
+	ImageUrl    *string `json:"imageUrl"`
+}
+
+// HandleSetShortUrlMetadata replaces the custom metadata and responds with the fetched, custom
+// and shown metadata. Fields that are null or missing show the fetched ones.
+func HandleSetShortUrlMetadata(c *fiber.Ctx) error {
+	var req SetShortUrlMetadataRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json body")
+	}
+
+	username := c.Locals(AuthedUsername).(string)
+
+	domain := queryDomain(c)
+	if err := services.MetadataServiceInstance.SetShortUrlMetadata(context.Background(), services.SetShortUrlMetadataParams{
+		Username: username,
+		Domain:   domain,
+		ShortUrl: c.Params("short_url"),
+		Custom: services.CustomMetadata{
+			Title:       req.Title,
+			Description: req.Description,
+			ImageUrl:    req.ImageUrl,
+		},
+	}); err != nil {
+		return fromServiceError(err)
+	}
+
+	metadata, err := services.MetadataServiceInstance.GetShortUrlMetadata(context.Background(), username, domain, c.Params("short_url"))
+	if err != nil {
+		return fromServiceError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(metadata)
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/assaidy/url_shortener/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReportShortUrlRequest struct {
+	Reason  string `json:"reason"`
+	Details string `json:"details"`
+}
+
+// HandleReportShortUrl reports a short url of the domain it's visited on.
+func HandleReportShortUrl(c *fiber.Ctx) error {
+	var req ReportShortUrlRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json body")
+	}
+
+	domain, err := hostDomain(c)
+	if err != nil {
+		return fromServiceError(err)
+	}
+
+	if err := services.ModerationServiceInstance.ReportShortUrl(context.Background(), services.ReportShortUrlParams{
+		Domain:     domain,
+		ShortUrl:   c.Params("short_url"),
+		Reason:     req.Reason,
+		Details:    req.Details,
+		ReporterIp: c.IP(),
+	}); err != nil {
+		return fromServiceError(err)
+	}
+
+	return c.SendStatus(fiber.StatusAccepted)
+}
+
+// HandleGetAbuseReports returns a page of reports, open ones by default. The next page
+// starts after the id of the last report.
+func HandleGetAbuseReports(c *fiber.Ctx) error {
+	status := c.Query("status", services.AbuseReportStatusOpen)
+
+	afterId := int64(0)
+	if it := c.Query("after"); it != "" {
+		parsed, err := strconv.ParseInt(it, 10, 64)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid after")
+		}
+		afterId = parsed
+	}
+
+	reports, err := services.ModerationServiceInstance.GetAbuseReports(context.Background(), status, afterId)
+	if err != nil {
+		return fromServiceError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(reports)
+}
+
+func HandleDismissAbuseReport(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+
+	moderator := c.Locals(AuthedUsername).(string)
+
+	if err := services.ModerationServiceInstance.DismissAbuseReport(context.Background(), moderator, int64(id)); err != nil {
+		return fromServiceError(err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+type DisableShortUrlRequest struct {
+	Reason string `json:"reason"`
+}
+
+func HandleDisableShortUrl(c *fiber.Ctx) error {
+	var req DisableShortUrlRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json body")
+	}
+
+	moderator := c.Locals(AuthedUsername).(string)
+
+	if err := services.ModerationServiceInstance.DisableShortUrl(context.Background(), services.DisableShortUrlParams{
+		Moderator: moderator,
+		Domain:    queryDomain(c),
+		ShortUrl:  c.Params("short_url"),
+		Reason:    req.Reason,
+	}); err != nil {
+		return fromServiceError(err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func HandleEnableShortUrl(c *fiber.Ctx) error {
+	if err := services.ModerationServiceInstance.EnableShortUrl(context.Background(), queryDomain(c), c.Params("short_url")); err != nil {
+		return fromServiceError(err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
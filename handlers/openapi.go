@@ -0,0 +1,11 @@
+package handlers
+
+import (
+	"github.com/assaidy/url_shortener/openapi"
+	"github.com/gofiber/fiber/v2"
+)
+
+func HandleGetOpenApiSpec(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+	return c.Status(fiber.StatusOK).Send(openapi.Spec)
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"embed"
+	"html/template"
+	"io/fs"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+//go:embed templates/*.html
+var templatesFS embed.FS
+
+// every page is parsed together with the layout, whose blocks it overrides
+var pageTemplates = parsePageTemplates()
+
+func parsePageTemplates() map[string]*template.Template {
+	layout := template.Must(template.ParseFS(templatesFS, "templates/layout.html"))
+
+	pages, err := fs.Glob(templatesFS, "templates/*.html")
+	if err != nil {
+		panic(err)
+	}
+
+	templates := map[string]*template.Template{}
+	for _, it := range pages {
+		if it == "templates/layout.html" {
+			continue
+		}
+		templates[it[len("templates/"):]] = template.Must(template.Must(layout.Clone()).ParseFS(templatesFS, it))
+	}
+	return templates
+}
+
+func renderPage(c *fiber.Ctx, status int, name string, data any) error {
+	page, ok := pageTemplates[name]
+	if !ok {
+		panic("no page template " + name)
+	}
+
+	c.Status(status)
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return page.ExecuteTemplate(c, "layout.html", data)
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/assaidy/url_shortener/utils"
+	"github.com/gofiber/fiber/v2"
+	fiberutils "github.com/gofiber/fiber/v2/utils"
+)
+
+const MIMEProblemJson = "application/problem+json"
+
+// Stable codes of problems, clients should match them instead of titles and details.
+const (
+	ProblemBadRequest       = "bad_request"
+	ProblemValidationFailed = "validation_failed" // Problem.Errors lists the invalid fields
+	ProblemUnauthorized     = "unauthorized"
+	ProblemForbidden        = "forbidden"
+	ProblemNotFound         = "not_found"
+	ProblemConflict         = "conflict"
+	ProblemUnprocessable    = "unprocessable"
+	ProblemRateLimited      = "rate_limited"
+	ProblemInternal         = "internal"
+)
+
+var problemCodes = map[int]string{
+	fiber.StatusBadRequest:          ProblemBadRequest,
+	fiber.StatusUnauthorized:        ProblemUnauthorized,
+	fiber.StatusForbidden:           ProblemForbidden,
+	fiber.StatusNotFound:            ProblemNotFound,
+	fiber.StatusConflict:            ProblemConflict,
+	fiber.StatusUnprocessableEntity: ProblemUnprocessable,
+	fiber.StatusTooManyRequests:     ProblemRateLimited,
+	fiber.StatusInternalServerError: ProblemInternal,
+}
+
+// Problem is an RFC 7807 error body, sent as application/problem+json.
+type Problem struct {
+	Type   string             `json:"type"`
+	Title  string             `json:"title"`
+	Status int                `json:"status"`
+	Detail string             `json:"detail,omitempty"`
+	Code   string             `json:"code"`
+	Errors []utils.FieldError `json:"errors,omitempty"`
+}
+
+// NewProblem returns the problem of status, with the code of the status. Statuses without a code
+// get their reason phrase in snake case, e.g. payload_too_large.
+func NewProblem(status int, detail string) *Problem {
+	code, ok := problemCodes[status]
+	if !ok {
+		code = strings.ReplaceAll(strings.ToLower(fiberutils.StatusMessage(status)), " ", "_")
+	}
+	return &Problem{
+		Type:   "about:blank",
+		Title:  fiberutils.StatusMessage(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+func (me *Problem) Error() string {
+	return me.Detail
+}
+
+// ErrorHandler sends errors as problem+json, hiding the details of internal errors.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	var problem *Problem
+	if !errors.As(err, &problem) {
+		code := fiber.StatusInternalServerError
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			code = fiberErr.Code
+		}
+		problem = NewProblem(code, err.Error())
+	}
+	// NOTE: Logging occurs before this error handler is executed, so the internal error
+	// has already been logged. We avoid exposing internal error details to the client.
+	if problem.Status == fiber.StatusInternalServerError {
+		problem = NewProblem(fiber.StatusInternalServerError, "")
+	}
+	return c.Status(problem.Status).JSON(problem, MIMEProblemJson)
+}
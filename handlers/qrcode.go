@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/assaidy/url_shortener/config"
+	"github.com/assaidy/url_shortener/services"
+	"github.com/assaidy/url_shortener/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// HandleGetShortUrlQrCode renders the QR code of a short url on ?domain as a PNG or an SVG. The
+// encoded link is marked with ?src=qr, so scans are counted apart in the analytics.
+func HandleGetShortUrlQrCode(c *fiber.Ctx) error {
+	shortUrl := c.Params("short_url")
+	domain := queryDomain(c)
+
+	baseUrl := config.Cfg.Server.PublicBaseUrl
+	if domain != "" {
+		baseUrl = c.Protocol() + "://" + domain
+	} else if baseUrl == "" {
+		baseUrl = c.BaseURL()
+	}
+	content := strings.TrimSuffix(baseUrl, "/") + "/" + shortUrl + "?" + utils.VisitSourceParam + "=" + utils.VisitSourceQr
+
+	format := c.Query("format", services.QrCodeFormatPng)
+	data, err := services.QrCodeServiceInstance.RenderQrCode(context.Background(), services.RenderQrCodeParams{
+		Username:   c.Locals(AuthedUsername).(string),
+		Domain:     domain,
+		ShortUrl:   shortUrl,
+		Content:    content,
+		Format:     format,
+		Size:       c.QueryInt("size", 256),
+		Margin:     c.QueryInt("margin", 4),
+		Level:      c.Query("level"),
+		Foreground: c.Query("fg", "000000"),
+		Background: c.Query("bg", "ffffff"),
+		LogoUrl:    c.Query("logo"),
+	})
+	if err != nil {
+		return fromServiceError(err)
+	}
+
+	if format == services.QrCodeFormatSvg {
+		c.Set(fiber.HeaderContentType, "image/svg+xml")
+	} else {
+		c.Set(fiber.HeaderContentType, "image/png")
+	}
+	return c.Status(fiber.StatusOK).Send(data)
+}
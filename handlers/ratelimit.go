@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/assaidy/url_shortener/config"
+	"github.com/assaidy/url_shortener/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// WithRateLimit limits requests using the policy configured for group.
+// Policies keyed by username must be mounted after WithJwt; requests that don't
+// carry the configured key (no user or no api key) are keyed by their IP.
+func WithRateLimit(group string) fiber.Handler {
+	policy, ok := config.Cfg.RateLimit.Policies.Get(group)
+	if !ok {
+		panic(fmt.Sprintf("no rate limit policy for group %q", group))
+	}
+
+	return func(c *fiber.Ctx) error {
+		if !config.Cfg.RateLimit.Enabled || policy.Max == 0 || services.RateLimitServiceInstance.IsAllowlisted(c.IP()) {
+			return c.Next()
+		}
+
+		result := services.RateLimitServiceInstance.Hit(context.Background(), group, rateLimitKey(c, policy.KeyBy))
+
+		resetSeconds := strconv.Itoa(int(math.Ceil(result.Reset.Seconds())))
+		c.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Set("RateLimit-Reset", resetSeconds)
+		c.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Max, int(policy.Window.Seconds())))
+
+		if !result.Allowed {
+			c.Set(fiber.HeaderRetryAfter, resetSeconds)
+			return fiber.NewError(fiber.StatusTooManyRequests, "rate limit exceeded")
+		}
+
+		return c.Next()
+	}
+}
+
+func rateLimitKey(c *fiber.Ctx, keyBy string) string {
+	switch keyBy {
+	case config.RateLimitKeyByUsername:
+		if username, ok := c.Locals(AuthedUsername).(string); ok {
+			return "user:" + username
+		}
+	case config.RateLimitKeyByApiKey:
+		if apiKey := c.Get(config.Cfg.RateLimit.ApiKeyHeader); apiKey != "" {
+			// don't keep raw keys in the cache
+			sum := sha256.Sum256([]byte(apiKey))
+			return "apikey:" + hex.EncodeToString(sum[:])
+		}
+	}
+	return "ip:" + c.IP()
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"github.com/assaidy/url_shortener/config"
+	"github.com/assaidy/url_shortener/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RegisterRoutes registers the routes of the api and the redirects on router.
+func RegisterRoutes(router *fiber.App) {
+	registerApiRoutes(router.Group(ApiPrefix))
+	if config.Cfg.Server.LegacyRoutes {
+		// the unversioned api and redirects from before /api/v1, the redirect route is the last one
+		// of /urls so it doesn't shadow the others
+		registerApiRoutes(router)
+		router.Get("/urls/:short_url/*", WithRateLimit(config.RateLimitGroupRedirect), HandleRedirectShortUrl)
+	}
+
+	router.Get("/openapi.json", HandleGetOpenApiSpec)
+
+	// the first segments of the other routes are utils.ReservedShortUrls
+	router.Get("/:short_url/*", WithRateLimit(config.RateLimitGroupRedirect), HandleRedirectShortUrl)
+}
+
+func registerApiRoutes(router fiber.Router) {
+	router.Post("/users/register", HandleRegister)
+	router.Post("/users/login", WithRateLimit(config.RateLimitGroupLogin), HandleLogin)
+	router.Delete("/users", WithJwt, HandleDeleteUser)
+
+	router.Post("/urls", WithJwt, WithRateLimit(config.RateLimitGroupCreate), WithIdempotencyKey, HandleCreateShortUrl)
+	router.Get("/urls", WithJwt, HandleListShortUrls)
+	router.Post("/urls/batch", WithJwt, WithRateLimit(config.RateLimitGroupCreate), WithIdempotencyKey, HandleCreateShortUrls)
+	router.Get("/urls/export", WithJwt, HandleExportShortUrls)
+	router.Post("/urls/import", WithJwt, WithRateLimit(config.RateLimitGroupCreate), HandleImportShortUrls)
+	router.Get("/urls/import/:job_id", WithJwt, HandleGetImportJob)
+	router.Get("/urls/import/:job_id/report", WithJwt, HandleGetImportJobReport)
+	router.Patch("/urls/:short_url", WithJwt, HandleUpdateShortUrl)
+	router.Put("/urls/:short_url/rules", WithJwt, HandleSetShortUrlRules)
+	router.Put("/urls/:short_url/variants", WithJwt, HandleSetShortUrlVariants)
+	router.Put("/urls/:short_url/schedule", WithJwt, HandleSetShortUrlSchedule)
+	router.Put("/urls/:short_url/metadata", WithJwt, HandleSetShortUrlMetadata)
+	router.Get("/urls/:short_url/qr", WithJwt, HandleGetShortUrlQrCode)
+	router.Post("/urls/:short_url/report", WithRateLimit(config.RateLimitGroupReport), HandleReportShortUrl)
+
+	router.Post("/domains", WithJwt, HandleAddDomain)
+	router.Get("/domains", WithJwt, HandleGetDomains)
+	router.Post("/domains/:domain/verify", WithJwt, HandleVerifyDomain)
+	router.Delete("/domains/:domain", WithJwt, HandleDeleteDomain)
+
+	router.Post("/utm-templates", WithJwt, HandleCreateUtmTemplate)
+	router.Get("/utm-templates", WithJwt, HandleGetUtmTemplates)
+	router.Delete("/utm-templates/:name", WithJwt, HandleDeleteUtmTemplate)
+
+	router.Post("/campaigns", WithJwt, HandleCreateCampaign)
+	router.Get("/campaigns", WithJwt, HandleGetCampaigns)
+	router.Delete("/campaigns/:name", WithJwt, HandleDeleteCampaign)
+
+	router.Get("/analytics/urls", WithJwt, WithRateLimit(config.RateLimitGroupAnalytics), HandleGetShortUrlsStats)
+	router.Get("/analytics/urls/:short_url", WithJwt, WithRateLimit(config.RateLimitGroupAnalytics), HandleGetShortUrlStats)
+	router.Get("/analytics/campaigns", WithJwt, WithRateLimit(config.RateLimitGroupAnalytics), HandleGetCampaignsStats)
+
+	moderators := WithRole(services.RoleModerator, services.RoleAdmin)
+	router.Get("/admin/reports", WithJwt, moderators, HandleGetAbuseReports)
+	router.Post("/admin/reports/:id/dismiss", WithJwt, moderators, HandleDismissAbuseReport)
+	router.Post("/admin/urls/:short_url/disable", WithJwt, moderators, HandleDisableShortUrl)
+	router.Post("/admin/urls/:short_url/enable", WithJwt, moderators, HandleEnableShortUrl)
+	router.Put("/admin/users/:username/role", WithJwt, WithRole(services.RoleAdmin), HandleSetUserRole)
+}
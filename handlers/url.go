@@ -2,15 +2,30 @@ package handlers
 
 import (
 	"context"
+	"net/http"
+	"strings"
 	"time"
 
+	"github.com/assaidy/url_shortener/config"
 	"github.com/assaidy/url_shortener/services"
+	"github.com/assaidy/url_shortener/utils"
 	"github.com/gofiber/fiber/v2"
 )
 
 type CreateShortUrlRequest struct {
-	LongUrl  string `json:"longUrl"`
-	ShortUrl string `json:"shortUrl"`
+	Domain          string                `json:"domain"`
+	LongUrl         string                `json:"longUrl"`
+	ShortUrl        string                `json:"shortUrl"`
+	RedirectStatus  int                   `json:"redirectStatus"`
+	CachePolicy     string                `json:"cachePolicy"`
+	ForwardPath     bool                  `json:"forwardPath"`
+	QueryForwarding string                `json:"queryForwarding"`
+	Campaign        string                `json:"campaign"`
+	UtmTemplate     string                `json:"utmTemplate"`
+	Rules           []utils.RoutingRule   `json:"rules"`
+	Variants        []utils.Variant       `json:"variants"`
+	Schedule        []utils.ScheduleEntry `json:"schedule"`
+	Interstitial    bool                  `json:"interstitial"`
 }
 
 func HandleCreateShortUrl(c *fiber.Ctx) error {
@@ -21,33 +36,367 @@ func HandleCreateShortUrl(c *fiber.Ctx) error {
 
 	username := c.Locals(AuthedUsername).(string)
 
+	domain := utils.NormalizeDomain(req.Domain)
 	shortUrl, err := services.UrlServiceInstance.CreateShortUrl(context.Background(), services.CreateShortUrlParams{
-		Username: username,
-		LongUrl:  req.LongUrl,
-		ShortUrl: req.ShortUrl,
+		Username:        username,
+		Domain:          domain,
+		LongUrl:         req.LongUrl,
+		ShortUrl:        req.ShortUrl,
+		RedirectStatus:  req.RedirectStatus,
+		CachePolicy:     req.CachePolicy,
+		ForwardPath:     req.ForwardPath,
+		QueryForwarding: req.QueryForwarding,
+		Campaign:        req.Campaign,
+		UtmTemplate:     req.UtmTemplate,
+		Rules:           req.Rules,
+		Variants:        req.Variants,
+		Schedule:        req.Schedule,
+		Interstitial:    req.Interstitial,
 	})
 	if err != nil {
 		return fromServiceError(err)
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"domain":   domain,
 		"shortUrl": shortUrl,
 	})
 }
 
+// UpdateShortUrlRequest only changes the fields present in the body.
+type UpdateShortUrlRequest struct {
+	LongUrl         *string `json:"longUrl"`
+	RedirectStatus  *int    `json:"redirectStatus"`
+	CachePolicy     *string `json:"cachePolicy"`
+	ForwardPath     *bool   `json:"forwardPath"`
+	QueryForwarding *string `json:"queryForwarding"`
+	Interstitial    *bool   `json:"interstitial"`
+}
+
+func HandleUpdateShortUrl(c *fiber.Ctx) error {
+	var req UpdateShortUrlRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json body")
+	}
+
+	username := c.Locals(AuthedUsername).(string)
+
+	if err := services.UrlServiceInstance.UpdateShortUrl(context.Background(), services.UpdateShortUrlParams{
+		Username:        username,
+		Domain:          queryDomain(c),
+		ShortUrl:        c.Params("short_url"),
+		LongUrl:         req.LongUrl,
+		RedirectStatus:  req.RedirectStatus,
+		CachePolicy:     req.CachePolicy,
+		ForwardPath:     req.ForwardPath,
+		QueryForwarding: req.QueryForwarding,
+		Interstitial:    req.Interstitial,
+	}); err != nil {
+		return fromServiceError(err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+type SetShortUrlRulesRequest struct {
+	Rules []utils.RoutingRule `json:"rules"`
+}
+
+func HandleSetShortUrlRules(c *fiber.Ctx) error {
+	var req SetShortUrlRulesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json body")
+	}
+
+	username := c.Locals(AuthedUsername).(string)
+
+	if err := services.UrlServiceInstance.SetShortUrlRules(context.Background(), services.SetShortUrlRulesParams{
+		Username: username,
+		Domain:   queryDomain(c),
+		ShortUrl: c.Params("short_url"),
+		Rules:    req.Rules,
+	}); err != nil {
+		return fromServiceError(err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+type SetShortUrlVariantsRequest struct {
+	Variants []utils.Variant `json:"variants"`
+}
+
+func HandleSetShortUrlVariants(c *fiber.Ctx) error {
+	var req SetShortUrlVariantsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json body")
+	}
+
+	username := c.Locals(AuthedUsername).(string)
+
+	if err := services.UrlServiceInstance.SetShortUrlVariants(context.Background(), services.SetShortUrlVariantsParams{
+		Username: username,
+		Domain:   queryDomain(c),
+		ShortUrl: c.Params("short_url"),
+		Variants: req.Variants,
+	}); err != nil {
+		return fromServiceError(err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+type SetShortUrlScheduleRequest struct {
+	Schedule []utils.ScheduleEntry `json:"schedule"`
+}
+
+func HandleSetShortUrlSchedule(c *fiber.Ctx) error {
+	var req SetShortUrlScheduleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json body")
+	}
+
+	username := c.Locals(AuthedUsername).(string)
+
+	if err := services.UrlServiceInstance.SetShortUrlSchedule(context.Background(), services.SetShortUrlScheduleParams{
+		Username: username,
+		Domain:   queryDomain(c),
+		ShortUrl: c.Params("short_url"),
+		Schedule: req.Schedule,
+	}); err != nil {
+		return fromServiceError(err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+type CreateShortUrlsRequest struct {
+	Domain string                  `json:"domain"` // of all the items
+	Items  []CreateShortUrlRequest `json:"items"`
+}
+
+// HandleCreateShortUrls responds with 201 when all items are created, and with 207 and
+// the status of each item otherwise.
+func HandleCreateShortUrls(c *fiber.Ctx) error {
+	var req CreateShortUrlsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json body")
+	}
+
+	username := c.Locals(AuthedUsername).(string)
+
+	items := make([]services.CreateShortUrlsItem, len(req.Items))
+	for i, it := range req.Items {
+		if it.Domain != "" {
+			return fiber.NewError(fiber.StatusBadRequest, "the domain of a batch is set on the batch, not on its items")
+		}
+		items[i] = services.CreateShortUrlsItem{
+			LongUrl:         it.LongUrl,
+			ShortUrl:        it.ShortUrl,
+			RedirectStatus:  it.RedirectStatus,
+			CachePolicy:     it.CachePolicy,
+			ForwardPath:     it.ForwardPath,
+			QueryForwarding: it.QueryForwarding,
+			Campaign:        it.Campaign,
+			UtmTemplate:     it.UtmTemplate,
+			Rules:           it.Rules,
+			Variants:        it.Variants,
+			Schedule:        it.Schedule,
+			Interstitial:    it.Interstitial,
+		}
+	}
+
+	results, err := services.UrlServiceInstance.CreateShortUrls(context.Background(), services.CreateShortUrlsParams{
+		Username: username,
+		Domain:   utils.NormalizeDomain(req.Domain),
+		Items:    items,
+	})
+	if err != nil {
+		return fromServiceError(err)
+	}
+
+	status := fiber.StatusCreated
+	resultsJson := make([]fiber.Map, len(results))
+	for i, it := range results {
+		if it.Err != nil {
+			status = fiber.StatusMultiStatus
+			problem := fromServiceError(it.Err)
+			resultsJson[i] = fiber.Map{"index": i, "status": problem.Status, "code": problem.Code, "error": problem.Detail}
+			if len(problem.Errors) != 0 {
+				resultsJson[i]["errors"] = problem.Errors
+			}
+		} else {
+			resultsJson[i] = fiber.Map{"index": i, "status": fiber.StatusCreated, "shortUrl": it.ShortUrl}
+		}
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"results": resultsJson,
+	})
+}
+
+// HandleListShortUrls returns a page of the user's short urls on ?domain with the last check of
+// their destinations. Only broken ones are returned with ?broken=true, and the next page starts
+// after the last short url with ?after.
+func HandleListShortUrls(c *fiber.Ctx) error {
+	username := c.Locals(AuthedUsername).(string)
+
+	listed, err := services.UrlServiceInstance.ListShortUrls(context.Background(), services.ListShortUrlsParams{
+		Username:      username,
+		Domain:        queryDomain(c),
+		AfterShortUrl: c.Query("after"),
+		BrokenOnly:    c.QueryBool("broken", false),
+	})
+	if err != nil {
+		return fromServiceError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(listed)
+}
+
+const (
+	variantCookiePrefix = "variant_"
+	variantCookieMaxAge = 30 * 24 * time.Hour
+)
+
+// HandleRedirectShortUrl is mounted as a prefix match on /:short_url/*, the rest of the path is only
+// accepted for short urls that forward it. A short url followed by "+" shows its preview page.
+// Short urls are looked up on the custom domain of the Host header, if it's one.
 func HandleRedirectShortUrl(c *fiber.Ctx) error {
 	shortUrl := c.Params("short_url")
+	forwardedPath := c.Params("*")
+
+	domain, err := hostDomain(c)
+	if err != nil {
+		return fromServiceError(err)
+	}
+
+	if previewed, ok := strings.CutSuffix(shortUrl, "+"); ok && forwardedPath == "" {
+		return handlePreviewShortUrl(c, domain, previewed)
+	}
 
-	longUrl, err := services.UrlServiceInstance.GetLongUrl(context.Background(), shortUrl)
+	info, err := services.UrlServiceInstance.GetLongUrl(context.Background(), domain, shortUrl)
 	if err != nil {
 		return fromServiceError(err)
 	}
 
+	if forwardedPath != "" && !info.ForwardPath {
+		return fiber.NewError(fiber.StatusNotFound, "url not found")
+	}
+
+	if info.Disabled {
+		return renderDisabledPage(c, info.DisabledReason)
+	}
+
+	switch info.State {
+	case utils.ScheduleStateComingSoon:
+		c.Set(fiber.HeaderCacheControl, "no-store")
+		if !info.StateUntil.IsZero() {
+			c.Set(fiber.HeaderRetryAfter, info.StateUntil.UTC().Format(http.TimeFormat))
+		}
+		return renderPage(c, fiber.StatusOK, "coming_soon.html", fiber.Map{"Until": info.StateUntil})
+	case utils.ScheduleStateExpired:
+		c.Set(fiber.HeaderCacheControl, "no-store")
+		return renderPage(c, fiber.StatusGone, "expired.html", nil)
+	}
+
+	longUrl := info.LongUrl
+	ruleMatched := false
+	if len(info.Rules) != 0 {
+		country := ""
+		if utils.UsesCountries(info.Rules) {
+			country = services.GeoIpServiceInstance.Country(c.IP())
+		}
+		attrs := utils.NewVisitorAttributes(c.Get(fiber.HeaderUserAgent), c.Get(fiber.HeaderAcceptLanguage), country)
+		if ruleLongUrl, ok := utils.MatchRoutingRules(info.Rules, attrs); ok {
+			longUrl = ruleLongUrl
+			ruleMatched = true
+		}
+		// the destination depends on these headers, so shared caches must not mix them up
+		c.Vary(fiber.HeaderUserAgent, fiber.HeaderAcceptLanguage)
+	}
+
+	variantName := ""
+	if !ruleMatched && len(info.Variants) != 0 {
+		cookieName := variantCookiePrefix + shortUrl
+		if variant, ok := utils.PickVariant(info.Variants, c.Cookies(cookieName), shortUrl+":"+c.IP()); ok {
+			longUrl = variant.LongUrl
+			variantName = variant.Name
+			c.Cookie(&fiber.Cookie{
+				Name:     cookieName,
+				Value:    variant.Name,
+				Path:     strings.TrimSuffix(c.Route().Path, "/:short_url/*") + "/" + shortUrl,
+				MaxAge:   int(variantCookieMaxAge.Seconds()),
+				HTTPOnly: true,
+				SameSite: fiber.CookieSameSiteLaxMode,
+			})
+		}
+	}
+
+	// the source marker is only for our analytics, it isn't forwarded
+	source, rawQuery := utils.CutVisitSource(string(c.Request().URI().QueryString()))
+
+	longUrl, err = utils.ForwardUrl(longUrl, forwardedPath, rawQuery, info.QueryForwarding)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// link preview crawlers get the metadata of the destination instead of a bare redirect,
+	// linking to where the redirect would have gone
+	if !info.Metadata.IsZero() {
+		c.Vary(fiber.HeaderUserAgent)
+		if utils.IsPreviewCrawler(c.Get(fiber.HeaderUserAgent)) {
+			return renderPage(c, fiber.StatusOK, "crawler.html", fiber.Map{
+				"Url":      c.BaseURL() + c.OriginalURL(),
+				"LongUrl":  longUrl,
+				"Metadata": info.Metadata,
+			})
+		}
+	}
+
 	services.UrlServiceInstance.StoreUrlVisit(services.UrlVisit{
+		Domain:    domain,
 		ShorUrl:   shortUrl,
-		VisitorIp: c.IP(), // NOTE: read docs of this func
+		VisitorIp: c.IP(), // from config.Cfg.Server.ProxyHeader when sent by a trusted proxy
 		VisitedAt: time.Now().UTC(),
+		Variant:   variantName,
+		Source:    source,
 	})
 
-	return c.Redirect(longUrl)
+	if info.CachePolicy != "" {
+		c.Set(fiber.HeaderCacheControl, info.CachePolicy)
+	}
+
+	if info.Interstitial {
+		return renderPage(c, fiber.StatusOK, "interstitial.html", fiber.Map{
+			"LongUrl": longUrl,
+			"Seconds": int(config.Cfg.Urls.InterstitialDelay.Seconds()),
+		})
+	}
+
+	return c.Redirect(longUrl, info.RedirectStatus)
+}
+
+func handlePreviewShortUrl(c *fiber.Ctx, domain string, shortUrl string) error {
+	preview, err := services.UrlServiceInstance.GetShortUrlPreview(context.Background(), domain, shortUrl)
+	if err != nil {
+		return fromServiceError(err)
+	}
+
+	if preview.Disabled {
+		return renderDisabledPage(c, preview.DisabledReason)
+	}
+
+	return renderPage(c, fiber.StatusOK, "preview.html", preview)
+}
+
+// renderDisabledPage answers 451 for links taken down for legal reasons and 410 otherwise.
+func renderDisabledPage(c *fiber.Ctx, reason string) error {
+	status := fiber.StatusGone
+	if reason == services.DisabledReasonIllegal {
+		status = fiber.StatusUnavailableForLegalReasons
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return renderPage(c, status, "disabled.html", fiber.Map{"Legal": status == fiber.StatusUnavailableForLegalReasons})
 }
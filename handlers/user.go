@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"slices"
 	"strings"
 	"time"
 
@@ -21,8 +22,9 @@ func HandleRegister(c *fiber.Ctx) error {
 	}
 
 	if err := services.UserServiceInstance.CreateUser(context.Background(), services.CreateUserParams{
-		Username: req.Username,
-		Password: req.Password,
+		Username:       req.Username,
+		Password:       req.Password,
+		SelfRegistered: true,
 	}); err != nil {
 		return fromServiceError(err)
 	}
@@ -54,22 +56,22 @@ func HandleLogin(c *fiber.Ctx) error {
 func WithJwt(c *fiber.Ctx) error {
 	tokenString := strings.TrimSpace(strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer"))
 	if tokenString == "" {
-		return c.Status(fiber.StatusBadRequest).SendString("missing or malformed Authorization header")
+		return fiber.NewError(fiber.StatusBadRequest, "missing or malformed Authorization header")
 	}
 
 	claims, err := services.UserServiceInstance.ParseJwtTokenString(tokenString)
 	if err != nil {
-		return c.SendStatus(fiber.StatusUnauthorized)
+		return fiber.NewError(fiber.StatusUnauthorized, "invalid token")
 	}
 
 	if time.Until(claims.ExpiresAt.Time) <= 0 {
-		return c.SendStatus(fiber.StatusUnauthorized)
+		return fiber.NewError(fiber.StatusUnauthorized, "expired token")
 	}
 
 	if ok, err := services.UserServiceInstance.CheckUsername(context.Background(), claims.Username); err != nil {
 		return fromServiceError(err)
 	} else if !ok { // user was deleted before token expiration
-		return c.SendStatus(fiber.StatusUnauthorized)
+		return fiber.NewError(fiber.StatusUnauthorized, "user not found")
 	}
 
 	c.Locals(AuthedUsername, claims.Username)
@@ -85,3 +87,40 @@ func HandleDeleteUser(c *fiber.Ctx) error {
 
 	return nil
 }
+
+// WithRole only lets users with one of roles through. It must come after WithJwt.
+func WithRole(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		username := c.Locals(AuthedUsername).(string)
+
+		role, err := services.UserServiceInstance.GetUserRole(context.Background(), username)
+		if err != nil {
+			return fromServiceError(err)
+		}
+		if !slices.Contains(roles, role) {
+			return fiber.NewError(fiber.StatusForbidden, "role not allowed")
+		}
+
+		return c.Next()
+	}
+}
+
+type SetUserRoleRequest struct {
+	Role string `json:"role"`
+}
+
+func HandleSetUserRole(c *fiber.Ctx) error {
+	var req SetUserRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json body")
+	}
+
+	if err := services.UserServiceInstance.SetUserRole(context.Background(), services.SetUserRoleParams{
+		Username: c.Params("username"),
+		Role:     req.Role,
+	}); err != nil {
+		return fromServiceError(err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
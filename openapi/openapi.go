@@ -0,0 +1,7 @@
+// Package openapi holds the OpenAPI document of the http api, served at /openapi.json.
+package openapi
+
+import _ "embed"
+
+//go:embed openapi.json
+var Spec []byte
@@ -0,0 +1,79 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/assaidy/url_shortener/config"
+	"github.com/assaidy/url_shortener/handlers"
+	"github.com/assaidy/url_shortener/openapi"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var routeParamRegex = regexp.MustCompile(`:(\w+)`)
+
+// registeredRoutes returns the routes of the server as documented paths, e.g. GET /api/v1/urls/{short_url}.
+// The legacy routes are the same as the /api/v1 ones, so they're left out.
+func registeredRoutes(t *testing.T) []string {
+	config.Cfg = config.Default()
+	config.Cfg.Server.LegacyRoutes = false
+
+	app := fiber.New()
+	handlers.RegisterRoutes(app)
+
+	routes := []string{}
+	for _, it := range app.GetRoutes(true) {
+		if it.Method == fiber.MethodHead { // added by fiber for each GET route
+			continue
+		}
+		path := routeParamRegex.ReplaceAllString(strings.TrimSuffix(it.Path, "/*"), "{$1}")
+		routes = append(routes, it.Method+" "+path)
+	}
+	return routes
+}
+
+func documentedRoutes(t *testing.T) []string {
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(openapi.Spec, &spec))
+
+	routes := []string{}
+	for path, operations := range spec.Paths {
+		for method := range operations {
+			routes = append(routes, strings.ToUpper(method)+" "+path)
+		}
+	}
+	return routes
+}
+
+func TestSpecMatchesRoutes(t *testing.T) {
+	registered := registeredRoutes(t)
+	require.NotEmpty(t, registered)
+
+	assert.ElementsMatch(t, registered, documentedRoutes(t))
+}
+
+func TestLegacyRoutesMatchApiRoutes(t *testing.T) {
+	config.Cfg = config.Default()
+	config.Cfg.Server.LegacyRoutes = true
+
+	app := fiber.New()
+	handlers.RegisterRoutes(app)
+
+	routes := []string{}
+	for _, it := range app.GetRoutes(true) {
+		routes = append(routes, it.Method+" "+it.Path)
+	}
+	for _, it := range routes {
+		method, path, _ := strings.Cut(it, " ")
+		if legacyPath, ok := strings.CutPrefix(path, handlers.ApiPrefix); ok {
+			assert.True(t, slices.Contains(routes, method+" "+legacyPath), "no legacy route for %s", it)
+		}
+	}
+}
@@ -0,0 +1,618 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: url_shortener.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CreateShortUrlRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Domain         string                 `protobuf:"bytes,1,opt,name=domain,proto3" json:"domain,omitempty"` // a verified custom domain of the user, empty for the main domain
+	LongUrl        string                 `protobuf:"bytes,2,opt,name=long_url,json=longUrl,proto3" json:"long_url,omitempty"`
+	ShortUrl       string                 `protobuf:"bytes,3,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`                    // generated when empty
+	RedirectStatus int32                  `protobuf:"varint,4,opt,name=redirect_status,json=redirectStatus,proto3" json:"redirect_status,omitempty"` // 301, 302, 307 or 308, defaults to 302
+	ForwardPath    bool                   `protobuf:"varint,5,opt,name=forward_path,json=forwardPath,proto3" json:"forward_path,omitempty"`
+	Campaign       string                 `protobuf:"bytes,6,opt,name=campaign,proto3" json:"campaign,omitempty"`
+	UtmTemplate    string                 `protobuf:"bytes,7,opt,name=utm_template,json=utmTemplate,proto3" json:"utm_template,omitempty"`
+	Interstitial   bool                   `protobuf:"varint,8,opt,name=interstitial,proto3" json:"interstitial,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CreateShortUrlRequest) Reset() {
+	*x = CreateShortUrlRequest{}
+	mi := &file_url_shortener_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateShortUrlRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateShortUrlRequest) ProtoMessage() {}
+
+func (x *CreateShortUrlRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_url_shortener_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateShortUrlRequest.ProtoReflect.Descriptor instead.
+func (*CreateShortUrlRequest) Descriptor() ([]byte, []int) {
+	return file_url_shortener_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CreateShortUrlRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *CreateShortUrlRequest) GetLongUrl() string {
+	if x != nil {
+		return x.LongUrl
+	}
+	return ""
+}
+
+func (x *CreateShortUrlRequest) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+func (x *CreateShortUrlRequest) GetRedirectStatus() int32 {
+	if x != nil {
+		return x.RedirectStatus
+	}
+	return 0
+}
+
+func (x *CreateShortUrlRequest) GetForwardPath() bool {
+	if x != nil {
+		return x.ForwardPath
+	}
+	return false
+}
+
+func (x *CreateShortUrlRequest) GetCampaign() string {
+	if x != nil {
+		return x.Campaign
+	}
+	return ""
+}
+
+func (x *CreateShortUrlRequest) GetUtmTemplate() string {
+	if x != nil {
+		return x.UtmTemplate
+	}
+	return ""
+}
+
+func (x *CreateShortUrlRequest) GetInterstitial() bool {
+	if x != nil {
+		return x.Interstitial
+	}
+	return false
+}
+
+type CreateShortUrlResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Domain        string                 `protobuf:"bytes,1,opt,name=domain,proto3" json:"domain,omitempty"`
+	ShortUrl      string                 `protobuf:"bytes,2,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateShortUrlResponse) Reset() {
+	*x = CreateShortUrlResponse{}
+	mi := &file_url_shortener_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateShortUrlResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateShortUrlResponse) ProtoMessage() {}
+
+func (x *CreateShortUrlResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_url_shortener_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateShortUrlResponse.ProtoReflect.Descriptor instead.
+func (*CreateShortUrlResponse) Descriptor() ([]byte, []int) {
+	return file_url_shortener_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateShortUrlResponse) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *CreateShortUrlResponse) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+type ResolveShortUrlRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Domain        string                 `protobuf:"bytes,1,opt,name=domain,proto3" json:"domain,omitempty"`
+	ShortUrl      string                 `protobuf:"bytes,2,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolveShortUrlRequest) Reset() {
+	*x = ResolveShortUrlRequest{}
+	mi := &file_url_shortener_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolveShortUrlRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolveShortUrlRequest) ProtoMessage() {}
+
+func (x *ResolveShortUrlRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_url_shortener_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolveShortUrlRequest.ProtoReflect.Descriptor instead.
+func (*ResolveShortUrlRequest) Descriptor() ([]byte, []int) {
+	return file_url_shortener_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ResolveShortUrlRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *ResolveShortUrlRequest) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+type ResolveShortUrlResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	LongUrl        string                 `protobuf:"bytes,1,opt,name=long_url,json=longUrl,proto3" json:"long_url,omitempty"` // rules and variants may send visitors elsewhere
+	RedirectStatus int32                  `protobuf:"varint,2,opt,name=redirect_status,json=redirectStatus,proto3" json:"redirect_status,omitempty"`
+	Disabled       bool                   `protobuf:"varint,3,opt,name=disabled,proto3" json:"disabled,omitempty"`
+	DisabledReason string                 `protobuf:"bytes,4,opt,name=disabled_reason,json=disabledReason,proto3" json:"disabled_reason,omitempty"`
+	State          string                 `protobuf:"bytes,5,opt,name=state,proto3" json:"state,omitempty"` // of the schedule, empty without one
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ResolveShortUrlResponse) Reset() {
+	*x = ResolveShortUrlResponse{}
+	mi := &file_url_shortener_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolveShortUrlResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolveShortUrlResponse) ProtoMessage() {}
+
+func (x *ResolveShortUrlResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_url_shortener_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolveShortUrlResponse.ProtoReflect.Descriptor instead.
+func (*ResolveShortUrlResponse) Descriptor() ([]byte, []int) {
+	return file_url_shortener_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ResolveShortUrlResponse) GetLongUrl() string {
+	if x != nil {
+		return x.LongUrl
+	}
+	return ""
+}
+
+func (x *ResolveShortUrlResponse) GetRedirectStatus() int32 {
+	if x != nil {
+		return x.RedirectStatus
+	}
+	return 0
+}
+
+func (x *ResolveShortUrlResponse) GetDisabled() bool {
+	if x != nil {
+		return x.Disabled
+	}
+	return false
+}
+
+func (x *ResolveShortUrlResponse) GetDisabledReason() string {
+	if x != nil {
+		return x.DisabledReason
+	}
+	return ""
+}
+
+func (x *ResolveShortUrlResponse) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+type GetStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Domain        string                 `protobuf:"bytes,1,opt,name=domain,proto3" json:"domain,omitempty"`
+	ShortUrl      string                 `protobuf:"bytes,2,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	From          *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=from,proto3" json:"from,omitempty"`
+	To            *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatsRequest) Reset() {
+	*x = GetStatsRequest{}
+	mi := &file_url_shortener_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsRequest) ProtoMessage() {}
+
+func (x *GetStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_url_shortener_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetStatsRequest) Descriptor() ([]byte, []int) {
+	return file_url_shortener_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetStatsRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *GetStatsRequest) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+func (x *GetStatsRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *GetStatsRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+type DailyVisits struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Day           *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=day,proto3" json:"day,omitempty"`
+	Visits        int64                  `protobuf:"varint,2,opt,name=visits,proto3" json:"visits,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DailyVisits) Reset() {
+	*x = DailyVisits{}
+	mi := &file_url_shortener_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DailyVisits) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DailyVisits) ProtoMessage() {}
+
+func (x *DailyVisits) ProtoReflect() protoreflect.Message {
+	mi := &file_url_shortener_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DailyVisits.ProtoReflect.Descriptor instead.
+func (*DailyVisits) Descriptor() ([]byte, []int) {
+	return file_url_shortener_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DailyVisits) GetDay() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Day
+	}
+	return nil
+}
+
+func (x *DailyVisits) GetVisits() int64 {
+	if x != nil {
+		return x.Visits
+	}
+	return 0
+}
+
+type GetStatsResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ShortUrl       string                 `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	LongUrl        string                 `protobuf:"bytes,2,opt,name=long_url,json=longUrl,proto3" json:"long_url,omitempty"`
+	Campaign       string                 `protobuf:"bytes,3,opt,name=campaign,proto3" json:"campaign,omitempty"`
+	Visits         int64                  `protobuf:"varint,4,opt,name=visits,proto3" json:"visits,omitempty"`
+	UniqueVisitors int64                  `protobuf:"varint,5,opt,name=unique_visitors,json=uniqueVisitors,proto3" json:"unique_visitors,omitempty"`
+	DailyVisits    []*DailyVisits         `protobuf:"bytes,6,rep,name=daily_visits,json=dailyVisits,proto3" json:"daily_visits,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetStatsResponse) Reset() {
+	*x = GetStatsResponse{}
+	mi := &file_url_shortener_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsResponse) ProtoMessage() {}
+
+func (x *GetStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_url_shortener_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetStatsResponse) Descriptor() ([]byte, []int) {
+	return file_url_shortener_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetStatsResponse) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+func (x *GetStatsResponse) GetLongUrl() string {
+	if x != nil {
+		return x.LongUrl
+	}
+	return ""
+}
+
+func (x *GetStatsResponse) GetCampaign() string {
+	if x != nil {
+		return x.Campaign
+	}
+	return ""
+}
+
+func (x *GetStatsResponse) GetVisits() int64 {
+	if x != nil {
+		return x.Visits
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetUniqueVisitors() int64 {
+	if x != nil {
+		return x.UniqueVisitors
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetDailyVisits() []*DailyVisits {
+	if x != nil {
+		return x.DailyVisits
+	}
+	return nil
+}
+
+var File_url_shortener_proto protoreflect.FileDescriptor
+
+const file_url_shortener_proto_rawDesc = "" +
+	"\n" +
+	"\x13url_shortener.proto\x12\x10url_shortener.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x96\x02\n" +
+	"\x15CreateShortUrlRequest\x12\x16\n" +
+	"\x06domain\x18\x01 \x01(\tR\x06domain\x12\x19\n" +
+	"\blong_url\x18\x02 \x01(\tR\alongUrl\x12\x1b\n" +
+	"\tshort_url\x18\x03 \x01(\tR\bshortUrl\x12'\n" +
+	"\x0fredirect_status\x18\x04 \x01(\x05R\x0eredirectStatus\x12!\n" +
+	"\fforward_path\x18\x05 \x01(\bR\vforwardPath\x12\x1a\n" +
+	"\bcampaign\x18\x06 \x01(\tR\bcampaign\x12!\n" +
+	"\futm_template\x18\a \x01(\tR\vutmTemplate\x12\"\n" +
+	"\finterstitial\x18\b \x01(\bR\finterstitial\"M\n" +
+	"\x16CreateShortUrlResponse\x12\x16\n" +
+	"\x06domain\x18\x01 \x01(\tR\x06domain\x12\x1b\n" +
+	"\tshort_url\x18\x02 \x01(\tR\bshortUrl\"M\n" +
+	"\x16ResolveShortUrlRequest\x12\x16\n" +
+	"\x06domain\x18\x01 \x01(\tR\x06domain\x12\x1b\n" +
+	"\tshort_url\x18\x02 \x01(\tR\bshortUrl\"\xb8\x01\n" +
+	"\x17ResolveShortUrlResponse\x12\x19\n" +
+	"\blong_url\x18\x01 \x01(\tR\alongUrl\x12'\n" +
+	"\x0fredirect_status\x18\x02 \x01(\x05R\x0eredirectStatus\x12\x1a\n" +
+	"\bdisabled\x18\x03 \x01(\bR\bdisabled\x12'\n" +
+	"\x0fdisabled_reason\x18\x04 \x01(\tR\x0edisabledReason\x12\x14\n" +
+	"\x05state\x18\x05 \x01(\tR\x05state\"\xa2\x01\n" +
+	"\x0fGetStatsRequest\x12\x16\n" +
+	"\x06domain\x18\x01 \x01(\tR\x06domain\x12\x1b\n" +
+	"\tshort_url\x18\x02 \x01(\tR\bshortUrl\x12.\n" +
+	"\x04from\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\"S\n" +
+	"\vDailyVisits\x12,\n" +
+	"\x03day\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x03day\x12\x16\n" +
+	"\x06visits\x18\x02 \x01(\x03R\x06visits\"\xe9\x01\n" +
+	"\x10GetStatsResponse\x12\x1b\n" +
+	"\tshort_url\x18\x01 \x01(\tR\bshortUrl\x12\x19\n" +
+	"\blong_url\x18\x02 \x01(\tR\alongUrl\x12\x1a\n" +
+	"\bcampaign\x18\x03 \x01(\tR\bcampaign\x12\x16\n" +
+	"\x06visits\x18\x04 \x01(\x03R\x06visits\x12'\n" +
+	"\x0funique_visitors\x18\x05 \x01(\x03R\x0euniqueVisitors\x12@\n" +
+	"\fdaily_visits\x18\x06 \x03(\v2\x1d.url_shortener.v1.DailyVisitsR\vdailyVisits2\xae\x02\n" +
+	"\fUrlShortener\x12c\n" +
+	"\x0eCreateShortUrl\x12'.url_shortener.v1.CreateShortUrlRequest\x1a(.url_shortener.v1.CreateShortUrlResponse\x12f\n" +
+	"\x0fResolveShortUrl\x12(.url_shortener.v1.ResolveShortUrlRequest\x1a).url_shortener.v1.ResolveShortUrlResponse\x12Q\n" +
+	"\bGetStats\x12!.url_shortener.v1.GetStatsRequest\x1a\".url_shortener.v1.GetStatsResponseB%Z#github.com/assaidy/url_shortener/pbb\x06proto3"
+
+var (
+	file_url_shortener_proto_rawDescOnce sync.Once
+	file_url_shortener_proto_rawDescData []byte
+)
+
+func file_url_shortener_proto_rawDescGZIP() []byte {
+	file_url_shortener_proto_rawDescOnce.Do(func() {
+		file_url_shortener_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_url_shortener_proto_rawDesc), len(file_url_shortener_proto_rawDesc)))
+	})
+	return file_url_shortener_proto_rawDescData
+}
+
+var file_url_shortener_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_url_shortener_proto_goTypes = []any{
+	(*CreateShortUrlRequest)(nil),   // 0: url_shortener.v1.CreateShortUrlRequest
+	(*CreateShortUrlResponse)(nil),  // 1: url_shortener.v1.CreateShortUrlResponse
+	(*ResolveShortUrlRequest)(nil),  // 2: url_shortener.v1.ResolveShortUrlRequest
+	(*ResolveShortUrlResponse)(nil), // 3: url_shortener.v1.ResolveShortUrlResponse
+	(*GetStatsRequest)(nil),         // 4: url_shortener.v1.GetStatsRequest
+	(*DailyVisits)(nil),             // 5: url_shortener.v1.DailyVisits
+	(*GetStatsResponse)(nil),        // 6: url_shortener.v1.GetStatsResponse
+	(*timestamppb.Timestamp)(nil),   // 7: google.protobuf.Timestamp
+}
+var file_url_shortener_proto_depIdxs = []int32{
+	7, // 0: url_shortener.v1.GetStatsRequest.from:type_name -> google.protobuf.Timestamp
+	7, // 1: url_shortener.v1.GetStatsRequest.to:type_name -> google.protobuf.Timestamp
+	7, // 2: url_shortener.v1.DailyVisits.day:type_name -> google.protobuf.Timestamp
+	5, // 3: url_shortener.v1.GetStatsResponse.daily_visits:type_name -> url_shortener.v1.DailyVisits
+	0, // 4: url_shortener.v1.UrlShortener.CreateShortUrl:input_type -> url_shortener.v1.CreateShortUrlRequest
+	2, // 5: url_shortener.v1.UrlShortener.ResolveShortUrl:input_type -> url_shortener.v1.ResolveShortUrlRequest
+	4, // 6: url_shortener.v1.UrlShortener.GetStats:input_type -> url_shortener.v1.GetStatsRequest
+	1, // 7: url_shortener.v1.UrlShortener.CreateShortUrl:output_type -> url_shortener.v1.CreateShortUrlResponse
+	3, // 8: url_shortener.v1.UrlShortener.ResolveShortUrl:output_type -> url_shortener.v1.ResolveShortUrlResponse
+	6, // 9: url_shortener.v1.UrlShortener.GetStats:output_type -> url_shortener.v1.GetStatsResponse
+	7, // [7:10] is the sub-list for method output_type
+	4, // [4:7] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_url_shortener_proto_init() }
+func file_url_shortener_proto_init() {
+	if File_url_shortener_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_url_shortener_proto_rawDesc), len(file_url_shortener_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_url_shortener_proto_goTypes,
+		DependencyIndexes: file_url_shortener_proto_depIdxs,
+		MessageInfos:      file_url_shortener_proto_msgTypes,
+	}.Build()
+	File_url_shortener_proto = out.File
+	file_url_shortener_proto_goTypes = nil
+	file_url_shortener_proto_depIdxs = nil
+}
@@ -0,0 +1,205 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: url_shortener.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	UrlShortener_CreateShortUrl_FullMethodName  = "/url_shortener.v1.UrlShortener/CreateShortUrl"
+	UrlShortener_ResolveShortUrl_FullMethodName = "/url_shortener.v1.UrlShortener/ResolveShortUrl"
+	UrlShortener_GetStats_FullMethodName        = "/url_shortener.v1.UrlShortener/GetStats"
+)
+
+// UrlShortenerClient is the client API for UrlShortener service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// UrlShortener is the gRPC api for internal services. Calls are authenticated with either the
+// "authorization: Bearer <jwt>" or the "x-api-key: <key>" metadata.
+type UrlShortenerClient interface {
+	CreateShortUrl(ctx context.Context, in *CreateShortUrlRequest, opts ...grpc.CallOption) (*CreateShortUrlResponse, error)
+	// ResolveShortUrl returns the default destination of a short url, it isn't counted as a visit.
+	ResolveShortUrl(ctx context.Context, in *ResolveShortUrlRequest, opts ...grpc.CallOption) (*ResolveShortUrlResponse, error)
+	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error)
+}
+
+type urlShortenerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewUrlShortenerClient(cc grpc.ClientConnInterface) UrlShortenerClient {
+	return &urlShortenerClient{cc}
+}
+
+func (c *urlShortenerClient) CreateShortUrl(ctx context.Context, in *CreateShortUrlRequest, opts ...grpc.CallOption) (*CreateShortUrlResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateShortUrlResponse)
+	err := c.cc.Invoke(ctx, UrlShortener_CreateShortUrl_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *urlShortenerClient) ResolveShortUrl(ctx context.Context, in *ResolveShortUrlRequest, opts ...grpc.CallOption) (*ResolveShortUrlResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResolveShortUrlResponse)
+	err := c.cc.Invoke(ctx, UrlShortener_ResolveShortUrl_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *urlShortenerClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetStatsResponse)
+	err := c.cc.Invoke(ctx, UrlShortener_GetStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UrlShortenerServer is the server API for UrlShortener service.
+// All implementations must embed UnimplementedUrlShortenerServer
+// for forward compatibility.
+//
+// UrlShortener is the gRPC api for internal services. Calls are authenticated with either the
+// "authorization: Bearer <jwt>" or the "x-api-key: <key>" metadata.
+type UrlShortenerServer interface {
+	CreateShortUrl(context.Context, *CreateShortUrlRequest) (*CreateShortUrlResponse, error)
+	// ResolveShortUrl returns the default destination of a short url, it isn't counted as a visit.
+	ResolveShortUrl(context.Context, *ResolveShortUrlRequest) (*ResolveShortUrlResponse, error)
+	GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error)
+	mustEmbedUnimplementedUrlShortenerServer()
+}
+
+// UnimplementedUrlShortenerServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedUrlShortenerServer struct{}
+
+func (UnimplementedUrlShortenerServer) CreateShortUrl(context.Context, *CreateShortUrlRequest) (*CreateShortUrlResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateShortUrl not implemented")
+}
+func (UnimplementedUrlShortenerServer) ResolveShortUrl(context.Context, *ResolveShortUrlRequest) (*ResolveShortUrlResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResolveShortUrl not implemented")
+}
+func (UnimplementedUrlShortenerServer) GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
+}
+func (UnimplementedUrlShortenerServer) mustEmbedUnimplementedUrlShortenerServer() {}
+func (UnimplementedUrlShortenerServer) testEmbeddedByValue()                      {}
+
+// UnsafeUrlShortenerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to UrlShortenerServer will
+// result in compilation errors.
+type UnsafeUrlShortenerServer interface {
+	mustEmbedUnimplementedUrlShortenerServer()
+}
+
+func RegisterUrlShortenerServer(s grpc.ServiceRegistrar, srv UrlShortenerServer) {
+	// If the following call pancis, it indicates UnimplementedUrlShortenerServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&UrlShortener_ServiceDesc, srv)
+}
+
+func _UrlShortener_CreateShortUrl_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateShortUrlRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UrlShortenerServer).CreateShortUrl(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UrlShortener_CreateShortUrl_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UrlShortenerServer).CreateShortUrl(ctx, req.(*CreateShortUrlRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UrlShortener_ResolveShortUrl_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResolveShortUrlRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UrlShortenerServer).ResolveShortUrl(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UrlShortener_ResolveShortUrl_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UrlShortenerServer).ResolveShortUrl(ctx, req.(*ResolveShortUrlRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UrlShortener_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UrlShortenerServer).GetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UrlShortener_GetStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UrlShortenerServer).GetStats(ctx, req.(*GetStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// UrlShortener_ServiceDesc is the grpc.ServiceDesc for UrlShortener service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var UrlShortener_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "url_shortener.v1.UrlShortener",
+	HandlerType: (*UrlShortenerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateShortUrl",
+			Handler:    _UrlShortener_CreateShortUrl_Handler,
+		},
+		{
+			MethodName: "ResolveShortUrl",
+			Handler:    _UrlShortener_ResolveShortUrl_Handler,
+		},
+		{
+			MethodName: "GetStats",
+			Handler:    _UrlShortener_GetStats_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "url_shortener.proto",
+}
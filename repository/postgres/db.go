@@ -30,30 +30,237 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.checkUsernameStmt, err = db.PrepareContext(ctx, checkUsername); err != nil {
 		return nil, fmt.Errorf("error preparing query CheckUsername: %w", err)
 	}
+	if q.claimImportJobStmt, err = db.PrepareContext(ctx, claimImportJob); err != nil {
+		return nil, fmt.Errorf("error preparing query ClaimImportJob: %w", err)
+	}
+	if q.claimLinkChecksStmt, err = db.PrepareContext(ctx, claimLinkChecks); err != nil {
+		return nil, fmt.Errorf("error preparing query ClaimLinkChecks: %w", err)
+	}
+	if q.claimMetadataFetchesStmt, err = db.PrepareContext(ctx, claimMetadataFetches); err != nil {
+		return nil, fmt.Errorf("error preparing query ClaimMetadataFetches: %w", err)
+	}
+	if q.clearFetchedMetadataStmt, err = db.PrepareContext(ctx, clearFetchedMetadata); err != nil {
+		return nil, fmt.Errorf("error preparing query ClearFetchedMetadata: %w", err)
+	}
+	if q.deleteCampaignStmt, err = db.PrepareContext(ctx, deleteCampaign); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteCampaign: %w", err)
+	}
+	if q.deleteDomainStmt, err = db.PrepareContext(ctx, deleteDomain); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteDomain: %w", err)
+	}
+	if q.deleteDomainShortUrlsStmt, err = db.PrepareContext(ctx, deleteDomainShortUrls); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteDomainShortUrls: %w", err)
+	}
+	if q.deleteLinkCheckStmt, err = db.PrepareContext(ctx, deleteLinkCheck); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteLinkCheck: %w", err)
+	}
+	if q.deletePendingDomainClaimsStmt, err = db.PrepareContext(ctx, deletePendingDomainClaims); err != nil {
+		return nil, fmt.Errorf("error preparing query DeletePendingDomainClaims: %w", err)
+	}
+	if q.deleteShortUrlRulesStmt, err = db.PrepareContext(ctx, deleteShortUrlRules); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteShortUrlRules: %w", err)
+	}
+	if q.deleteShortUrlScheduleStmt, err = db.PrepareContext(ctx, deleteShortUrlSchedule); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteShortUrlSchedule: %w", err)
+	}
+	if q.deleteShortUrlVariantsStmt, err = db.PrepareContext(ctx, deleteShortUrlVariants); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteShortUrlVariants: %w", err)
+	}
 	if q.deleteUserByUsernameStmt, err = db.PrepareContext(ctx, deleteUserByUsername); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteUserByUsername: %w", err)
 	}
+	if q.deleteUtmTemplateStmt, err = db.PrepareContext(ctx, deleteUtmTemplate); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteUtmTemplate: %w", err)
+	}
+	if q.disableShortUrlStmt, err = db.PrepareContext(ctx, disableShortUrl); err != nil {
+		return nil, fmt.Errorf("error preparing query DisableShortUrl: %w", err)
+	}
+	if q.enableShortUrlStmt, err = db.PrepareContext(ctx, enableShortUrl); err != nil {
+		return nil, fmt.Errorf("error preparing query EnableShortUrl: %w", err)
+	}
+	if q.extendImportJobClaimStmt, err = db.PrepareContext(ctx, extendImportJobClaim); err != nil {
+		return nil, fmt.Errorf("error preparing query ExtendImportJobClaim: %w", err)
+	}
+	if q.finishImportJobStmt, err = db.PrepareContext(ctx, finishImportJob); err != nil {
+		return nil, fmt.Errorf("error preparing query FinishImportJob: %w", err)
+	}
+	if q.finishLinkCheckStmt, err = db.PrepareContext(ctx, finishLinkCheck); err != nil {
+		return nil, fmt.Errorf("error preparing query FinishLinkCheck: %w", err)
+	}
+	if q.finishMetadataFetchStmt, err = db.PrepareContext(ctx, finishMetadataFetch); err != nil {
+		return nil, fmt.Errorf("error preparing query FinishMetadataFetch: %w", err)
+	}
+	if q.getAbuseReportsStmt, err = db.PrepareContext(ctx, getAbuseReports); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAbuseReports: %w", err)
+	}
+	if q.getCampaignByIdStmt, err = db.PrepareContext(ctx, getCampaignById); err != nil {
+		return nil, fmt.Errorf("error preparing query GetCampaignById: %w", err)
+	}
+	if q.getCampaignByNameStmt, err = db.PrepareContext(ctx, getCampaignByName); err != nil {
+		return nil, fmt.Errorf("error preparing query GetCampaignByName: %w", err)
+	}
+	if q.getCampaignsByUsernameStmt, err = db.PrepareContext(ctx, getCampaignsByUsername); err != nil {
+		return nil, fmt.Errorf("error preparing query GetCampaignsByUsername: %w", err)
+	}
+	if q.getCampaignsStatsStmt, err = db.PrepareContext(ctx, getCampaignsStats); err != nil {
+		return nil, fmt.Errorf("error preparing query GetCampaignsStats: %w", err)
+	}
+	if q.getClaimableImportJobIdsStmt, err = db.PrepareContext(ctx, getClaimableImportJobIds); err != nil {
+		return nil, fmt.Errorf("error preparing query GetClaimableImportJobIds: %w", err)
+	}
+	if q.getDomainStmt, err = db.PrepareContext(ctx, getDomain); err != nil {
+		return nil, fmt.Errorf("error preparing query GetDomain: %w", err)
+	}
+	if q.getDomainForUpdateStmt, err = db.PrepareContext(ctx, getDomainForUpdate); err != nil {
+		return nil, fmt.Errorf("error preparing query GetDomainForUpdate: %w", err)
+	}
+	if q.getDomainsByUsernameStmt, err = db.PrepareContext(ctx, getDomainsByUsername); err != nil {
+		return nil, fmt.Errorf("error preparing query GetDomainsByUsername: %w", err)
+	}
+	if q.getExistingShortUrlsStmt, err = db.PrepareContext(ctx, getExistingShortUrls); err != nil {
+		return nil, fmt.Errorf("error preparing query GetExistingShortUrls: %w", err)
+	}
+	if q.getImportJobStmt, err = db.PrepareContext(ctx, getImportJob); err != nil {
+		return nil, fmt.Errorf("error preparing query GetImportJob: %w", err)
+	}
+	if q.getImportJobBatchesStmt, err = db.PrepareContext(ctx, getImportJobBatches); err != nil {
+		return nil, fmt.Errorf("error preparing query GetImportJobBatches: %w", err)
+	}
+	if q.getImportJobReportStmt, err = db.PrepareContext(ctx, getImportJobReport); err != nil {
+		return nil, fmt.Errorf("error preparing query GetImportJobReport: %w", err)
+	}
 	if q.getLongUrlStmt, err = db.PrepareContext(ctx, getLongUrl); err != nil {
 		return nil, fmt.Errorf("error preparing query GetLongUrl: %w", err)
 	}
+	if q.getShortUrlDailyVisitsStmt, err = db.PrepareContext(ctx, getShortUrlDailyVisits); err != nil {
+		return nil, fmt.Errorf("error preparing query GetShortUrlDailyVisits: %w", err)
+	}
+	if q.getShortUrlForUpdateStmt, err = db.PrepareContext(ctx, getShortUrlForUpdate); err != nil {
+		return nil, fmt.Errorf("error preparing query GetShortUrlForUpdate: %w", err)
+	}
 	if q.getShortUrlLengthStmt, err = db.PrepareContext(ctx, getShortUrlLength); err != nil {
 		return nil, fmt.Errorf("error preparing query GetShortUrlLength: %w", err)
 	}
+	if q.getShortUrlMetadataStmt, err = db.PrepareContext(ctx, getShortUrlMetadata); err != nil {
+		return nil, fmt.Errorf("error preparing query GetShortUrlMetadata: %w", err)
+	}
+	if q.getShortUrlOwnerStmt, err = db.PrepareContext(ctx, getShortUrlOwner); err != nil {
+		return nil, fmt.Errorf("error preparing query GetShortUrlOwner: %w", err)
+	}
+	if q.getShortUrlPreviewStmt, err = db.PrepareContext(ctx, getShortUrlPreview); err != nil {
+		return nil, fmt.Errorf("error preparing query GetShortUrlPreview: %w", err)
+	}
+	if q.getShortUrlRulesStmt, err = db.PrepareContext(ctx, getShortUrlRules); err != nil {
+		return nil, fmt.Errorf("error preparing query GetShortUrlRules: %w", err)
+	}
+	if q.getShortUrlScheduleStmt, err = db.PrepareContext(ctx, getShortUrlSchedule); err != nil {
+		return nil, fmt.Errorf("error preparing query GetShortUrlSchedule: %w", err)
+	}
+	if q.getShortUrlSourceVisitsStmt, err = db.PrepareContext(ctx, getShortUrlSourceVisits); err != nil {
+		return nil, fmt.Errorf("error preparing query GetShortUrlSourceVisits: %w", err)
+	}
+	if q.getShortUrlStatsStmt, err = db.PrepareContext(ctx, getShortUrlStats); err != nil {
+		return nil, fmt.Errorf("error preparing query GetShortUrlStats: %w", err)
+	}
+	if q.getShortUrlVariantVisitsStmt, err = db.PrepareContext(ctx, getShortUrlVariantVisits); err != nil {
+		return nil, fmt.Errorf("error preparing query GetShortUrlVariantVisits: %w", err)
+	}
+	if q.getShortUrlVariantsStmt, err = db.PrepareContext(ctx, getShortUrlVariants); err != nil {
+		return nil, fmt.Errorf("error preparing query GetShortUrlVariants: %w", err)
+	}
+	if q.getShortUrlsByUsernameStmt, err = db.PrepareContext(ctx, getShortUrlsByUsername); err != nil {
+		return nil, fmt.Errorf("error preparing query GetShortUrlsByUsername: %w", err)
+	}
+	if q.getShortUrlsForListingStmt, err = db.PrepareContext(ctx, getShortUrlsForListing); err != nil {
+		return nil, fmt.Errorf("error preparing query GetShortUrlsForListing: %w", err)
+	}
+	if q.getShortUrlsStatsStmt, err = db.PrepareContext(ctx, getShortUrlsStats); err != nil {
+		return nil, fmt.Errorf("error preparing query GetShortUrlsStats: %w", err)
+	}
 	if q.getUserByUsernameStmt, err = db.PrepareContext(ctx, getUserByUsername); err != nil {
 		return nil, fmt.Errorf("error preparing query GetUserByUsername: %w", err)
 	}
+	if q.getUserRoleStmt, err = db.PrepareContext(ctx, getUserRole); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUserRole: %w", err)
+	}
+	if q.getUsersStmt, err = db.PrepareContext(ctx, getUsers); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUsers: %w", err)
+	}
+	if q.getUtmTemplateByIdStmt, err = db.PrepareContext(ctx, getUtmTemplateById); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUtmTemplateById: %w", err)
+	}
+	if q.getUtmTemplateByNameStmt, err = db.PrepareContext(ctx, getUtmTemplateByName); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUtmTemplateByName: %w", err)
+	}
+	if q.getUtmTemplatesByUsernameStmt, err = db.PrepareContext(ctx, getUtmTemplatesByUsername); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUtmTemplatesByUsername: %w", err)
+	}
+	if q.getVerifiedDomainOwnerStmt, err = db.PrepareContext(ctx, getVerifiedDomainOwner); err != nil {
+		return nil, fmt.Errorf("error preparing query GetVerifiedDomainOwner: %w", err)
+	}
+	if q.getVerifiedDomainsStmt, err = db.PrepareContext(ctx, getVerifiedDomains); err != nil {
+		return nil, fmt.Errorf("error preparing query GetVerifiedDomains: %w", err)
+	}
 	if q.incrementShortUrlLengthStmt, err = db.PrepareContext(ctx, incrementShortUrlLength); err != nil {
 		return nil, fmt.Errorf("error preparing query IncrementShortUrlLength: %w", err)
 	}
+	if q.insertAbuseReportStmt, err = db.PrepareContext(ctx, insertAbuseReport); err != nil {
+		return nil, fmt.Errorf("error preparing query InsertAbuseReport: %w", err)
+	}
+	if q.insertCampaignStmt, err = db.PrepareContext(ctx, insertCampaign); err != nil {
+		return nil, fmt.Errorf("error preparing query InsertCampaign: %w", err)
+	}
+	if q.insertDomainStmt, err = db.PrepareContext(ctx, insertDomain); err != nil {
+		return nil, fmt.Errorf("error preparing query InsertDomain: %w", err)
+	}
+	if q.insertImportJobStmt, err = db.PrepareContext(ctx, insertImportJob); err != nil {
+		return nil, fmt.Errorf("error preparing query InsertImportJob: %w", err)
+	}
+	if q.insertImportJobBatchStmt, err = db.PrepareContext(ctx, insertImportJobBatch); err != nil {
+		return nil, fmt.Errorf("error preparing query InsertImportJobBatch: %w", err)
+	}
 	if q.insertShortUrlStmt, err = db.PrepareContext(ctx, insertShortUrl); err != nil {
 		return nil, fmt.Errorf("error preparing query InsertShortUrl: %w", err)
 	}
+	if q.insertShortUrlRulesStmt, err = db.PrepareContext(ctx, insertShortUrlRules); err != nil {
+		return nil, fmt.Errorf("error preparing query InsertShortUrlRules: %w", err)
+	}
+	if q.insertShortUrlScheduleStmt, err = db.PrepareContext(ctx, insertShortUrlSchedule); err != nil {
+		return nil, fmt.Errorf("error preparing query InsertShortUrlSchedule: %w", err)
+	}
+	if q.insertShortUrlVariantsStmt, err = db.PrepareContext(ctx, insertShortUrlVariants); err != nil {
+		return nil, fmt.Errorf("error preparing query InsertShortUrlVariants: %w", err)
+	}
+	if q.insertShortUrlsStmt, err = db.PrepareContext(ctx, insertShortUrls); err != nil {
+		return nil, fmt.Errorf("error preparing query InsertShortUrls: %w", err)
+	}
 	if q.insertUrlVisitsStmt, err = db.PrepareContext(ctx, insertUrlVisits); err != nil {
 		return nil, fmt.Errorf("error preparing query InsertUrlVisits: %w", err)
 	}
 	if q.insertUserStmt, err = db.PrepareContext(ctx, insertUser); err != nil {
 		return nil, fmt.Errorf("error preparing query InsertUser: %w", err)
 	}
+	if q.insertUtmTemplateStmt, err = db.PrepareContext(ctx, insertUtmTemplate); err != nil {
+		return nil, fmt.Errorf("error preparing query InsertUtmTemplate: %w", err)
+	}
+	if q.resolveAbuseReportStmt, err = db.PrepareContext(ctx, resolveAbuseReport); err != nil {
+		return nil, fmt.Errorf("error preparing query ResolveAbuseReport: %w", err)
+	}
+	if q.resolveShortUrlAbuseReportsStmt, err = db.PrepareContext(ctx, resolveShortUrlAbuseReports); err != nil {
+		return nil, fmt.Errorf("error preparing query ResolveShortUrlAbuseReports: %w", err)
+	}
+	if q.setShortUrlCustomMetadataStmt, err = db.PrepareContext(ctx, setShortUrlCustomMetadata); err != nil {
+		return nil, fmt.Errorf("error preparing query SetShortUrlCustomMetadata: %w", err)
+	}
+	if q.setUserRoleStmt, err = db.PrepareContext(ctx, setUserRole); err != nil {
+		return nil, fmt.Errorf("error preparing query SetUserRole: %w", err)
+	}
+	if q.updateShortUrlStmt, err = db.PrepareContext(ctx, updateShortUrl); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateShortUrl: %w", err)
+	}
+	if q.verifyDomainStmt, err = db.PrepareContext(ctx, verifyDomain); err != nil {
+		return nil, fmt.Errorf("error preparing query VerifyDomain: %w", err)
+	}
 	return &q, nil
 }
 
@@ -69,36 +276,346 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing checkUsernameStmt: %w", cerr)
 		}
 	}
+	if q.claimImportJobStmt != nil {
+		if cerr := q.claimImportJobStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing claimImportJobStmt: %w", cerr)
+		}
+	}
+	if q.claimLinkChecksStmt != nil {
+		if cerr := q.claimLinkChecksStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing claimLinkChecksStmt: %w", cerr)
+		}
+	}
+	if q.claimMetadataFetchesStmt != nil {
+		if cerr := q.claimMetadataFetchesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing claimMetadataFetchesStmt: %w", cerr)
+		}
+	}
+	if q.clearFetchedMetadataStmt != nil {
+		if cerr := q.clearFetchedMetadataStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing clearFetchedMetadataStmt: %w", cerr)
+		}
+	}
+	if q.deleteCampaignStmt != nil {
+		if cerr := q.deleteCampaignStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteCampaignStmt: %w", cerr)
+		}
+	}
+	if q.deleteDomainStmt != nil {
+		if cerr := q.deleteDomainStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteDomainStmt: %w", cerr)
+		}
+	}
+	if q.deleteDomainShortUrlsStmt != nil {
+		if cerr := q.deleteDomainShortUrlsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteDomainShortUrlsStmt: %w", cerr)
+		}
+	}
+	if q.deleteLinkCheckStmt != nil {
+		if cerr := q.deleteLinkCheckStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteLinkCheckStmt: %w", cerr)
+		}
+	}
+	if q.deletePendingDomainClaimsStmt != nil {
+		if cerr := q.deletePendingDomainClaimsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deletePendingDomainClaimsStmt: %w", cerr)
+		}
+	}
+	if q.deleteShortUrlRulesStmt != nil {
+		if cerr := q.deleteShortUrlRulesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteShortUrlRulesStmt: %w", cerr)
+		}
+	}
+	if q.deleteShortUrlScheduleStmt != nil {
+		if cerr := q.deleteShortUrlScheduleStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteShortUrlScheduleStmt: %w", cerr)
+		}
+	}
+	if q.deleteShortUrlVariantsStmt != nil {
+		if cerr := q.deleteShortUrlVariantsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteShortUrlVariantsStmt: %w", cerr)
+		}
+	}
 	if q.deleteUserByUsernameStmt != nil {
 		if cerr := q.deleteUserByUsernameStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteUserByUsernameStmt: %w", cerr)
 		}
 	}
+	if q.deleteUtmTemplateStmt != nil {
+		if cerr := q.deleteUtmTemplateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteUtmTemplateStmt: %w", cerr)
+		}
+	}
+	if q.disableShortUrlStmt != nil {
+		if cerr := q.disableShortUrlStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing disableShortUrlStmt: %w", cerr)
+		}
+	}
+	if q.enableShortUrlStmt != nil {
+		if cerr := q.enableShortUrlStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing enableShortUrlStmt: %w", cerr)
+		}
+	}
+	if q.extendImportJobClaimStmt != nil {
+		if cerr := q.extendImportJobClaimStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing extendImportJobClaimStmt: %w", cerr)
+		}
+	}
+	if q.finishImportJobStmt != nil {
+		if cerr := q.finishImportJobStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing finishImportJobStmt: %w", cerr)
+		}
+	}
+	if q.finishLinkCheckStmt != nil {
+		if cerr := q.finishLinkCheckStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing finishLinkCheckStmt: %w", cerr)
+		}
+	}
+	if q.finishMetadataFetchStmt != nil {
+		if cerr := q.finishMetadataFetchStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing finishMetadataFetchStmt: %w", cerr)
+		}
+	}
+	if q.getAbuseReportsStmt != nil {
+		if cerr := q.getAbuseReportsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAbuseReportsStmt: %w", cerr)
+		}
+	}
+	if q.getCampaignByIdStmt != nil {
+		if cerr := q.getCampaignByIdStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getCampaignByIdStmt: %w", cerr)
+		}
+	}
+	if q.getCampaignByNameStmt != nil {
+		if cerr := q.getCampaignByNameStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getCampaignByNameStmt: %w", cerr)
+		}
+	}
+	if q.getCampaignsByUsernameStmt != nil {
+		if cerr := q.getCampaignsByUsernameStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getCampaignsByUsernameStmt: %w", cerr)
+		}
+	}
+	if q.getCampaignsStatsStmt != nil {
+		if cerr := q.getCampaignsStatsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getCampaignsStatsStmt: %w", cerr)
+		}
+	}
+	if q.getClaimableImportJobIdsStmt != nil {
+		if cerr := q.getClaimableImportJobIdsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getClaimableImportJobIdsStmt: %w", cerr)
+		}
+	}
+	if q.getDomainStmt != nil {
+		if cerr := q.getDomainStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getDomainStmt: %w", cerr)
+		}
+	}
+	if q.getDomainForUpdateStmt != nil {
+		if cerr := q.getDomainForUpdateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getDomainForUpdateStmt: %w", cerr)
+		}
+	}
+	if q.getDomainsByUsernameStmt != nil {
+		if cerr := q.getDomainsByUsernameStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getDomainsByUsernameStmt: %w", cerr)
+		}
+	}
+	if q.getExistingShortUrlsStmt != nil {
+		if cerr := q.getExistingShortUrlsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getExistingShortUrlsStmt: %w", cerr)
+		}
+	}
+	if q.getImportJobStmt != nil {
+		if cerr := q.getImportJobStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getImportJobStmt: %w", cerr)
+		}
+	}
+	if q.getImportJobBatchesStmt != nil {
+		if cerr := q.getImportJobBatchesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getImportJobBatchesStmt: %w", cerr)
+		}
+	}
+	if q.getImportJobReportStmt != nil {
+		if cerr := q.getImportJobReportStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getImportJobReportStmt: %w", cerr)
+		}
+	}
 	if q.getLongUrlStmt != nil {
 		if cerr := q.getLongUrlStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getLongUrlStmt: %w", cerr)
 		}
 	}
+	if q.getShortUrlDailyVisitsStmt != nil {
+		if cerr := q.getShortUrlDailyVisitsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getShortUrlDailyVisitsStmt: %w", cerr)
+		}
+	}
+	if q.getShortUrlForUpdateStmt != nil {
+		if cerr := q.getShortUrlForUpdateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getShortUrlForUpdateStmt: %w", cerr)
+		}
+	}
 	if q.getShortUrlLengthStmt != nil {
 		if cerr := q.getShortUrlLengthStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getShortUrlLengthStmt: %w", cerr)
 		}
 	}
+	if q.getShortUrlMetadataStmt != nil {
+		if cerr := q.getShortUrlMetadataStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getShortUrlMetadataStmt: %w", cerr)
+		}
+	}
+	if q.getShortUrlOwnerStmt != nil {
+		if cerr := q.getShortUrlOwnerStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getShortUrlOwnerStmt: %w", cerr)
+		}
+	}
+	if q.getShortUrlPreviewStmt != nil {
+		if cerr := q.getShortUrlPreviewStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getShortUrlPreviewStmt: %w", cerr)
+		}
+	}
+	if q.getShortUrlRulesStmt != nil {
+		if cerr := q.getShortUrlRulesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getShortUrlRulesStmt: %w", cerr)
+		}
+	}
+	if q.getShortUrlScheduleStmt != nil {
+		if cerr := q.getShortUrlScheduleStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getShortUrlScheduleStmt: %w", cerr)
+		}
+	}
+	if q.getShortUrlSourceVisitsStmt != nil {
+		if cerr := q.getShortUrlSourceVisitsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getShortUrlSourceVisitsStmt: %w", cerr)
+		}
+	}
+	if q.getShortUrlStatsStmt != nil {
+		if cerr := q.getShortUrlStatsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getShortUrlStatsStmt: %w", cerr)
+		}
+	}
+	if q.getShortUrlVariantVisitsStmt != nil {
+		if cerr := q.getShortUrlVariantVisitsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getShortUrlVariantVisitsStmt: %w", cerr)
+		}
+	}
+	if q.getShortUrlVariantsStmt != nil {
+		if cerr := q.getShortUrlVariantsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getShortUrlVariantsStmt: %w", cerr)
+		}
+	}
+	if q.getShortUrlsByUsernameStmt != nil {
+		if cerr := q.getShortUrlsByUsernameStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getShortUrlsByUsernameStmt: %w", cerr)
+		}
+	}
+	if q.getShortUrlsForListingStmt != nil {
+		if cerr := q.getShortUrlsForListingStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getShortUrlsForListingStmt: %w", cerr)
+		}
+	}
+	if q.getShortUrlsStatsStmt != nil {
+		if cerr := q.getShortUrlsStatsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getShortUrlsStatsStmt: %w", cerr)
+		}
+	}
 	if q.getUserByUsernameStmt != nil {
 		if cerr := q.getUserByUsernameStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getUserByUsernameStmt: %w", cerr)
 		}
 	}
+	if q.getUserRoleStmt != nil {
+		if cerr := q.getUserRoleStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUserRoleStmt: %w", cerr)
+		}
+	}
+	if q.getUsersStmt != nil {
+		if cerr := q.getUsersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUsersStmt: %w", cerr)
+		}
+	}
+	if q.getUtmTemplateByIdStmt != nil {
+		if cerr := q.getUtmTemplateByIdStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUtmTemplateByIdStmt: %w", cerr)
+		}
+	}
+	if q.getUtmTemplateByNameStmt != nil {
+		if cerr := q.getUtmTemplateByNameStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUtmTemplateByNameStmt: %w", cerr)
+		}
+	}
+	if q.getUtmTemplatesByUsernameStmt != nil {
+		if cerr := q.getUtmTemplatesByUsernameStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUtmTemplatesByUsernameStmt: %w", cerr)
+		}
+	}
+	if q.getVerifiedDomainOwnerStmt != nil {
+		if cerr := q.getVerifiedDomainOwnerStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getVerifiedDomainOwnerStmt: %w", cerr)
+		}
+	}
+	if q.getVerifiedDomainsStmt != nil {
+		if cerr := q.getVerifiedDomainsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getVerifiedDomainsStmt: %w", cerr)
+		}
+	}
 	if q.incrementShortUrlLengthStmt != nil {
 		if cerr := q.incrementShortUrlLengthStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing incrementShortUrlLengthStmt: %w", cerr)
 		}
 	}
+	if q.insertAbuseReportStmt != nil {
+		if cerr := q.insertAbuseReportStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing insertAbuseReportStmt: %w", cerr)
+		}
+	}
+	if q.insertCampaignStmt != nil {
+		if cerr := q.insertCampaignStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing insertCampaignStmt: %w", cerr)
+		}
+	}
+	if q.insertDomainStmt != nil {
+		if cerr := q.insertDomainStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing insertDomainStmt: %w", cerr)
+		}
+	}
+	if q.insertImportJobStmt != nil {
+		if cerr := q.insertImportJobStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing insertImportJobStmt: %w", cerr)
+		}
+	}
+	if q.insertImportJobBatchStmt != nil {
+		if cerr := q.insertImportJobBatchStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing insertImportJobBatchStmt: %w", cerr)
+		}
+	}
 	if q.insertShortUrlStmt != nil {
 		if cerr := q.insertShortUrlStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing insertShortUrlStmt: %w", cerr)
 		}
 	}
+	if q.insertShortUrlRulesStmt != nil {
+		if cerr := q.insertShortUrlRulesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing insertShortUrlRulesStmt: %w", cerr)
+		}
+	}
+	if q.insertShortUrlScheduleStmt != nil {
+		if cerr := q.insertShortUrlScheduleStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing insertShortUrlScheduleStmt: %w", cerr)
+		}
+	}
+	if q.insertShortUrlVariantsStmt != nil {
+		if cerr := q.insertShortUrlVariantsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing insertShortUrlVariantsStmt: %w", cerr)
+		}
+	}
+	if q.insertShortUrlsStmt != nil {
+		if cerr := q.insertShortUrlsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing insertShortUrlsStmt: %w", cerr)
+		}
+	}
 	if q.insertUrlVisitsStmt != nil {
 		if cerr := q.insertUrlVisitsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing insertUrlVisitsStmt: %w", cerr)
@@ -109,6 +626,41 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing insertUserStmt: %w", cerr)
 		}
 	}
+	if q.insertUtmTemplateStmt != nil {
+		if cerr := q.insertUtmTemplateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing insertUtmTemplateStmt: %w", cerr)
+		}
+	}
+	if q.resolveAbuseReportStmt != nil {
+		if cerr := q.resolveAbuseReportStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing resolveAbuseReportStmt: %w", cerr)
+		}
+	}
+	if q.resolveShortUrlAbuseReportsStmt != nil {
+		if cerr := q.resolveShortUrlAbuseReportsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing resolveShortUrlAbuseReportsStmt: %w", cerr)
+		}
+	}
+	if q.setShortUrlCustomMetadataStmt != nil {
+		if cerr := q.setShortUrlCustomMetadataStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setShortUrlCustomMetadataStmt: %w", cerr)
+		}
+	}
+	if q.setUserRoleStmt != nil {
+		if cerr := q.setUserRoleStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setUserRoleStmt: %w", cerr)
+		}
+	}
+	if q.updateShortUrlStmt != nil {
+		if cerr := q.updateShortUrlStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateShortUrlStmt: %w", cerr)
+		}
+	}
+	if q.verifyDomainStmt != nil {
+		if cerr := q.verifyDomainStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing verifyDomainStmt: %w", cerr)
+		}
+	}
 	return err
 }
 
@@ -146,33 +698,171 @@ func (q *Queries) queryRow(ctx context.Context, stmt *sql.Stmt, query string, ar
 }
 
 type Queries struct {
-	db                          DBTX
-	tx                          *sql.Tx
-	checkShortUrlStmt           *sql.Stmt
-	checkUsernameStmt           *sql.Stmt
-	deleteUserByUsernameStmt    *sql.Stmt
-	getLongUrlStmt              *sql.Stmt
-	getShortUrlLengthStmt       *sql.Stmt
-	getUserByUsernameStmt       *sql.Stmt
-	incrementShortUrlLengthStmt *sql.Stmt
-	insertShortUrlStmt          *sql.Stmt
-	insertUrlVisitsStmt         *sql.Stmt
-	insertUserStmt              *sql.Stmt
+	db                              DBTX
+	tx                              *sql.Tx
+	checkShortUrlStmt               *sql.Stmt
+	checkUsernameStmt               *sql.Stmt
+	claimImportJobStmt              *sql.Stmt
+	claimLinkChecksStmt             *sql.Stmt
+	claimMetadataFetchesStmt        *sql.Stmt
+	clearFetchedMetadataStmt        *sql.Stmt
+	deleteCampaignStmt              *sql.Stmt
+	deleteDomainStmt                *sql.Stmt
+	deleteDomainShortUrlsStmt       *sql.Stmt
+	deleteLinkCheckStmt             *sql.Stmt
+	deletePendingDomainClaimsStmt   *sql.Stmt
+	deleteShortUrlRulesStmt         *sql.Stmt
+	deleteShortUrlScheduleStmt      *sql.Stmt
+	deleteShortUrlVariantsStmt      *sql.Stmt
+	deleteUserByUsernameStmt        *sql.Stmt
+	deleteUtmTemplateStmt           *sql.Stmt
+	disableShortUrlStmt             *sql.Stmt
+	enableShortUrlStmt              *sql.Stmt
+	extendImportJobClaimStmt        *sql.Stmt
+	finishImportJobStmt             *sql.Stmt
+	finishLinkCheckStmt             *sql.Stmt
+	finishMetadataFetchStmt         *sql.Stmt
+	getAbuseReportsStmt             *sql.Stmt
+	getCampaignByIdStmt             *sql.Stmt
+	getCampaignByNameStmt           *sql.Stmt
+	getCampaignsByUsernameStmt      *sql.Stmt
+	getCampaignsStatsStmt           *sql.Stmt
+	getClaimableImportJobIdsStmt    *sql.Stmt
+	getDomainStmt                   *sql.Stmt
+	getDomainForUpdateStmt          *sql.Stmt
+	getDomainsByUsernameStmt        *sql.Stmt
+	getExistingShortUrlsStmt        *sql.Stmt
+	getImportJobStmt                *sql.Stmt
+	getImportJobBatchesStmt         *sql.Stmt
+	getImportJobReportStmt          *sql.Stmt
+	getLongUrlStmt                  *sql.Stmt
+	getShortUrlDailyVisitsStmt      *sql.Stmt
+	getShortUrlForUpdateStmt        *sql.Stmt
+	getShortUrlLengthStmt           *sql.Stmt
+	getShortUrlMetadataStmt         *sql.Stmt
+	getShortUrlOwnerStmt            *sql.Stmt
+	getShortUrlPreviewStmt          *sql.Stmt
+	getShortUrlRulesStmt            *sql.Stmt
+	getShortUrlScheduleStmt         *sql.Stmt
+	getShortUrlSourceVisitsStmt     *sql.Stmt
+	getShortUrlStatsStmt            *sql.Stmt
+	getShortUrlVariantVisitsStmt    *sql.Stmt
+	getShortUrlVariantsStmt         *sql.Stmt
+	getShortUrlsByUsernameStmt      *sql.Stmt
+	getShortUrlsForListingStmt      *sql.Stmt
+	getShortUrlsStatsStmt           *sql.Stmt
+	getUserByUsernameStmt           *sql.Stmt
+	getUserRoleStmt                 *sql.Stmt
+	getUsersStmt                    *sql.Stmt
+	getUtmTemplateByIdStmt          *sql.Stmt
+	getUtmTemplateByNameStmt        *sql.Stmt
+	getUtmTemplatesByUsernameStmt   *sql.Stmt
+	getVerifiedDomainOwnerStmt      *sql.Stmt
+	getVerifiedDomainsStmt          *sql.Stmt
+	incrementShortUrlLengthStmt     *sql.Stmt
+	insertAbuseReportStmt           *sql.Stmt
+	insertCampaignStmt              *sql.Stmt
+	insertDomainStmt                *sql.Stmt
+	insertImportJobStmt             *sql.Stmt
+	insertImportJobBatchStmt        *sql.Stmt
+	insertShortUrlStmt              *sql.Stmt
+	insertShortUrlRulesStmt         *sql.Stmt
+	insertShortUrlScheduleStmt      *sql.Stmt
+	insertShortUrlVariantsStmt      *sql.Stmt
+	insertShortUrlsStmt             *sql.Stmt
+	insertUrlVisitsStmt             *sql.Stmt
+	insertUserStmt                  *sql.Stmt
+	insertUtmTemplateStmt           *sql.Stmt
+	resolveAbuseReportStmt          *sql.Stmt
+	resolveShortUrlAbuseReportsStmt *sql.Stmt
+	setShortUrlCustomMetadataStmt   *sql.Stmt
+	setUserRoleStmt                 *sql.Stmt
+	updateShortUrlStmt              *sql.Stmt
+	verifyDomainStmt                *sql.Stmt
 }
 
 func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 	return &Queries{
-		db:                          tx,
-		tx:                          tx,
-		checkShortUrlStmt:           q.checkShortUrlStmt,
-		checkUsernameStmt:           q.checkUsernameStmt,
-		deleteUserByUsernameStmt:    q.deleteUserByUsernameStmt,
-		getLongUrlStmt:              q.getLongUrlStmt,
-		getShortUrlLengthStmt:       q.getShortUrlLengthStmt,
-		getUserByUsernameStmt:       q.getUserByUsernameStmt,
-		incrementShortUrlLengthStmt: q.incrementShortUrlLengthStmt,
-		insertShortUrlStmt:          q.insertShortUrlStmt,
-		insertUrlVisitsStmt:         q.insertUrlVisitsStmt,
-		insertUserStmt:              q.insertUserStmt,
+		db:                              tx,
+		tx:                              tx,
+		checkShortUrlStmt:               q.checkShortUrlStmt,
+		checkUsernameStmt:               q.checkUsernameStmt,
+		claimImportJobStmt:              q.claimImportJobStmt,
+		claimLinkChecksStmt:             q.claimLinkChecksStmt,
+		claimMetadataFetchesStmt:        q.claimMetadataFetchesStmt,
+		clearFetchedMetadataStmt:        q.clearFetchedMetadataStmt,
+		deleteCampaignStmt:              q.deleteCampaignStmt,
+		deleteDomainStmt:                q.deleteDomainStmt,
+		deleteDomainShortUrlsStmt:       q.deleteDomainShortUrlsStmt,
+		deleteLinkCheckStmt:             q.deleteLinkCheckStmt,
+		deletePendingDomainClaimsStmt:   q.deletePendingDomainClaimsStmt,
+		deleteShortUrlRulesStmt:         q.deleteShortUrlRulesStmt,
+		deleteShortUrlScheduleStmt:      q.deleteShortUrlScheduleStmt,
+		deleteShortUrlVariantsStmt:      q.deleteShortUrlVariantsStmt,
+		deleteUserByUsernameStmt:        q.deleteUserByUsernameStmt,
+		deleteUtmTemplateStmt:           q.deleteUtmTemplateStmt,
+		disableShortUrlStmt:             q.disableShortUrlStmt,
+		enableShortUrlStmt:              q.enableShortUrlStmt,
+		extendImportJobClaimStmt:        q.extendImportJobClaimStmt,
+		finishImportJobStmt:             q.finishImportJobStmt,
+		finishLinkCheckStmt:             q.finishLinkCheckStmt,
+		finishMetadataFetchStmt:         q.finishMetadataFetchStmt,
+		getAbuseReportsStmt:             q.getAbuseReportsStmt,
+		getCampaignByIdStmt:             q.getCampaignByIdStmt,
+		getCampaignByNameStmt:           q.getCampaignByNameStmt,
+		getCampaignsByUsernameStmt:      q.getCampaignsByUsernameStmt,
+		getCampaignsStatsStmt:           q.getCampaignsStatsStmt,
+		getClaimableImportJobIdsStmt:    q.getClaimableImportJobIdsStmt,
+		getDomainStmt:                   q.getDomainStmt,
+		getDomainForUpdateStmt:          q.getDomainForUpdateStmt,
+		getDomainsByUsernameStmt:        q.getDomainsByUsernameStmt,
+		getExistingShortUrlsStmt:        q.getExistingShortUrlsStmt,
+		getImportJobStmt:                q.getImportJobStmt,
+		getImportJobBatchesStmt:         q.getImportJobBatchesStmt,
+		getImportJobReportStmt:          q.getImportJobReportStmt,
+		getLongUrlStmt:                  q.getLongUrlStmt,
+		getShortUrlDailyVisitsStmt:      q.getShortUrlDailyVisitsStmt,
+		getShortUrlForUpdateStmt:        q.getShortUrlForUpdateStmt,
+		getShortUrlLengthStmt:           q.getShortUrlLengthStmt,
+		getShortUrlMetadataStmt:         q.getShortUrlMetadataStmt,
+		getShortUrlOwnerStmt:            q.getShortUrlOwnerStmt,
+		getShortUrlPreviewStmt:          q.getShortUrlPreviewStmt,
+		getShortUrlRulesStmt:            q.getShortUrlRulesStmt,
+		getShortUrlScheduleStmt:         q.getShortUrlScheduleStmt,
+		getShortUrlSourceVisitsStmt:     q.getShortUrlSourceVisitsStmt,
+		getShortUrlStatsStmt:            q.getShortUrlStatsStmt,
+		getShortUrlVariantVisitsStmt:    q.getShortUrlVariantVisitsStmt,
+		getShortUrlVariantsStmt:         q.getShortUrlVariantsStmt,
+		getShortUrlsByUsernameStmt:      q.getShortUrlsByUsernameStmt,
+		getShortUrlsForListingStmt:      q.getShortUrlsForListingStmt,
+		getShortUrlsStatsStmt:           q.getShortUrlsStatsStmt,
+		getUserByUsernameStmt:           q.getUserByUsernameStmt,
+		getUserRoleStmt:                 q.getUserRoleStmt,
+		getUsersStmt:                    q.getUsersStmt,
+		getUtmTemplateByIdStmt:          q.getUtmTemplateByIdStmt,
+		getUtmTemplateByNameStmt:        q.getUtmTemplateByNameStmt,
+		getUtmTemplatesByUsernameStmt:   q.getUtmTemplatesByUsernameStmt,
+		getVerifiedDomainOwnerStmt:      q.getVerifiedDomainOwnerStmt,
+		getVerifiedDomainsStmt:          q.getVerifiedDomainsStmt,
+		incrementShortUrlLengthStmt:     q.incrementShortUrlLengthStmt,
+		insertAbuseReportStmt:           q.insertAbuseReportStmt,
+		insertCampaignStmt:              q.insertCampaignStmt,
+		insertDomainStmt:                q.insertDomainStmt,
+		insertImportJobStmt:             q.insertImportJobStmt,
+		insertImportJobBatchStmt:        q.insertImportJobBatchStmt,
+		insertShortUrlStmt:              q.insertShortUrlStmt,
+		insertShortUrlRulesStmt:         q.insertShortUrlRulesStmt,
+		insertShortUrlScheduleStmt:      q.insertShortUrlScheduleStmt,
+		insertShortUrlVariantsStmt:      q.insertShortUrlVariantsStmt,
+		insertShortUrlsStmt:             q.insertShortUrlsStmt,
+		insertUrlVisitsStmt:             q.insertUrlVisitsStmt,
+		insertUserStmt:                  q.insertUserStmt,
+		insertUtmTemplateStmt:           q.insertUtmTemplateStmt,
+		resolveAbuseReportStmt:          q.resolveAbuseReportStmt,
+		resolveShortUrlAbuseReportsStmt: q.resolveShortUrlAbuseReportsStmt,
+		setShortUrlCustomMetadataStmt:   q.setShortUrlCustomMetadataStmt,
+		setUserRoleStmt:                 q.setUserRoleStmt,
+		updateShortUrlStmt:              q.updateShortUrlStmt,
+		verifyDomainStmt:                q.verifyDomainStmt,
 	}
 }
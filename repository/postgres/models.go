@@ -5,14 +5,90 @@
 package postgres_repo
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 )
 
+type AbuseReport struct {
+	ID         int64
+	ShortUrl   string
+	Reason     string
+	Details    string
+	ReporterIp string
+	Status     string
+	CreatedAt  time.Time
+	ResolvedAt sql.NullTime
+	ResolvedBy string
+	Domain     string
+}
+
+type Campaign struct {
+	ID            int64
+	Username      string
+	Name          string
+	UtmTemplateID sql.NullInt64
+	CreatedAt     time.Time
+}
+
+type Domain struct {
+	Domain            string
+	Username          string
+	VerificationToken string
+	VerifiedAt        sql.NullTime
+	CreatedAt         time.Time
+}
+
+type ImportJob struct {
+	ID           int64
+	Username     string
+	Status       string
+	OnConflict   string
+	Items        json.RawMessage
+	Report       json.RawMessage
+	Total        int32
+	Created      int32
+	Skipped      int32
+	Failed       int32
+	Error        string
+	CreatedAt    time.Time
+	FinishedAt   sql.NullTime
+	ClaimedUntil sql.NullTime
+	Domain       string
+}
+
+type ImportJobBatch struct {
+	JobID      int64
+	StartIndex int32
+	Report     json.RawMessage
+}
+
+type LinkCheck struct {
+	ShortUrl    string
+	CheckedUrl  string
+	StatusCode  int32
+	FinalUrl    string
+	Error       string
+	Broken      bool
+	CheckedAt   sql.NullTime
+	NextCheckAt time.Time
+	Domain      string
+}
+
 type ShortUrl struct {
-	Username  string
-	LongUrl   string
-	ShortUrl  string
-	CreatedAt time.Time
+	Username        string
+	LongUrl         string
+	ShortUrl        string
+	CreatedAt       time.Time
+	RedirectStatus  int32
+	CachePolicy     string
+	ForwardPath     bool
+	QueryForwarding string
+	CampaignID      sql.NullInt64
+	Interstitial    bool
+	DisabledAt      sql.NullTime
+	DisabledReason  string
+	Domain          string
 }
 
 type ShortUrlLength struct {
@@ -20,14 +96,72 @@ type ShortUrlLength struct {
 	LastUpdate time.Time
 }
 
+type ShortUrlMetadatum struct {
+	ShortUrl          string
+	FetchedUrl        string
+	Title             string
+	Description       string
+	ImageUrl          string
+	FetchError        string
+	FetchedAt         sql.NullTime
+	ClaimedUntil      sql.NullTime
+	CustomTitle       sql.NullString
+	CustomDescription sql.NullString
+	CustomImageUrl    sql.NullString
+	Domain            string
+}
+
+type ShortUrlRule struct {
+	ShortUrl  string
+	Position  int32
+	Device    string
+	Os        string
+	Language  string
+	LongUrl   string
+	Countries []string
+	Domain    string
+}
+
+type ShortUrlSchedule struct {
+	ShortUrl string
+	At       time.Time
+	State    string
+	LongUrl  string
+	Domain   string
+}
+
+type ShortUrlVariant struct {
+	ShortUrl string
+	Name     string
+	LongUrl  string
+	Weight   int32
+	Domain   string
+}
+
 type UrlVisit struct {
 	ShortUrl  string
 	VisitorIp string
 	VisitedAt time.Time
+	Variant   string
+	Source    string
+	Domain    string
 }
 
 type User struct {
 	Username       string
 	HashedPassword string
 	CreatedAt      time.Time
+	Role           string
+}
+
+type UtmTemplate struct {
+	ID          int64
+	Username    string
+	Name        string
+	UtmSource   string
+	UtmMedium   string
+	UtmCampaign string
+	UtmTerm     string
+	UtmContent  string
+	CreatedAt   time.Time
 }
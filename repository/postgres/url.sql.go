@@ -7,29 +7,145 @@ package postgres_repo
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 const checkShortUrl = `-- name: CheckShortUrl :one
-select exists (select 1 from short_urls where short_url = $1 for update)
+select exists (select 1 from short_urls where domain = $1 and short_url = $2 for update)
 `
 
-func (q *Queries) CheckShortUrl(ctx context.Context, shortUrl string) (bool, error) {
-	row := q.queryRow(ctx, q.checkShortUrlStmt, checkShortUrl, shortUrl)
+type CheckShortUrlParams struct {
+	Domain   string
+	ShortUrl string
+}
+
+func (q *Queries) CheckShortUrl(ctx context.Context, arg CheckShortUrlParams) (bool, error) {
+	row := q.queryRow(ctx, q.checkShortUrlStmt, checkShortUrl, arg.Domain, arg.ShortUrl)
 	var exists bool
 	err := row.Scan(&exists)
 	return exists, err
 }
 
+const getExistingShortUrls = `-- name: GetExistingShortUrls :many
+select short_url from short_urls where domain = $1 and short_url = any($2::varchar[]) for update
+`
+
+type GetExistingShortUrlsParams struct {
+	Domain    string
+	ShortUrls []string
+}
+
+func (q *Queries) GetExistingShortUrls(ctx context.Context, arg GetExistingShortUrlsParams) ([]string, error) {
+	rows, err := q.query(ctx, q.getExistingShortUrlsStmt, getExistingShortUrls, arg.Domain, pq.Array(arg.ShortUrls))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var short_url string
+		if err := rows.Scan(&short_url); err != nil {
+			return nil, err
+		}
+		items = append(items, short_url)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getLongUrl = `-- name: GetLongUrl :one
-select long_url from short_urls where short_url = $1
+select
+    s.long_url,
+    s.redirect_status,
+    s.cache_policy,
+    s.forward_path,
+    s.query_forwarding,
+    s.interstitial,
+    (s.disabled_at is not null)::boolean as disabled,
+    s.disabled_reason,
+    coalesce(m.custom_title, m.title, '')::varchar as title,
+    coalesce(m.custom_description, m.description, '')::varchar as description,
+    coalesce(m.custom_image_url, m.image_url, '')::varchar as image_url
+from short_urls s
+left join short_url_metadata m on m.domain = s.domain and m.short_url = s.short_url
+where s.domain = $1 and s.short_url = $2
 `
 
-func (q *Queries) GetLongUrl(ctx context.Context, shortUrl string) (string, error) {
-	row := q.queryRow(ctx, q.getLongUrlStmt, getLongUrl, shortUrl)
-	var long_url string
-	err := row.Scan(&long_url)
-	return long_url, err
+type GetLongUrlParams struct {
+	Domain   string
+	ShortUrl string
+}
+
+type GetLongUrlRow struct {
+	LongUrl         string
+	RedirectStatus  int32
+	CachePolicy     string
+	ForwardPath     bool
+	QueryForwarding string
+	Interstitial    bool
+	Disabled        bool
+	DisabledReason  string
+	Title           string
+	Description     string
+	ImageUrl        string
+}
+
+func (q *Queries) GetLongUrl(ctx context.Context, arg GetLongUrlParams) (GetLongUrlRow, error) {
+	row := q.queryRow(ctx, q.getLongUrlStmt, getLongUrl, arg.Domain, arg.ShortUrl)
+	var i GetLongUrlRow
+	err := row.Scan(
+		&i.LongUrl,
+		&i.RedirectStatus,
+		&i.CachePolicy,
+		&i.ForwardPath,
+		&i.QueryForwarding,
+		&i.Interstitial,
+		&i.Disabled,
+		&i.DisabledReason,
+		&i.Title,
+		&i.Description,
+		&i.ImageUrl,
+	)
+	return i, err
+}
+
+const getShortUrlForUpdate = `-- name: GetShortUrlForUpdate :one
+select username, long_url, short_url, created_at, redirect_status, cache_policy, forward_path, query_forwarding, campaign_id, interstitial, disabled_at, disabled_reason, domain from short_urls where domain = $1 and short_url = $2 for update
+`
+
+type GetShortUrlForUpdateParams struct {
+	Domain   string
+	ShortUrl string
+}
+
+func (q *Queries) GetShortUrlForUpdate(ctx context.Context, arg GetShortUrlForUpdateParams) (ShortUrl, error) {
+	row := q.queryRow(ctx, q.getShortUrlForUpdateStmt, getShortUrlForUpdate, arg.Domain, arg.ShortUrl)
+	var i ShortUrl
+	err := row.Scan(
+		&i.Username,
+		&i.LongUrl,
+		&i.ShortUrl,
+		&i.CreatedAt,
+		&i.RedirectStatus,
+		&i.CachePolicy,
+		&i.ForwardPath,
+		&i.QueryForwarding,
+		&i.CampaignID,
+		&i.Interstitial,
+		&i.DisabledAt,
+		&i.DisabledReason,
+		&i.Domain,
+	)
+	return i, err
 }
 
 const getShortUrlLength = `-- name: GetShortUrlLength :one
@@ -43,6 +159,220 @@ func (q *Queries) GetShortUrlLength(ctx context.Context) (int32, error) {
 	return length, err
 }
 
+const getShortUrlOwner = `-- name: GetShortUrlOwner :one
+select username from short_urls where domain = $1 and short_url = $2
+`
+
+type GetShortUrlOwnerParams struct {
+	Domain   string
+	ShortUrl string
+}
+
+func (q *Queries) GetShortUrlOwner(ctx context.Context, arg GetShortUrlOwnerParams) (string, error) {
+	row := q.queryRow(ctx, q.getShortUrlOwnerStmt, getShortUrlOwner, arg.Domain, arg.ShortUrl)
+	var username string
+	err := row.Scan(&username)
+	return username, err
+}
+
+const getShortUrlPreview = `-- name: GetShortUrlPreview :one
+select
+    s.username,
+    s.long_url,
+    s.created_at,
+    (s.disabled_at is not null)::boolean as disabled,
+    s.disabled_reason,
+    (select count(*) from url_visits v where v.domain = s.domain and v.short_url = s.short_url) as visits,
+    (exists (select 1 from short_url_rules r where r.domain = s.domain and r.short_url = s.short_url)
+        or exists (select 1 from short_url_variants r where r.domain = s.domain and r.short_url = s.short_url))::boolean as has_other_destinations
+from short_urls s
+where s.domain = $1 and s.short_url = $2
+`
+
+type GetShortUrlPreviewParams struct {
+	Domain   string
+	ShortUrl string
+}
+
+type GetShortUrlPreviewRow struct {
+	Username             string
+	LongUrl              string
+	CreatedAt            time.Time
+	Disabled             bool
+	DisabledReason       string
+	Visits               int64
+	HasOtherDestinations bool
+}
+
+func (q *Queries) GetShortUrlPreview(ctx context.Context, arg GetShortUrlPreviewParams) (GetShortUrlPreviewRow, error) {
+	row := q.queryRow(ctx, q.getShortUrlPreviewStmt, getShortUrlPreview, arg.Domain, arg.ShortUrl)
+	var i GetShortUrlPreviewRow
+	err := row.Scan(
+		&i.Username,
+		&i.LongUrl,
+		&i.CreatedAt,
+		&i.Disabled,
+		&i.DisabledReason,
+		&i.Visits,
+		&i.HasOtherDestinations,
+	)
+	return i, err
+}
+
+const getShortUrlsByUsername = `-- name: GetShortUrlsByUsername :many
+select
+    s.short_url,
+    s.long_url,
+    s.created_at,
+    (case
+        when $1::boolean then (select count(*) from url_visits v where v.domain = s.domain and v.short_url = s.short_url)
+        else 0
+    end)::bigint as visits
+from short_urls s
+where s.username = $2 and s.domain = $3 and s.short_url > $4
+order by s.short_url
+limit $5
+`
+
+type GetShortUrlsByUsernameParams struct {
+	WithVisits    bool
+	Username      string
+	Domain        string
+	AfterShortUrl string
+	PageSize      int32
+}
+
+type GetShortUrlsByUsernameRow struct {
+	ShortUrl  string
+	LongUrl   string
+	CreatedAt time.Time
+	Visits    int64
+}
+
+func (q *Queries) GetShortUrlsByUsername(ctx context.Context, arg GetShortUrlsByUsernameParams) ([]GetShortUrlsByUsernameRow, error) {
+	rows, err := q.query(ctx, q.getShortUrlsByUsernameStmt, getShortUrlsByUsername,
+		arg.WithVisits,
+		arg.Username,
+		arg.Domain,
+		arg.AfterShortUrl,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetShortUrlsByUsernameRow{}
+	for rows.Next() {
+		var i GetShortUrlsByUsernameRow
+		if err := rows.Scan(
+			&i.ShortUrl,
+			&i.LongUrl,
+			&i.CreatedAt,
+			&i.Visits,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getShortUrlsForListing = `-- name: GetShortUrlsForListing :many
+select
+    s.short_url,
+    s.long_url,
+    s.created_at,
+    (s.disabled_at is not null)::boolean as disabled,
+    coalesce(m.custom_title, m.title, '')::varchar as title,
+    coalesce(m.custom_description, m.description, '')::varchar as description,
+    coalesce(m.custom_image_url, m.image_url, '')::varchar as image_url,
+    coalesce(c.status_code, 0)::int as status_code,
+    coalesce(c.final_url, '')::varchar as final_url,
+    coalesce(c.error, '')::varchar as error,
+    coalesce(c.broken, false)::boolean as broken,
+    c.checked_at
+from short_urls s
+left join link_checks c on c.domain = s.domain and c.short_url = s.short_url and c.checked_url = s.long_url
+left join short_url_metadata m on m.domain = s.domain and m.short_url = s.short_url
+where s.username = $1
+    and s.domain = $2
+    and s.short_url > $3
+    and (not $4::boolean or c.broken)
+order by s.short_url
+limit $5
+`
+
+type GetShortUrlsForListingParams struct {
+	Username      string
+	Domain        string
+	AfterShortUrl string
+	BrokenOnly    bool
+	PageSize      int32
+}
+
+type GetShortUrlsForListingRow struct {
+	ShortUrl    string
+	LongUrl     string
+	CreatedAt   time.Time
+	Disabled    bool
+	Title       string
+	Description string
+	ImageUrl    string
+	StatusCode  int32
+	FinalUrl    string
+	Error       string
+	Broken      bool
+	CheckedAt   sql.NullTime
+}
+
+func (q *Queries) GetShortUrlsForListing(ctx context.Context, arg GetShortUrlsForListingParams) ([]GetShortUrlsForListingRow, error) {
+	rows, err := q.query(ctx, q.getShortUrlsForListingStmt, getShortUrlsForListing,
+		arg.Username,
+		arg.Domain,
+		arg.AfterShortUrl,
+		arg.BrokenOnly,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetShortUrlsForListingRow{}
+	for rows.Next() {
+		var i GetShortUrlsForListingRow
+		if err := rows.Scan(
+			&i.ShortUrl,
+			&i.LongUrl,
+			&i.CreatedAt,
+			&i.Disabled,
+			&i.Title,
+			&i.Description,
+			&i.ImageUrl,
+			&i.StatusCode,
+			&i.FinalUrl,
+			&i.Error,
+			&i.Broken,
+			&i.CheckedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const incrementShortUrlLength = `-- name: IncrementShortUrlLength :one
 update short_url_length 
 set 
@@ -59,30 +389,95 @@ func (q *Queries) IncrementShortUrlLength(ctx context.Context) (int32, error) {
 }
 
 const insertShortUrl = `-- name: InsertShortUrl :exec
-insert into short_urls (username, long_url, short_url)
-values ($1, $2, $3)
+insert into short_urls (username, domain, long_url, short_url, redirect_status, cache_policy, forward_path, query_forwarding, campaign_id, interstitial)
+values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 `
 
 type InsertShortUrlParams struct {
-	Username string
-	LongUrl  string
-	ShortUrl string
+	Username        string
+	Domain          string
+	LongUrl         string
+	ShortUrl        string
+	RedirectStatus  int32
+	CachePolicy     string
+	ForwardPath     bool
+	QueryForwarding string
+	CampaignID      sql.NullInt64
+	Interstitial    bool
 }
 
 func (q *Queries) InsertShortUrl(ctx context.Context, arg InsertShortUrlParams) error {
-	_, err := q.exec(ctx, q.insertShortUrlStmt, insertShortUrl, arg.Username, arg.LongUrl, arg.ShortUrl)
+	_, err := q.exec(ctx, q.insertShortUrlStmt, insertShortUrl,
+		arg.Username,
+		arg.Domain,
+		arg.LongUrl,
+		arg.ShortUrl,
+		arg.RedirectStatus,
+		arg.CachePolicy,
+		arg.ForwardPath,
+		arg.QueryForwarding,
+		arg.CampaignID,
+		arg.Interstitial,
+	)
 	return err
 }
 
+const insertShortUrls = `-- name: InsertShortUrls :many
+with short_urls_data as (
+    select jsonb_array_elements($1::jsonb) as v
+)
+insert into short_urls (username, domain, long_url, short_url, redirect_status, cache_policy, forward_path, query_forwarding, campaign_id, interstitial)
+select
+    v ->> 'username',
+    v ->> 'domain',
+    v ->> 'longUrl',
+    v ->> 'shortUrl',
+    (v ->> 'redirectStatus')::int,
+    v ->> 'cachePolicy',
+    (v ->> 'forwardPath')::boolean,
+    v ->> 'queryForwarding',
+    (v ->> 'campaignId')::bigint,
+    (v ->> 'interstitial')::boolean
+from short_urls_data
+on conflict (domain, short_url) do nothing
+returning short_url
+`
+
+func (q *Queries) InsertShortUrls(ctx context.Context, jsonShortUrls json.RawMessage) ([]string, error) {
+	rows, err := q.query(ctx, q.insertShortUrlsStmt, insertShortUrls, jsonShortUrls)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var short_url string
+		if err := rows.Scan(&short_url); err != nil {
+			return nil, err
+		}
+		items = append(items, short_url)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const insertUrlVisits = `-- name: InsertUrlVisits :exec
 with visits_data as (
     select jsonb_array_elements($1::jsonb) as v
 )
-insert into url_visits (short_url, visitor_ip, visited_at) 
+insert into url_visits (domain, short_url, visitor_ip, visited_at, variant, source) 
 select 
+    v ->> 'domain',
     v ->> 'shortUrl',
     v ->> 'visitorIp',
-    (v ->> 'visitedAt')::timestamp
+    (v ->> 'visitedAt')::timestamp,
+    coalesce(v ->> 'variant', ''),
+    coalesce(v ->> 'source', '')
 from visits_data
 `
 
@@ -90,3 +485,40 @@ func (q *Queries) InsertUrlVisits(ctx context.Context, jsonVisits json.RawMessag
 	_, err := q.exec(ctx, q.insertUrlVisitsStmt, insertUrlVisits, jsonVisits)
 	return err
 }
+
+const updateShortUrl = `-- name: UpdateShortUrl :exec
+update short_urls
+set
+    long_url = $3,
+    redirect_status = $4,
+    cache_policy = $5,
+    forward_path = $6,
+    query_forwarding = $7,
+    interstitial = $8
+where domain = $1 and short_url = $2
+`
+
+type UpdateShortUrlParams struct {
+	Domain          string
+	ShortUrl        string
+	LongUrl         string
+	RedirectStatus  int32
+	CachePolicy     string
+	ForwardPath     bool
+	QueryForwarding string
+	Interstitial    bool
+}
+
+func (q *Queries) UpdateShortUrl(ctx context.Context, arg UpdateShortUrlParams) error {
+	_, err := q.exec(ctx, q.updateShortUrlStmt, updateShortUrl,
+		arg.Domain,
+		arg.ShortUrl,
+		arg.LongUrl,
+		arg.RedirectStatus,
+		arg.CachePolicy,
+		arg.ForwardPath,
+		arg.QueryForwarding,
+		arg.Interstitial,
+	)
+	return err
+}
@@ -7,6 +7,7 @@ package postgres_repo
 
 import (
 	"context"
+	"time"
 )
 
 const checkUsername = `-- name: CheckUsername :one
@@ -33,29 +34,104 @@ func (q *Queries) DeleteUserByUsername(ctx context.Context, username string) (in
 }
 
 const getUserByUsername = `-- name: GetUserByUsername :one
-select username, hashed_password, created_at from users where username = $1
+select username, hashed_password, created_at, role from users where username = $1
 `
 
 func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User, error) {
 	row := q.queryRow(ctx, q.getUserByUsernameStmt, getUserByUsername, username)
 	var i User
-	err := row.Scan(&i.Username, &i.HashedPassword, &i.CreatedAt)
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.CreatedAt,
+		&i.Role,
+	)
 	return i, err
 }
 
+const getUserRole = `-- name: GetUserRole :one
+select role from users where username = $1
+`
+
+func (q *Queries) GetUserRole(ctx context.Context, username string) (string, error) {
+	row := q.queryRow(ctx, q.getUserRoleStmt, getUserRole, username)
+	var role string
+	err := row.Scan(&role)
+	return role, err
+}
+
+const getUsers = `-- name: GetUsers :many
+select username, role, created_at from users
+where username > $1
+order by username
+limit $2
+`
+
+type GetUsersParams struct {
+	AfterUsername string
+	PageSize      int32
+}
+
+type GetUsersRow struct {
+	Username  string
+	Role      string
+	CreatedAt time.Time
+}
+
+func (q *Queries) GetUsers(ctx context.Context, arg GetUsersParams) ([]GetUsersRow, error) {
+	rows, err := q.query(ctx, q.getUsersStmt, getUsers, arg.AfterUsername, arg.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetUsersRow{}
+	for rows.Next() {
+		var i GetUsersRow
+		if err := rows.Scan(&i.Username, &i.Role, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const insertUser = `-- name: InsertUser :execrows
-insert into users (username, hashed_password)
-values ($1, $2)
+insert into users (username, hashed_password, role)
+values ($1, $2, $3)
 on conflict (username) do nothing
 `
 
 type InsertUserParams struct {
 	Username       string
 	HashedPassword string
+	Role           string
 }
 
 func (q *Queries) InsertUser(ctx context.Context, arg InsertUserParams) (int64, error) {
-	result, err := q.exec(ctx, q.insertUserStmt, insertUser, arg.Username, arg.HashedPassword)
+	result, err := q.exec(ctx, q.insertUserStmt, insertUser, arg.Username, arg.HashedPassword, arg.Role)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setUserRole = `-- name: SetUserRole :execrows
+update users set role = $2 where username = $1
+`
+
+type SetUserRoleParams struct {
+	Username string
+	Role     string
+}
+
+func (q *Queries) SetUserRole(ctx context.Context, arg SetUserRoleParams) (int64, error) {
+	result, err := q.exec(ctx, q.setUserRoleStmt, setUserRole, arg.Username, arg.Role)
 	if err != nil {
 		return 0, err
 	}
@@ -0,0 +1,79 @@
+package rpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/assaidy/url_shortener/services"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	authorizationMetadata = "authorization"
+	apiKeyMetadata        = "x-api-key"
+)
+
+type authedUsernameKey struct{}
+
+func authedUsername(ctx context.Context) string {
+	return ctx.Value(authedUsernameKey{}).(string)
+}
+
+func hashApiKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// parseApiKeys parses "key=username" pairs into a map of hashed keys to usernames.
+func parseApiKeys(pairs []string) (map[string]string, error) {
+	apiKeys := map[string]string{}
+	for _, it := range pairs {
+		key, username, ok := strings.Cut(it, "=")
+		if !ok || key == "" || username == "" {
+			return nil, fmt.Errorf("invalid api key, expected key=username")
+		}
+		apiKeys[hashApiKey(key)] = username
+	}
+	return apiKeys, nil
+}
+
+// authInterceptor authenticates calls with the x-api-key or authorization metadata, like WithJwt
+// does for http requests.
+func (me *Server) authInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	username := ""
+	if keys := md.Get(apiKeyMetadata); len(keys) != 0 {
+		var ok bool
+		if username, ok = me.apiKeys[hashApiKey(keys[0])]; !ok {
+			return nil, status.Error(codes.Unauthenticated, "invalid api key")
+		}
+	} else if values := md.Get(authorizationMetadata); len(values) != 0 {
+		tokenString := strings.TrimSpace(strings.TrimPrefix(values[0], "Bearer"))
+		claims, err := services.UserServiceInstance.ParseJwtTokenString(tokenString)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+		if time.Until(claims.ExpiresAt.Time) <= 0 {
+			return nil, status.Error(codes.Unauthenticated, "expired token")
+		}
+		username = claims.Username
+	} else {
+		return nil, status.Error(codes.Unauthenticated, "missing authorization or x-api-key metadata")
+	}
+
+	if ok, err := services.UserServiceInstance.CheckUsername(ctx, username); err != nil {
+		return nil, fromServiceError(err)
+	} else if !ok { // user was deleted before token expiration, or after its key was configured
+		return nil, status.Error(codes.Unauthenticated, "user not found")
+	}
+
+	return handler(context.WithValue(ctx, authedUsernameKey{}, username), req)
+}
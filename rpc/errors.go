@@ -0,0 +1,73 @@
+package rpc
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"unicode"
+
+	"github.com/assaidy/url_shortener/services"
+	"github.com/assaidy/url_shortener/utils"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fromServiceError mirrors the http fromServiceError with grpc status codes. Invalid fields of
+// validation errors are sent as BadRequest details.
+func fromServiceError(err error) error {
+	is := func(serviceErr error) bool {
+		return errors.Is(err, serviceErr)
+	}
+
+	code := codes.Internal
+	switch {
+	case is(services.ConflictErr):
+		code = codes.AlreadyExists
+	case is(services.NotFoundErr):
+		code = codes.NotFound
+	case is(services.UnauthorizedErr):
+		code = codes.Unauthenticated
+	case is(services.ValidationErr):
+		code = codes.InvalidArgument
+	case is(services.UnprocessableErr):
+		code = codes.FailedPrecondition
+	case is(services.ForbiddenErr):
+		code = codes.PermissionDenied
+	}
+
+	// internal errors aren't exposed to clients
+	if code == codes.Internal {
+		slog.Error("error handling grpc call", "err", err)
+		return status.Error(codes.Internal, "internal error")
+	}
+
+	st := status.New(code, err.Error())
+	var fieldErrs utils.ValidationErrors
+	if errors.As(err, &fieldErrs) {
+		badRequest := &errdetails.BadRequest{}
+		for _, it := range fieldErrs {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       snakeCase(it.Field),
+				Description: "violation in constraint '" + it.Constraint + "'",
+			})
+		}
+		if withDetails, err := st.WithDetails(badRequest); err == nil {
+			st = withDetails
+		}
+	}
+	return st.Err()
+}
+
+// snakeCase turns field paths like rules[0].longUrl into the names of proto fields, rules[0].long_url.
+func snakeCase(field string) string {
+	var b strings.Builder
+	for _, r := range field {
+		if unicode.IsUpper(r) {
+			b.WriteByte('_')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
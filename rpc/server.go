@@ -0,0 +1,150 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"time"
+
+	"github.com/assaidy/url_shortener/config"
+	"github.com/assaidy/url_shortener/pb"
+	"github.com/assaidy/url_shortener/services"
+	"github.com/assaidy/url_shortener/utils"
+	"github.com/valyala/fasthttp/reuseport"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var ServerInstance = &Server{}
+
+// Server serves the gRPC api on config.Cfg.Grpc.Addr. Its listener uses SO_REUSEPORT like the prefork
+// children of fiber, so each process serves a share of the calls.
+type Server struct {
+	pb.UnimplementedUrlShortenerServer
+
+	server  *grpc.Server
+	apiKeys map[string]string // sha256 of the keys to their usernames
+}
+
+func (me *Server) Start() error {
+	if config.Cfg.Grpc.Addr == "" {
+		return nil
+	}
+
+	apiKeys, err := parseApiKeys(config.Cfg.Grpc.ApiKeys)
+	if err != nil {
+		return fmt.Errorf("error parsing grpc api keys: %w", err)
+	}
+	me.apiKeys = apiKeys
+
+	listener, err := reuseport.Listen(listenNetwork(config.Cfg.Grpc.Addr), config.Cfg.Grpc.Addr)
+	if err != nil {
+		return fmt.Errorf("error listening on grpc addr: %w", err)
+	}
+
+	me.server = grpc.NewServer(grpc.UnaryInterceptor(me.authInterceptor))
+	pb.RegisterUrlShortenerServer(me.server, me)
+
+	go func() {
+		if err := me.server.Serve(listener); err != nil {
+			slog.Error("error serving grpc", "err", err, "PID", os.Getpid())
+		}
+	}()
+
+	return nil
+}
+
+// listenNetwork picks tcp6 for ipv6 addrs, reuseport only listens on tcp4 or tcp6.
+func listenNetwork(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "tcp4"
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.To4() == nil {
+		return "tcp6"
+	}
+	return "tcp4"
+}
+
+func (me *Server) Stop() {
+	if me.server == nil {
+		return
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		me.server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		me.server.Stop()
+	}
+}
+
+func (me *Server) CreateShortUrl(ctx context.Context, req *pb.CreateShortUrlRequest) (*pb.CreateShortUrlResponse, error) {
+	domain := utils.NormalizeDomain(req.Domain)
+	shortUrl, err := services.UrlServiceInstance.CreateShortUrl(ctx, services.CreateShortUrlParams{
+		Username:       authedUsername(ctx),
+		Domain:         domain,
+		LongUrl:        req.LongUrl,
+		ShortUrl:       req.ShortUrl,
+		RedirectStatus: int(req.RedirectStatus),
+		ForwardPath:    req.ForwardPath,
+		Campaign:       req.Campaign,
+		UtmTemplate:    req.UtmTemplate,
+		Interstitial:   req.Interstitial,
+	})
+	if err != nil {
+		return nil, fromServiceError(err)
+	}
+
+	return &pb.CreateShortUrlResponse{Domain: domain, ShortUrl: shortUrl}, nil
+}
+
+func (me *Server) ResolveShortUrl(ctx context.Context, req *pb.ResolveShortUrlRequest) (*pb.ResolveShortUrlResponse, error) {
+	info, err := services.UrlServiceInstance.GetLongUrl(ctx, utils.NormalizeDomain(req.Domain), req.ShortUrl)
+	if err != nil {
+		return nil, fromServiceError(err)
+	}
+
+	return &pb.ResolveShortUrlResponse{
+		LongUrl:        info.LongUrl,
+		RedirectStatus: int32(info.RedirectStatus),
+		Disabled:       info.Disabled,
+		DisabledReason: info.DisabledReason,
+		State:          info.State,
+	}, nil
+}
+
+func (me *Server) GetStats(ctx context.Context, req *pb.GetStatsRequest) (*pb.GetStatsResponse, error) {
+	var statsRange services.StatsRange
+	if req.From != nil {
+		statsRange.From = req.From.AsTime()
+	}
+	if req.To != nil {
+		statsRange.To = req.To.AsTime()
+	}
+
+	stats, err := services.AnalyticsServiceInstance.GetShortUrlStats(ctx, authedUsername(ctx), utils.NormalizeDomain(req.Domain), req.ShortUrl, statsRange)
+	if err != nil {
+		return nil, fromServiceError(err)
+	}
+
+	dailyVisits := make([]*pb.DailyVisits, len(stats.DailyVisits))
+	for i, it := range stats.DailyVisits {
+		dailyVisits[i] = &pb.DailyVisits{Day: timestamppb.New(it.Day), Visits: it.Visits}
+	}
+
+	return &pb.GetStatsResponse{
+		ShortUrl:       stats.ShortUrl,
+		LongUrl:        stats.LongUrl,
+		Campaign:       stats.Campaign,
+		Visits:         stats.Visits,
+		UniqueVisitors: stats.UniqueVisitors,
+		DailyVisits:    dailyVisits,
+	}, nil
+}
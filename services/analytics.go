@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/assaidy/url_shortener/db/postgres"
+	"github.com/assaidy/url_shortener/repository/postgres"
+)
+
+var AnalyticsServiceInstance = &AnalyticsService{}
+
+// AnalyticsService reports visits of short urls to their owners.
+type AnalyticsService struct {
+	db      *sql.DB
+	queries *postgres_repo.Queries
+}
+
+func (me *AnalyticsService) Start() error {
+	me.db = postgres_db.DB
+	me.queries = postgres_repo.New(me.db)
+
+	return nil
+}
+
+func (me *AnalyticsService) Stop() {}
+
+// StatsRange limits stats to visits in [From, To). Zero values leave that side open.
+type StatsRange struct {
+	From time.Time
+	To   time.Time
+}
+
+func (me StatsRange) bounds() (time.Time, time.Time) {
+	from, to := me.From, me.To
+	if from.IsZero() {
+		from = time.Unix(0, 0).UTC()
+	}
+	if to.IsZero() {
+		to = time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return from.UTC(), to.UTC()
+}
+
+type ShortUrlStats struct {
+	ShortUrl       string         `json:"shortUrl"`
+	LongUrl        string         `json:"longUrl,omitempty"`
+	Campaign       string         `json:"campaign,omitempty"`
+	Visits         int64          `json:"visits"`
+	UniqueVisitors int64          `json:"uniqueVisitors"`
+	DailyVisits    []DailyStats   `json:"dailyVisits,omitempty"`
+	Variants       []VariantStats `json:"variants,omitempty"`
+	Sources        []SourceStats  `json:"sources,omitempty"`
+}
+
+type VariantStats struct {
+	Name           string `json:"name"`
+	Visits         int64  `json:"visits"`
+	UniqueVisitors int64  `json:"uniqueVisitors"`
+}
+
+// SourceStats counts visits by how visitors got the short url, e.g. by scanning its QR code.
+type SourceStats struct {
+	Source         string `json:"source"`
+	Visits         int64  `json:"visits"`
+	UniqueVisitors int64  `json:"uniqueVisitors"`
+}
+
+type DailyStats struct {
+	Day    time.Time `json:"day"`
+	Visits int64     `json:"visits"`
+}
+
+type GetShortUrlsStatsParams struct {
+	Username string
+	Domain   string
+	Campaign string // only short urls of this campaign when set
+	Range    StatsRange
+}
+
+func (me *AnalyticsService) GetShortUrlsStats(ctx context.Context, params GetShortUrlsStatsParams) ([]ShortUrlStats, error) {
+	from, to := params.Range.bounds()
+
+	rows, err := me.queries.GetShortUrlsStats(ctx, postgres_repo.GetShortUrlsStatsParams{
+		FromTime: from,
+		ToTime:   to,
+		Username: params.Username,
+		Domain:   params.Domain,
+		Campaign: params.Campaign,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting short urls stats: %w", err)
+	}
+
+	stats := make([]ShortUrlStats, len(rows))
+	for i, it := range rows {
+		stats[i] = ShortUrlStats{
+			ShortUrl:       it.ShortUrl,
+			LongUrl:        it.LongUrl,
+			Campaign:       it.Campaign,
+			Visits:         it.Visits,
+			UniqueVisitors: it.UniqueVisitors,
+		}
+	}
+
+	return stats, nil
+}
+
+// GetShortUrlStats returns the stats of a short url owned by username, with its visits per day,
+// per split test variant and per source.
+func (me *AnalyticsService) GetShortUrlStats(ctx context.Context, username string, domain string, shortUrl string, statsRange StatsRange) (ShortUrlStats, error) {
+	if err := checkShortUrlOwner(ctx, me.queries, username, domain, shortUrl); err != nil {
+		return ShortUrlStats{}, err
+	}
+
+	from, to := statsRange.bounds()
+
+	totals, err := me.queries.GetShortUrlStats(ctx, postgres_repo.GetShortUrlStatsParams{
+		Domain:   domain,
+		ShortUrl: shortUrl,
+		FromTime: from,
+		ToTime:   to,
+	})
+	if err != nil {
+		return ShortUrlStats{}, fmt.Errorf("error getting short url stats: %w", err)
+	}
+
+	daily, err := me.queries.GetShortUrlDailyVisits(ctx, postgres_repo.GetShortUrlDailyVisitsParams{
+		Domain:   domain,
+		ShortUrl: shortUrl,
+		FromTime: from,
+		ToTime:   to,
+	})
+	if err != nil {
+		return ShortUrlStats{}, fmt.Errorf("error getting short url daily visits: %w", err)
+	}
+
+	variants, err := me.queries.GetShortUrlVariantVisits(ctx, postgres_repo.GetShortUrlVariantVisitsParams{
+		Domain:   domain,
+		ShortUrl: shortUrl,
+		FromTime: from,
+		ToTime:   to,
+	})
+	if err != nil {
+		return ShortUrlStats{}, fmt.Errorf("error getting short url variant visits: %w", err)
+	}
+
+	sources, err := me.queries.GetShortUrlSourceVisits(ctx, postgres_repo.GetShortUrlSourceVisitsParams{
+		Domain:   domain,
+		ShortUrl: shortUrl,
+		FromTime: from,
+		ToTime:   to,
+	})
+	if err != nil {
+		return ShortUrlStats{}, fmt.Errorf("error getting short url source visits: %w", err)
+	}
+
+	stats := ShortUrlStats{
+		ShortUrl:       shortUrl,
+		Visits:         totals.Visits,
+		UniqueVisitors: totals.UniqueVisitors,
+		DailyVisits:    make([]DailyStats, len(daily)),
+		Variants:       make([]VariantStats, len(variants)),
+		Sources:        make([]SourceStats, len(sources)),
+	}
+	for i, it := range daily {
+		stats.DailyVisits[i] = DailyStats{Day: it.Day, Visits: it.Visits}
+	}
+	for i, it := range variants {
+		stats.Variants[i] = VariantStats{Name: it.Variant, Visits: it.Visits, UniqueVisitors: it.UniqueVisitors}
+	}
+	for i, it := range sources {
+		stats.Sources[i] = SourceStats{Source: it.Source, Visits: it.Visits, UniqueVisitors: it.UniqueVisitors}
+	}
+
+	return stats, nil
+}
+
+type CampaignStats struct {
+	Name           string `json:"name"`
+	ShortUrls      int64  `json:"shortUrls"`
+	Visits         int64  `json:"visits"`
+	UniqueVisitors int64  `json:"uniqueVisitors"`
+}
+
+func (me *AnalyticsService) GetCampaignsStats(ctx context.Context, username string, statsRange StatsRange) ([]CampaignStats, error) {
+	from, to := statsRange.bounds()
+
+	rows, err := me.queries.GetCampaignsStats(ctx, postgres_repo.GetCampaignsStatsParams{
+		FromTime: from,
+		ToTime:   to,
+		Username: username,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting campaigns stats: %w", err)
+	}
+
+	stats := make([]CampaignStats, len(rows))
+	for i, it := range rows {
+		stats[i] = CampaignStats{
+			Name:           it.Name,
+			ShortUrls:      it.ShortUrls,
+			Visits:         it.Visits,
+			UniqueVisitors: it.UniqueVisitors,
+		}
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/assaidy/url_shortener/db/postgres"
+	"github.com/assaidy/url_shortener/repository/postgres"
+	"github.com/assaidy/url_shortener/utils"
+)
+
+var CampaignServiceInstance = &CampaignService{}
+
+// CampaignService manages reusable utm templates and the campaigns that group short urls.
+type CampaignService struct {
+	db      *sql.DB
+	queries *postgres_repo.Queries
+}
+
+func (me *CampaignService) Start() error {
+	me.db = postgres_db.DB
+	me.queries = postgres_repo.New(me.db)
+
+	return nil
+}
+
+func (me *CampaignService) Stop() {}
+
+type UtmTemplate struct {
+	Name        string    `json:"name"`
+	UtmSource   string    `json:"utmSource"`
+	UtmMedium   string    `json:"utmMedium"`
+	UtmCampaign string    `json:"utmCampaign"`
+	UtmTerm     string    `json:"utmTerm"`
+	UtmContent  string    `json:"utmContent"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type CreateUtmTemplateParams struct {
+	Username    string `validate:"required"`
+	Name        string `validate:"required,customSlug,max=50"`
+	UtmSource   string `validate:"required,customNoOuterSpaces,max=100"`
+	UtmMedium   string `validate:"customNoOuterSpaces,max=100"`
+	UtmCampaign string `validate:"customNoOuterSpaces,max=100"`
+	UtmTerm     string `validate:"customNoOuterSpaces,max=100"`
+	UtmContent  string `validate:"customNoOuterSpaces,max=100"`
+}
+
+func (me *CampaignService) CreateUtmTemplate(ctx context.Context, params CreateUtmTemplateParams) error {
+	if err := utils.ValidateStruct(params); err != nil {
+		return fmt.Errorf("%w: %w", ValidationErr, err)
+	}
+
+	numAffectedRows, err := me.queries.InsertUtmTemplate(ctx, postgres_repo.InsertUtmTemplateParams{
+		Username:    params.Username,
+		Name:        params.Name,
+		UtmSource:   params.UtmSource,
+		UtmMedium:   params.UtmMedium,
+		UtmCampaign: params.UtmCampaign,
+		UtmTerm:     params.UtmTerm,
+		UtmContent:  params.UtmContent,
+	})
+	if err != nil {
+		return fmt.Errorf("error inserting utm template: %w", err)
+	}
+	if numAffectedRows == 0 {
+		return fmt.Errorf("%w: utm template already exists", ConflictErr)
+	}
+
+	return nil
+}
+
+func (me *CampaignService) GetUtmTemplates(ctx context.Context, username string) ([]UtmTemplate, error) {
+	rows, err := me.queries.GetUtmTemplatesByUsername(ctx, username)
+	if err != nil {
+		return nil, fmt.Errorf("error getting utm templates: %w", err)
+	}
+
+	templates := make([]UtmTemplate, len(rows))
+	for i, it := range rows {
+		templates[i] = toUtmTemplate(it)
+	}
+
+	return templates, nil
+}
+
+func toUtmTemplate(row postgres_repo.UtmTemplate) UtmTemplate {
+	return UtmTemplate{
+		Name:        row.Name,
+		UtmSource:   row.UtmSource,
+		UtmMedium:   row.UtmMedium,
+		UtmCampaign: row.UtmCampaign,
+		UtmTerm:     row.UtmTerm,
+		UtmContent:  row.UtmContent,
+		CreatedAt:   row.CreatedAt,
+	}
+}
+
+func (me *CampaignService) DeleteUtmTemplate(ctx context.Context, username string, name string) error {
+	if numAffectedRows, err := me.queries.DeleteUtmTemplate(ctx, postgres_repo.DeleteUtmTemplateParams{
+		Username: username,
+		Name:     name,
+	}); err != nil {
+		return fmt.Errorf("error deleting utm template: %w", err)
+	} else if numAffectedRows == 0 {
+		return fmt.Errorf("%w: utm template not found", NotFoundErr)
+	}
+
+	return nil
+}
+
+type Campaign struct {
+	Name        string    `json:"name"`
+	UtmTemplate string    `json:"utmTemplate"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type CreateCampaignParams struct {
+	Username    string `validate:"required"`
+	Name        string `validate:"required,customSlug,max=50"`
+	UtmTemplate string `validate:"customSlug,max=50"` // used for short urls of the campaign that don't set one
+}
+
+func (me *CampaignService) CreateCampaign(ctx context.Context, params CreateCampaignParams) error {
+	if err := utils.ValidateStruct(params); err != nil {
+		return fmt.Errorf("%w: %w", ValidationErr, err)
+	}
+
+	utmTemplateId := sql.NullInt64{}
+	if params.UtmTemplate != "" {
+		template, err := me.queries.GetUtmTemplateByName(ctx, postgres_repo.GetUtmTemplateByNameParams{
+			Username: params.Username,
+			Name:     params.UtmTemplate,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("%w: utm template not found", ValidationErr)
+			}
+			return fmt.Errorf("error getting utm template: %w", err)
+		}
+		utmTemplateId = sql.NullInt64{Int64: template.ID, Valid: true}
+	}
+
+	numAffectedRows, err := me.queries.InsertCampaign(ctx, postgres_repo.InsertCampaignParams{
+		Username:      params.Username,
+		Name:          params.Name,
+		UtmTemplateID: utmTemplateId,
+	})
+	if err != nil {
+		return fmt.Errorf("error inserting campaign: %w", err)
+	}
+	if numAffectedRows == 0 {
+		return fmt.Errorf("%w: campaign already exists", ConflictErr)
+	}
+
+	return nil
+}
+
+func (me *CampaignService) GetCampaigns(ctx context.Context, username string) ([]Campaign, error) {
+	rows, err := me.queries.GetCampaignsByUsername(ctx, username)
+	if err != nil {
+		return nil, fmt.Errorf("error getting campaigns: %w", err)
+	}
+
+	campaigns := make([]Campaign, len(rows))
+	for i, it := range rows {
+		campaigns[i] = Campaign{
+			Name:        it.Name,
+			UtmTemplate: it.UtmTemplate,
+			CreatedAt:   it.CreatedAt,
+		}
+	}
+
+	return campaigns, nil
+}
+
+// DeleteCampaign deletes the campaign only, its short urls are kept without a campaign.
+func (me *CampaignService) DeleteCampaign(ctx context.Context, username string, name string) error {
+	if numAffectedRows, err := me.queries.DeleteCampaign(ctx, postgres_repo.DeleteCampaignParams{
+		Username: username,
+		Name:     name,
+	}); err != nil {
+		return fmt.Errorf("error deleting campaign: %w", err)
+	} else if numAffectedRows == 0 {
+		return fmt.Errorf("%w: campaign not found", NotFoundErr)
+	}
+
+	return nil
+}
+
+// resolveCampaign returns the campaign id and the utm params for a new short url of username.
+// An explicit utmTemplate takes precedence over the template of the campaign, and the campaign
+// name is used as utm_campaign when the template doesn't set one.
+func resolveCampaign(ctx context.Context, queries *postgres_repo.Queries, username, campaign, utmTemplate string) (sql.NullInt64, *utils.UtmParams, error) {
+	campaignId := sql.NullInt64{}
+	templateId := sql.NullInt64{}
+
+	if campaign != "" {
+		row, err := queries.GetCampaignByName(ctx, postgres_repo.GetCampaignByNameParams{Username: username, Name: campaign})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return sql.NullInt64{}, nil, fmt.Errorf("%w: campaign not found", ValidationErr)
+			}
+			return sql.NullInt64{}, nil, fmt.Errorf("error getting campaign: %w", err)
+		}
+		campaignId = sql.NullInt64{Int64: row.ID, Valid: true}
+		templateId = row.UtmTemplateID
+	}
+
+	var template postgres_repo.UtmTemplate
+	var err error
+	switch {
+	case utmTemplate != "":
+		template, err = queries.GetUtmTemplateByName(ctx, postgres_repo.GetUtmTemplateByNameParams{Username: username, Name: utmTemplate})
+	case templateId.Valid:
+		template, err = queries.GetUtmTemplateById(ctx, templateId.Int64)
+	default:
+		return campaignId, nil, nil
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return sql.NullInt64{}, nil, fmt.Errorf("%w: utm template not found", ValidationErr)
+		}
+		return sql.NullInt64{}, nil, fmt.Errorf("error getting utm template: %w", err)
+	}
+
+	utmParams := &utils.UtmParams{
+		Source:   template.UtmSource,
+		Medium:   template.UtmMedium,
+		Campaign: template.UtmCampaign,
+		Term:     template.UtmTerm,
+		Content:  template.UtmContent,
+	}
+	if utmParams.Campaign == "" {
+		utmParams.Campaign = campaign
+	}
+
+	return campaignId, utmParams, nil
+}
+
+// campaignUtmParams returns the utm params of the campaign of a short url of username, nil when it has none.
+// They are applied again to the destinations that replace the ones the short url was created with.
+func campaignUtmParams(ctx context.Context, queries *postgres_repo.Queries, username string, campaignId sql.NullInt64) (*utils.UtmParams, error) {
+	if !campaignId.Valid {
+		return nil, nil
+	}
+
+	campaign, err := queries.GetCampaignById(ctx, campaignId.Int64)
+	if err != nil {
+		return nil, fmt.Errorf("error getting campaign: %w", err)
+	}
+	_, utmParams, err := resolveCampaign(ctx, queries, username, campaign.Name, "")
+	return utmParams, err
+}
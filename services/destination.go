@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/assaidy/url_shortener/config"
+	"github.com/assaidy/url_shortener/utils"
+)
+
+var DestinationServiceInstance = &DestinationService{}
+
+// DestinationService checks destinations of short urls before they are stored, rejecting
+// disallowed schemes, our own hosts and custom domains, private addresses and anything on the
+// blocklist file, which is reloaded when it changes.
+type DestinationService struct {
+	checkers []utils.DestinationChecker
+
+	blocklist        atomic.Pointer[utils.Blocklist]
+	blocklistModTime time.Time
+	reloaderDone     chan struct{}
+}
+
+func (me *DestinationService) Start() error {
+	ownHosts := append([]string{}, config.Cfg.Server.OwnHosts...)
+	if host, _, err := net.SplitHostPort(config.Cfg.Server.Addr); err == nil && host != "" {
+		ownHosts = append(ownHosts, host)
+	}
+
+	me.checkers = []utils.DestinationChecker{
+		utils.SchemeAllowlist(config.Cfg.Destination.AllowedSchemes...),
+		utils.SelfReference(ownHosts...),
+	}
+	if !config.Cfg.Destination.AllowPrivate {
+		me.checkers = append(me.checkers, utils.PrivateAddress())
+	}
+	// custom domains serve short urls too
+	me.checkers = append(me.checkers, utils.DestinationCheckerFunc(func(destination *url.URL) error {
+		domain, err := DomainServiceInstance.ResolveHost(context.Background(), destination.Hostname())
+		if err != nil {
+			slog.Error("error resolving destination host", "err", err)
+			return fmt.Errorf("host can't be checked right now")
+		}
+		if domain != "" {
+			return fmt.Errorf("destination points back at this service")
+		}
+		return nil
+	}))
+	me.checkers = append(me.checkers, utils.DestinationCheckerFunc(func(destination *url.URL) error {
+		if blocklist := me.blocklist.Load(); blocklist != nil {
+			return blocklist.CheckDestination(destination)
+		}
+		return nil
+	}))
+
+	me.reloaderDone = make(chan struct{})
+	if config.Cfg.Destination.BlocklistPath != "" {
+		if err := me.reloadBlocklist(); err != nil {
+			return err
+		}
+		me.startBlocklistReloader()
+	}
+
+	return nil
+}
+
+func (me *DestinationService) Stop() {
+	close(me.reloaderDone)
+}
+
+// CheckDestination returns a ValidationErr with the reason when rawUrl can't be used as a destination.
+func (me *DestinationService) CheckDestination(rawUrl string) error {
+	if err := utils.CheckDestination(rawUrl, me.checkers...); err != nil {
+		return fmt.Errorf("%w: destination %s rejected: %s", ValidationErr, rawUrl, err.Error())
+	}
+	return nil
+}
+
+// reloadBlocklist loads the blocklist file when it changed since the last load.
+func (me *DestinationService) reloadBlocklist() error {
+	file, err := os.Open(config.Cfg.Destination.BlocklistPath)
+	if err != nil {
+		return fmt.Errorf("error opening destination blocklist: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("error reading destination blocklist: %w", err)
+	}
+	if info.ModTime().Equal(me.blocklistModTime) {
+		return nil
+	}
+
+	blocklist, err := utils.ParseBlocklist(file)
+	if err != nil {
+		return fmt.Errorf("error parsing destination blocklist: %w", err)
+	}
+	me.blocklist.Store(blocklist)
+	me.blocklistModTime = info.ModTime()
+
+	slog.Info("destination blocklist loaded", "entries", blocklist.Len(), "PID", os.Getpid())
+	return nil
+}
+
+func (me *DestinationService) startBlocklistReloader() {
+	go func() {
+		ticker := time.NewTicker(config.Cfg.Destination.BlocklistReloadInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-me.reloaderDone:
+				return
+			case <-ticker.C:
+				// a broken file keeps the previous blocklist
+				if err := me.reloadBlocklist(); err != nil {
+					slog.Error("error reloading destination blocklist", "err", err)
+				}
+			}
+		}
+	}()
+}
+
+// newDestinationHttpClient returns a client for requests to destinations of short urls. Unless
+// private destinations are allowed, it refuses to connect to private IPs, which also catches
+// public names resolving to them.
+func newDestinationHttpClient(dialTimeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	if !config.Cfg.Destination.AllowPrivate {
+		dialer.Control = rejectPrivateAddresses
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Transport: transport}
+}
+
+func rejectPrivateAddresses(network string, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if utils.IsPrivateAddr(addrPort.Addr()) {
+		return fmt.Errorf("destination is a private address")
+	}
+	return nil
+}
@@ -0,0 +1,296 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/assaidy/url_shortener/cache"
+	"github.com/assaidy/url_shortener/config"
+	"github.com/assaidy/url_shortener/db/postgres"
+	"github.com/assaidy/url_shortener/repository/postgres"
+	"github.com/assaidy/url_shortener/utils"
+	"github.com/lib/pq"
+	"github.com/valkey-io/valkey-go"
+)
+
+var DomainServiceInstance = &DomainService{}
+
+// DomainService manages the custom domains short urls can be served on, each with its own short
+// urls. Any user can claim a domain, the first one to publish the TXT record of their claim owns it.
+type DomainService struct {
+	db       *sql.DB
+	queries  *postgres_repo.Queries
+	cache    valkey.Client
+	resolver utils.TxtResolver
+}
+
+func (me *DomainService) Start() error {
+	me.db = postgres_db.DB
+	me.queries = postgres_repo.New(me.db)
+	me.cache = cache.Valkey
+
+	me.resolver = net.DefaultResolver
+	if len(config.Cfg.Domains.TxtRecords) != 0 {
+		resolver, err := utils.ParseFakeTxtRecords(config.Cfg.Domains.TxtRecords)
+		if err != nil {
+			return fmt.Errorf("error parsing domain txt records: %w", err)
+		}
+		me.resolver = resolver
+	}
+
+	return nil
+}
+
+func (me *DomainService) Stop() {}
+
+type Domain struct {
+	Domain             string                         `json:"domain"`
+	Verified           bool                           `json:"verified"`
+	VerifiedAt         *time.Time                     `json:"verifiedAt"`
+	VerificationRecord utils.DomainVerificationRecord `json:"verificationRecord"` // to add to the dns of the domain
+	CreatedAt          time.Time                      `json:"createdAt"`
+}
+
+func toDomain(row postgres_repo.Domain) Domain {
+	domain := Domain{
+		Domain:             row.Domain,
+		Verified:           row.VerifiedAt.Valid,
+		VerificationRecord: utils.NewDomainVerificationRecord(row.Domain, row.VerificationToken),
+		CreatedAt:          row.CreatedAt,
+	}
+	if row.VerifiedAt.Valid {
+		domain.VerifiedAt = &row.VerifiedAt.Time
+	}
+	return domain
+}
+
+type AddDomainParams struct {
+	Username string `validate:"required"`
+	Domain   string `validate:"required,fqdn,max=253"`
+}
+
+// AddDomain claims a domain for username. It can't be used until VerifyDomain finds its record.
+func (me *DomainService) AddDomain(ctx context.Context, params AddDomainParams) (Domain, error) {
+	params.Domain = utils.NormalizeDomain(params.Domain)
+	if err := utils.ValidateStruct(params); err != nil {
+		return Domain{}, fmt.Errorf("%w: %w", ValidationErr, err)
+	}
+	if err := utils.SelfReference(config.Cfg.Server.OwnHosts...).CheckDestination(&url.URL{Host: params.Domain}); err != nil {
+		return Domain{}, fmt.Errorf("%w: domain is the main domain of this service", ValidationErr)
+	}
+
+	if owner, err := me.queries.GetVerifiedDomainOwner(ctx, params.Domain); err == nil {
+		if owner != params.Username {
+			return Domain{}, fmt.Errorf("%w: domain is verified by another user", ConflictErr)
+		}
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return Domain{}, fmt.Errorf("error getting domain owner: %w", err)
+	}
+
+	token := make([]byte, 16)
+	rand.Read(token)
+
+	if numAffectedRows, err := me.queries.InsertDomain(ctx, postgres_repo.InsertDomainParams{
+		Domain:            params.Domain,
+		Username:          params.Username,
+		VerificationToken: hex.EncodeToString(token),
+	}); err != nil {
+		return Domain{}, fmt.Errorf("error inserting domain: %w", err)
+	} else if numAffectedRows == 0 {
+		return Domain{}, fmt.Errorf("%w: domain already added", ConflictErr)
+	}
+
+	row, err := me.queries.GetDomain(ctx, postgres_repo.GetDomainParams{Domain: params.Domain, Username: params.Username})
+	if err != nil {
+		return Domain{}, fmt.Errorf("error getting domain: %w", err)
+	}
+
+	return toDomain(row), nil
+}
+
+func (me *DomainService) GetDomains(ctx context.Context, username string) ([]Domain, error) {
+	rows, err := me.queries.GetDomainsByUsername(ctx, username)
+	if err != nil {
+		return nil, fmt.Errorf("error getting domains: %w", err)
+	}
+
+	domains := make([]Domain, len(rows))
+	for i, it := range rows {
+		domains[i] = toDomain(it)
+	}
+
+	return domains, nil
+}
+
+// VerifyDomain looks up the TXT record of a domain claimed by username. Once it's found, the domain
+// belongs to username and the claims of other users are dropped.
+func (me *DomainService) VerifyDomain(ctx context.Context, username string, domain string) (Domain, error) {
+	domain = utils.NormalizeDomain(domain)
+
+	tx, err := me.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Domain{}, fmt.Errorf("error beginning tx: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := me.queries.WithTx(tx)
+
+	row, err := qtx.GetDomainForUpdate(ctx, postgres_repo.GetDomainForUpdateParams{Domain: domain, Username: username})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Domain{}, fmt.Errorf("%w: domain not found", NotFoundErr)
+		}
+		return Domain{}, fmt.Errorf("error getting domain: %w", err)
+	}
+	if row.VerifiedAt.Valid {
+		return toDomain(row), nil
+	}
+
+	if owner, err := qtx.GetVerifiedDomainOwner(ctx, domain); err == nil && owner != username {
+		return Domain{}, fmt.Errorf("%w: domain is verified by another user", ConflictErr)
+	} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return Domain{}, fmt.Errorf("error getting domain owner: %w", err)
+	}
+
+	record := utils.NewDomainVerificationRecord(domain, row.VerificationToken)
+	if verified, err := utils.VerifyDomainRecord(ctx, me.resolver, record); err != nil {
+		return Domain{}, fmt.Errorf("%w: %s", UnprocessableErr, err.Error())
+	} else if !verified {
+		return Domain{}, fmt.Errorf("%w: TXT record %s with value %s not found", UnprocessableErr, record.Name, record.Value)
+	}
+
+	if err := qtx.VerifyDomain(ctx, postgres_repo.VerifyDomainParams{Domain: domain, Username: username}); err != nil {
+		// another user verified the domain after the owner check
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+			return Domain{}, fmt.Errorf("%w: domain is verified by another user", ConflictErr)
+		}
+		return Domain{}, fmt.Errorf("error verifying domain: %w", err)
+	}
+	if err := qtx.DeletePendingDomainClaims(ctx, domain); err != nil {
+		return Domain{}, fmt.Errorf("error deleting pending domain claims: %w", err)
+	}
+
+	if row, err = qtx.GetDomain(ctx, postgres_repo.GetDomainParams{Domain: domain, Username: username}); err != nil {
+		return Domain{}, fmt.Errorf("error getting domain: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Domain{}, fmt.Errorf("error commiting tx: %w", err)
+	}
+
+	me.invalidateVerifiedDomains(ctx)
+
+	return toDomain(row), nil
+}
+
+// DeleteDomain drops the claim of username on a domain, with all its short urls when it was verified.
+func (me *DomainService) DeleteDomain(ctx context.Context, username string, domain string) error {
+	domain = utils.NormalizeDomain(domain)
+
+	tx, err := me.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error beginning tx: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := me.queries.WithTx(tx)
+
+	row, err := qtx.GetDomainForUpdate(ctx, postgres_repo.GetDomainForUpdateParams{Domain: domain, Username: username})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: domain not found", NotFoundErr)
+		}
+		return fmt.Errorf("error getting domain: %w", err)
+	}
+
+	if err := qtx.DeleteDomain(ctx, postgres_repo.DeleteDomainParams{Domain: domain, Username: username}); err != nil {
+		return fmt.Errorf("error deleting domain: %w", err)
+	}
+	if row.VerifiedAt.Valid {
+		if err := qtx.DeleteDomainShortUrls(ctx, domain); err != nil {
+			return fmt.Errorf("error deleting domain short urls: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error commiting tx: %w", err)
+	}
+
+	if row.VerifiedAt.Valid {
+		me.invalidateVerifiedDomains(ctx)
+	}
+
+	return nil
+}
+
+// verifiedDomainsCacheKey holds the set of verified domains. It always has the empty member so a
+// cached set with no domains can be told apart from a missing one.
+const verifiedDomainsCacheKey = "domains:verified"
+
+// ResolveHost returns the custom domain requests to host are for, or an empty string for the main
+// domain. Only verified domains are resolved, other hosts serve the short urls of the main domain.
+func (me *DomainService) ResolveHost(ctx context.Context, host string) (string, error) {
+	host = utils.NormalizeDomain(host)
+	if host == "" {
+		return "", nil
+	}
+
+	if cached, err := me.cache.Do(ctx, me.cache.B().Smismember().Key(verifiedDomainsCacheKey).Member("", host).Build()).AsBoolSlice(); err == nil && len(cached) == 2 && cached[0] {
+		if cached[1] {
+			return host, nil
+		}
+		return "", nil
+	}
+
+	domains, err := me.queries.GetVerifiedDomains(ctx)
+	if err != nil {
+		return "", fmt.Errorf("error getting verified domains: %w", err)
+	}
+
+	for _, it := range me.cache.DoMulti(
+		ctx,
+		me.cache.B().Sadd().Key(verifiedDomainsCacheKey).Member("").Member(domains...).Build(),
+		me.cache.B().Pexpire().Key(verifiedDomainsCacheKey).Milliseconds(config.Cfg.Valkey.CacheTTL.Milliseconds()).Build(),
+	) {
+		if err := it.Error(); err != nil {
+			slog.Error("error setting cache", "key", verifiedDomainsCacheKey, "err", err)
+			break
+		}
+	}
+
+	if slices.Contains(domains, host) {
+		return host, nil
+	}
+	return "", nil
+}
+
+// invalidateVerifiedDomains drops the cached set of verified domains, the next ResolveHost loads
+// it again.
+func (me *DomainService) invalidateVerifiedDomains(ctx context.Context) {
+	if err := me.cache.Do(ctx, me.cache.B().Del().Key(verifiedDomainsCacheKey).Build()).Error(); err != nil {
+		slog.Error("error deleting cache", "key", verifiedDomainsCacheKey, "err", err)
+	}
+}
+
+// checkDomainOwner checks that short urls of username can be on domain, the main domain is everyone's.
+func checkDomainOwner(ctx context.Context, queries *postgres_repo.Queries, username string, domain string) error {
+	if domain == "" {
+		return nil
+	}
+	owner, err := queries.GetVerifiedDomainOwner(ctx, domain)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("error getting domain owner: %w", err)
+	}
+	if err != nil || owner != username {
+		return fmt.Errorf("%w: %s is not a verified domain of yours", ValidationErr, domain)
+	}
+	return nil
+}
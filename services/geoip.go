@@ -0,0 +1,67 @@
+package services
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+
+	"github.com/assaidy/url_shortener/config"
+	"github.com/oschwald/maxminddb-golang"
+)
+
+var GeoIpServiceInstance = &GeoIpService{}
+
+// GeoIpService resolves the country of IPs from an offline .mmdb database (MaxMind or DB-IP,
+// country or city edition) that is fully loaded in memory, so lookups never hit the network or disk.
+type GeoIpService struct {
+	db *maxminddb.Reader // nil when no database is configured
+}
+
+func (me *GeoIpService) Start() error {
+	if config.Cfg.GeoIp.DatabasePath == "" {
+		slog.Warn("no geoip database configured, country rules won't match")
+		return nil
+	}
+
+	data, err := os.ReadFile(config.Cfg.GeoIp.DatabasePath)
+	if err != nil {
+		return fmt.Errorf("error reading geoip database: %w", err)
+	}
+	if me.db, err = maxminddb.FromBytes(data); err != nil {
+		return fmt.Errorf("error opening geoip database: %w", err)
+	}
+
+	return nil
+}
+
+func (me *GeoIpService) Stop() {
+	if me.db != nil {
+		me.db.Close()
+	}
+}
+
+// Country returns the ISO 3166-1 alpha-2 code of the country of ip, or an empty string
+// when it's unknown (private IPs, no database, ...).
+func (me *GeoIpService) Country(ip string) string {
+	if me.db == nil {
+		return ""
+	}
+
+	parsedIp := net.ParseIP(ip)
+	if parsedIp == nil {
+		return ""
+	}
+
+	var record struct {
+		Country struct {
+			IsoCode string `maxminddb:"iso_code"`
+		} `maxminddb:"country"`
+	}
+	if err := me.db.Lookup(parsedIp, &record); err != nil {
+		slog.Error("error looking up geoip", "ip", ip, "err", err)
+		return ""
+	}
+
+	return record.Country.IsoCode
+}
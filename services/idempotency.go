@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/assaidy/url_shortener/cache"
+	"github.com/assaidy/url_shortener/config"
+	"github.com/valkey-io/valkey-go"
+)
+
+var IdempotencyServiceInstance = &IdempotencyService{}
+
+// IdempotencyService remembers the responses of requests sent with an idempotency key,
+// so retries of the same request get the original response instead of being executed again.
+type IdempotencyService struct {
+	cache valkey.Client
+}
+
+func (me *IdempotencyService) Start() error {
+	me.cache = cache.Valkey
+	return nil
+}
+
+func (me *IdempotencyService) Stop() {}
+
+type IdempotentResponse struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"contentType"`
+	Body        []byte `json:"body"`
+}
+
+type idempotencyRecord struct {
+	RequestHash string              `json:"requestHash"`
+	Response    *IdempotentResponse `json:"response"` // nil while the request is in progress
+}
+
+func idempotencyCacheKey(username, key string) string {
+	return "idempotency:" + username + ":" + key
+}
+
+// BeginIdempotentRequest reserves key for a request of username identified by requestHash.
+// It returns the stored response if the same request was already completed, and nil
+// if the caller should execute the request and then call Complete or Release. The reservation
+// only lasts config.Cfg.Urls.IdempotencyLockTTL, so a key of a request that never finished can
+// be retried after it.
+func (me *IdempotencyService) BeginIdempotentRequest(ctx context.Context, username, key, requestHash string) (*IdempotentResponse, error) {
+	cacheKey := idempotencyCacheKey(username, key)
+
+	rawRecord, err := json.Marshal(idempotencyRecord{RequestHash: requestHash})
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling idempotency record: %w", err)
+	}
+
+	err = me.cache.Do(ctx, me.cache.B().Set().Key(cacheKey).Value(string(rawRecord)).Nx().Ex(config.Cfg.Urls.IdempotencyLockTTL).Build()).Error()
+	if err == nil {
+		return nil, nil
+	}
+	if !valkey.IsValkeyNil(err) {
+		return nil, fmt.Errorf("error reserving idempotency key: %w", err)
+	}
+
+	var record idempotencyRecord
+	if err := me.cache.Do(ctx, me.cache.B().Get().Key(cacheKey).Build()).DecodeJSON(&record); err != nil {
+		if valkey.IsValkeyNil(err) { // expired right after the reservation attempt
+			return nil, fmt.Errorf("%w: request with this idempotency key is in progress", ConflictErr)
+		}
+		return nil, fmt.Errorf("error getting idempotency record: %w", err)
+	}
+
+	if record.RequestHash != requestHash {
+		return nil, fmt.Errorf("%w: idempotency key was already used with a different request", UnprocessableErr)
+	}
+	if record.Response == nil {
+		return nil, fmt.Errorf("%w: request with this idempotency key is in progress", ConflictErr)
+	}
+
+	return record.Response, nil
+}
+
+// CompleteIdempotentRequest stores the response of a request started with BeginIdempotentRequest
+// for config.Cfg.Urls.IdempotencyKeyTTL.
+func (me *IdempotencyService) CompleteIdempotentRequest(ctx context.Context, username, key, requestHash string, response IdempotentResponse) error {
+	rawRecord, err := json.Marshal(idempotencyRecord{RequestHash: requestHash, Response: &response})
+	if err != nil {
+		return fmt.Errorf("error marshaling idempotency record: %w", err)
+	}
+
+	cacheKey := idempotencyCacheKey(username, key)
+	if err := me.cache.Do(ctx, me.cache.B().Set().Key(cacheKey).Value(string(rawRecord)).Ex(config.Cfg.Urls.IdempotencyKeyTTL).Build()).Error(); err != nil {
+		return fmt.Errorf("error storing idempotency record: %w", err)
+	}
+
+	return nil
+}
+
+// ReleaseIdempotentRequest frees key after a failed request, so it can be retried.
+func (me *IdempotencyService) ReleaseIdempotentRequest(ctx context.Context, username, key string) error {
+	if err := me.cache.Do(ctx, me.cache.B().Del().Key(idempotencyCacheKey(username, key)).Build()).Error(); err != nil {
+		return fmt.Errorf("error deleting idempotency record: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,624 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/assaidy/url_shortener/config"
+	"github.com/assaidy/url_shortener/db/postgres"
+	"github.com/assaidy/url_shortener/repository/postgres"
+	"github.com/assaidy/url_shortener/utils"
+)
+
+var ImportServiceInstance = &ImportService{}
+
+// ImportService imports short urls from csv or ndjson files. Small files are imported right away,
+// larger ones are stored as jobs and imported in the background by a worker that polls for them,
+// so jobs survive restarts and are picked by a single process when running with prefork. Jobs are
+// imported in batches, and jobs of a worker that stopped are resumed after their last batch.
+type ImportService struct {
+	db      *sql.DB
+	queries *postgres_repo.Queries
+
+	jobNotifyChan chan struct{}
+	jobWorkerStop chan struct{}
+	jobWorkerDone chan struct{}
+}
+
+func (me *ImportService) Start() error {
+	me.db = postgres_db.DB
+	me.queries = postgres_repo.New(me.db)
+
+	me.jobNotifyChan = make(chan struct{}, 1)
+	me.jobWorkerStop = make(chan struct{})
+	me.jobWorkerDone = make(chan struct{})
+	me.startImportJobWorker()
+
+	return nil
+}
+
+func (me *ImportService) Stop() {
+	close(me.jobWorkerStop)
+	<-me.jobWorkerDone
+}
+
+const (
+	ImportFormatCsv    = "csv"
+	ImportFormatNdjson = "ndjson"
+
+	ImportOnConflictSkip   = "skip"
+	ImportOnConflictFail   = "fail"
+	ImportOnConflictRename = "rename" // a random short url is generated instead
+
+	ImportJobStatusPending   = "pending"
+	ImportJobStatusRunning   = "running"
+	ImportJobStatusCompleted = "completed"
+	ImportJobStatusFailed    = "failed"
+
+	ImportResultCreated     = "created"
+	ImportResultRenamed     = "renamed"
+	ImportResultSkipped     = "skipped"
+	ImportResultFailed      = "failed"
+	ImportResultNotImported = "not_imported"
+)
+
+type ImportJob struct {
+	ID         int64      `json:"id"`
+	Domain     string     `json:"domain"`
+	Status     string     `json:"status"`
+	OnConflict string     `json:"onConflict"`
+	Total      int        `json:"total"`
+	Created    int        `json:"created"`
+	Skipped    int        `json:"skipped"`
+	Failed     int        `json:"failed"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	FinishedAt *time.Time `json:"finishedAt"`
+}
+
+type ImportReportRow struct {
+	Line            int    `json:"line"`
+	ShortUrl        string `json:"shortUrl"`
+	LongUrl         string `json:"longUrl"`
+	Result          string `json:"result"`
+	CreatedShortUrl string `json:"createdShortUrl,omitempty"`
+	Error           string `json:"error,omitempty"`
+}
+
+type importItem struct {
+	Line     int    `json:"line"`
+	ShortUrl string `json:"shortUrl"`
+	LongUrl  string `json:"longUrl"`
+}
+
+type CreateImportJobParams struct {
+	Username   string `validate:"required"`
+	Domain     string `validate:"omitempty,fqdn"` // of all the short urls, see CreateShortUrlParams.Domain
+	Format     string `validate:"oneof=csv ndjson"`
+	OnConflict string `validate:"oneof=skip fail rename"`
+	Data       []byte
+}
+
+// CreateImportJob parses the file in params and imports it. The returned job is already finished
+// when the file has at most config.Cfg.Import.SyncMaxItems items, and pending otherwise.
+func (me *ImportService) CreateImportJob(ctx context.Context, params CreateImportJobParams) (ImportJob, error) {
+	if err := utils.ValidateStruct(params); err != nil {
+		return ImportJob{}, fmt.Errorf("%w: %w", ValidationErr, err)
+	}
+	if err := checkDomainOwner(ctx, me.queries, params.Username, params.Domain); err != nil {
+		return ImportJob{}, err
+	}
+
+	var items []importItem
+	var err error
+	switch params.Format {
+	case ImportFormatCsv:
+		items, err = parseCsvImportItems(params.Data)
+	case ImportFormatNdjson:
+		items, err = parseNdjsonImportItems(params.Data)
+	}
+	if err != nil {
+		return ImportJob{}, fmt.Errorf("%w: %w", ValidationErr, err)
+	}
+	if len(items) == 0 {
+		return ImportJob{}, fmt.Errorf("%w: file has no items to import", ValidationErr)
+	}
+
+	rawItems, err := json.Marshal(items)
+	if err != nil {
+		return ImportJob{}, fmt.Errorf("error marshaling import items: %w", err)
+	}
+
+	jobId, err := me.queries.InsertImportJob(ctx, postgres_repo.InsertImportJobParams{
+		Username:   params.Username,
+		Domain:     params.Domain,
+		OnConflict: params.OnConflict,
+		Items:      rawItems,
+		Total:      int32(len(items)),
+	})
+	if err != nil {
+		return ImportJob{}, fmt.Errorf("error inserting import job: %w", err)
+	}
+
+	if len(items) <= config.Cfg.Import.SyncMaxItems {
+		if err := me.runImportJob(ctx, jobId); err != nil {
+			return ImportJob{}, err
+		}
+	} else {
+		select {
+		case me.jobNotifyChan <- struct{}{}:
+		default: // the worker is already notified
+		}
+	}
+
+	return me.GetImportJob(ctx, params.Username, jobId)
+}
+
+func parseCsvImportItems(data []byte) ([]importItem, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv header: %s", err.Error())
+	}
+
+	longUrlColumn, shortUrlColumn := -1, -1
+	for i, it := range header {
+		switch strings.ToLower(strings.TrimSpace(it)) {
+		case "long_url":
+			longUrlColumn = i
+		case "short_url":
+			shortUrlColumn = i
+		}
+	}
+	if longUrlColumn == -1 {
+		return nil, fmt.Errorf("csv header must have a long_url column")
+	}
+
+	items := []importItem{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return items, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv: %s", err.Error())
+		}
+
+		line, _ := reader.FieldPos(0)
+		item := importItem{Line: line, LongUrl: strings.TrimSpace(record[longUrlColumn])}
+		if shortUrlColumn != -1 {
+			item.ShortUrl = strings.TrimSpace(record[shortUrlColumn])
+		}
+		items = append(items, item)
+	}
+}
+
+func parseNdjsonImportItems(data []byte) ([]importItem, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	items := []importItem{}
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var item importItem
+		if err := json.Unmarshal(scanner.Bytes(), &item); err != nil {
+			return nil, fmt.Errorf("invalid json at line %d", line)
+		}
+		item.Line = line
+		items = append(items, item)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("invalid ndjson: %s", err.Error())
+	}
+
+	return items, nil
+}
+
+func (me *ImportService) startImportJobWorker() {
+	go func() {
+		defer close(me.jobWorkerDone)
+
+		ticker := time.NewTicker(config.Cfg.Import.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			me.runPendingImportJobs()
+
+			select {
+			case <-me.jobWorkerStop:
+				return
+			case <-me.jobNotifyChan:
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (me *ImportService) runPendingImportJobs() {
+	ctx := context.Background()
+
+	jobIds, err := me.queries.GetClaimableImportJobIds(ctx, sql.NullTime{Time: time.Now(), Valid: true})
+	if err != nil {
+		slog.Error("error getting pending import jobs", "err", err)
+		return
+	}
+
+	for _, it := range jobIds {
+		select {
+		case <-me.jobWorkerStop:
+			return
+		default:
+		}
+
+		if err := me.runImportJob(ctx, it); err != nil {
+			slog.Error("error running import job", "id", it, "err", err)
+		}
+	}
+}
+
+// importJobLease is how long a claimed job is left to its worker, it's extended after each batch. Jobs of
+// a worker that stopped are taken over by another one once it's over.
+const importJobLease = 10 * time.Minute
+
+// runImportJob claims a pending job, or a running one whose lease is over, and imports its items. Jobs
+// that are claimed by another worker are ignored. Errors after the claim fail the job, unless it can't
+// be finished, then it's left to be taken over.
+func (me *ImportService) runImportJob(ctx context.Context, jobId int64) error {
+	now := time.Now()
+	job, err := me.queries.ClaimImportJob(ctx, postgres_repo.ClaimImportJobParams{
+		ClaimedUntil: sql.NullTime{Time: now.Add(importJobLease), Valid: true},
+		ID:           jobId,
+		Now:          sql.NullTime{Time: now, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("error claiming import job: %w", err)
+	}
+
+	status := ImportJobStatusCompleted
+	errorMessage := ""
+	report, err := me.importJob(ctx, jobId, job)
+	if err != nil {
+		status = ImportJobStatusFailed
+		if errors.Is(err, ConflictErr) || errors.Is(err, ValidationErr) { // e.g. the domain isn't verified anymore
+			errorMessage = err.Error()
+		} else {
+			slog.Error("error importing items", "id", jobId, "err", err, "PID", os.Getpid())
+			errorMessage = "internal error while importing"
+		}
+	}
+
+	var created, skipped, failed int32
+	for _, it := range report {
+		switch it.Result {
+		case ImportResultCreated, ImportResultRenamed:
+			created += 1
+		case ImportResultSkipped:
+			skipped += 1
+		case ImportResultFailed:
+			failed += 1
+		}
+	}
+
+	rawReport, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("error marshaling import report: %w", err)
+	}
+
+	if err := me.queries.FinishImportJob(ctx, postgres_repo.FinishImportJobParams{
+		ID:      jobId,
+		Status:  status,
+		Report:  rawReport,
+		Created: created,
+		Skipped: skipped,
+		Failed:  failed,
+		Error:   errorMessage,
+	}); err != nil {
+		return fmt.Errorf("error finishing import job: %w", err)
+	}
+
+	slog.Info("import job finished", "id", jobId, "status", status, "PID", os.Getpid())
+	return nil
+}
+
+// importJob imports the items of a claimed job after its batches that are already imported, and
+// returns the report of all the items.
+func (me *ImportService) importJob(ctx context.Context, jobId int64, job postgres_repo.ClaimImportJobRow) ([]ImportReportRow, error) {
+	var items []importItem
+	if err := json.Unmarshal(job.Items, &items); err != nil {
+		return []ImportReportRow{}, fmt.Errorf("error unmarshaling import items: %w", err)
+	}
+
+	report := make([]ImportReportRow, len(items))
+	for i, it := range items {
+		report[i] = ImportReportRow{Line: it.Line, ShortUrl: it.ShortUrl, LongUrl: it.LongUrl, Result: ImportResultNotImported}
+	}
+
+	batches, err := me.queries.GetImportJobBatches(ctx, jobId)
+	if err != nil {
+		return report, fmt.Errorf("error getting import job batches: %w", err)
+	}
+	start := 0
+	for _, it := range batches {
+		var batchReport []ImportReportRow
+		if err := json.Unmarshal(it.Report, &batchReport); err != nil {
+			return report, fmt.Errorf("error unmarshaling import batch report: %w", err)
+		}
+		copy(report[it.StartIndex:], batchReport)
+		start = int(it.StartIndex) + len(batchReport)
+	}
+
+	if job.OnConflict == ImportOnConflictFail {
+		return report, me.importItemsOrNone(ctx, jobId, job, items, report, start)
+	}
+
+	for start < len(items) {
+		end := min(start+config.Cfg.Urls.BatchCreateMaxItems, len(items))
+		if err := me.importBatch(ctx, jobId, job, items, report, start, end); err != nil {
+			return report, err
+		}
+		start = end
+	}
+
+	return report, nil
+}
+
+// importBatch imports items[start:end] in their own transaction, see importBatchInTx.
+func (me *ImportService) importBatch(ctx context.Context, jobId int64, job postgres_repo.ClaimImportJobRow, items []importItem, report []ImportReportRow, start int, end int) error {
+	tx, err := me.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error beginning tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := importBatchInTx(ctx, me.queries.WithTx(tx), jobId, job, items, report, start, end); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error commiting tx: %w", err)
+	}
+
+	MetadataServiceInstance.notifyFetcher()
+
+	return me.extendImportJobClaim(ctx, jobId)
+}
+
+// importItemsOrNone imports the items from start in a single transaction, which is rolled back if any
+// short url is taken, by an existing short url or an earlier item of the file. The random short urls
+// are picked before it, so the lock of their length isn't held while the file is imported.
+func (me *ImportService) importItemsOrNone(ctx context.Context, jobId int64, job postgres_repo.ClaimImportJobRow, items []importItem, report []ImportReportRow, start int) error {
+	if start == len(items) {
+		return nil
+	}
+
+	items, err := me.pickRandomShortUrls(ctx, job.Domain, items, start)
+	if err != nil {
+		return err
+	}
+
+	tx, err := me.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error beginning tx: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := me.queries.WithTx(tx)
+
+	conflicts := 0
+	for i := start; i < len(items); i += config.Cfg.Urls.BatchCreateMaxItems {
+		end := min(i+config.Cfg.Urls.BatchCreateMaxItems, len(items))
+		batchConflicts, err := importBatchInTx(ctx, qtx, jobId, job, items, report, i, end)
+		if err != nil {
+			return err
+		}
+		conflicts += batchConflicts
+
+		if err := me.extendImportJobClaim(ctx, jobId); err != nil {
+			return err
+		}
+	}
+
+	if conflicts != 0 {
+		for i := start; i < len(items); i++ {
+			if report[i].Result == ImportResultCreated {
+				report[i] = ImportReportRow{Line: items[i].Line, ShortUrl: items[i].ShortUrl, LongUrl: items[i].LongUrl, Result: ImportResultNotImported}
+			}
+		}
+		return fmt.Errorf("%w: %d short urls are already taken", ConflictErr, conflicts)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error commiting tx: %w", err)
+	}
+
+	MetadataServiceInstance.notifyFetcher()
+
+	return nil
+}
+
+// pickRandomShortUrls returns a copy of items with random short urls for the items from start that
+// have none, picked in their own transaction. A picked short url that is taken before the items are
+// imported conflicts like a custom one.
+func (me *ImportService) pickRandomShortUrls(ctx context.Context, domain string, items []importItem, start int) ([]importItem, error) {
+	custom := map[string]bool{}
+	randomIndexes := []int{}
+	for i, it := range items[start:] {
+		if it.ShortUrl == "" {
+			randomIndexes = append(randomIndexes, start+i)
+		} else {
+			custom[it.ShortUrl] = true
+		}
+	}
+	if len(randomIndexes) == 0 {
+		return items, nil
+	}
+
+	tx, err := me.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error beginning tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	shortUrls, err := generateRandomShortUrls(ctx, me.queries.WithTx(tx), domain, len(randomIndexes), func(shortUrl string) bool { return custom[shortUrl] })
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error commiting tx: %w", err)
+	}
+
+	items = slices.Clone(items)
+	for i, it := range randomIndexes {
+		items[it].ShortUrl = shortUrls[i]
+	}
+
+	return items, nil
+}
+
+func (me *ImportService) extendImportJobClaim(ctx context.Context, jobId int64) error {
+	if err := me.queries.ExtendImportJobClaim(ctx, postgres_repo.ExtendImportJobClaimParams{
+		ClaimedUntil: sql.NullTime{Time: time.Now().Add(importJobLease), Valid: true},
+		ID:           jobId,
+	}); err != nil {
+		return fmt.Errorf("error extending import job claim: %w", err)
+	}
+	return nil
+}
+
+// importBatchInTx creates items[start:end], at most config.Cfg.Urls.BatchCreateMaxItems, in the transaction
+// of qtx, reusing the validation of CreateShortUrl. It fills their report and stores it as a batch of the
+// job, so a job that is taken over resumes after it. It returns the number of items that conflict with
+// taken short urls when the job is ImportOnConflictFail.
+func importBatchInTx(ctx context.Context, qtx *postgres_repo.Queries, jobId int64, job postgres_repo.ClaimImportJobRow, items []importItem, report []ImportReportRow, start int, end int) (int, error) {
+	batch := make([]CreateShortUrlsItem, end-start)
+	for i, it := range items[start:end] {
+		batch[i] = CreateShortUrlsItem{LongUrl: it.LongUrl, ShortUrl: it.ShortUrl}
+	}
+
+	results, err := createShortUrls(ctx, qtx, CreateShortUrlsParams{Username: job.Username, Domain: job.Domain, Items: batch})
+	if err != nil {
+		return 0, err
+	}
+
+	conflicts := 0
+	renameIndexes := []int{} // indexes in report
+	for i, it := range results {
+		row := &report[start+i]
+		switch {
+		case it.Err == nil:
+			row.Result = ImportResultCreated
+			row.CreatedShortUrl = it.ShortUrl
+		case errors.Is(it.Err, ConflictErr) && job.OnConflict == ImportOnConflictRename:
+			renameIndexes = append(renameIndexes, start+i)
+		case errors.Is(it.Err, ConflictErr) && job.OnConflict == ImportOnConflictSkip:
+			row.Result = ImportResultSkipped
+			row.Error = it.Err.Error()
+		default:
+			if errors.Is(it.Err, ConflictErr) {
+				conflicts += 1
+			}
+			row.Result = ImportResultFailed
+			row.Error = it.Err.Error()
+		}
+	}
+
+	if len(renameIndexes) != 0 {
+		batch = make([]CreateShortUrlsItem, len(renameIndexes))
+		for i, it := range renameIndexes {
+			batch[i] = CreateShortUrlsItem{LongUrl: items[it].LongUrl}
+		}
+
+		results, err = createShortUrls(ctx, qtx, CreateShortUrlsParams{Username: job.Username, Domain: job.Domain, Items: batch})
+		if err != nil {
+			return 0, err
+		}
+
+		for i, it := range results {
+			row := &report[renameIndexes[i]]
+			if it.Err == nil {
+				row.Result = ImportResultRenamed
+				row.CreatedShortUrl = it.ShortUrl
+			} else {
+				row.Result = ImportResultFailed
+				row.Error = it.Err.Error()
+			}
+		}
+	}
+
+	rawReport, err := json.Marshal(report[start:end])
+	if err != nil {
+		return 0, fmt.Errorf("error marshaling import batch report: %w", err)
+	}
+	if err := qtx.InsertImportJobBatch(ctx, postgres_repo.InsertImportJobBatchParams{
+		JobID:      jobId,
+		StartIndex: int32(start),
+		Report:     rawReport,
+	}); err != nil {
+		return 0, fmt.Errorf("error inserting import job batch: %w", err)
+	}
+
+	return conflicts, nil
+}
+
+func (me *ImportService) GetImportJob(ctx context.Context, username string, jobId int64) (ImportJob, error) {
+	job, err := me.queries.GetImportJob(ctx, postgres_repo.GetImportJobParams{ID: jobId, Username: username})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ImportJob{}, fmt.Errorf("%w: import job not found", NotFoundErr)
+		}
+		return ImportJob{}, fmt.Errorf("error getting import job: %w", err)
+	}
+
+	result := ImportJob{
+		ID:         job.ID,
+		Domain:     job.Domain,
+		Status:     job.Status,
+		OnConflict: job.OnConflict,
+		Total:      int(job.Total),
+		Created:    int(job.Created),
+		Skipped:    int(job.Skipped),
+		Failed:     int(job.Failed),
+		Error:      job.Error,
+		CreatedAt:  job.CreatedAt,
+	}
+	if job.FinishedAt.Valid {
+		result.FinishedAt = &job.FinishedAt.Time
+	}
+
+	return result, nil
+}
+
+func (me *ImportService) GetImportJobReport(ctx context.Context, username string, jobId int64) ([]ImportReportRow, error) {
+	rawReport, err := me.queries.GetImportJobReport(ctx, postgres_repo.GetImportJobReportParams{ID: jobId, Username: username})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: import job not found", NotFoundErr)
+		}
+		return nil, fmt.Errorf("error getting import job report: %w", err)
+	}
+
+	report := []ImportReportRow{}
+	if err := json.Unmarshal(rawReport, &report); err != nil {
+		return nil, fmt.Errorf("error unmarshaling import report: %w", err)
+	}
+
+	return report, nil
+}
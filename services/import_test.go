@@ -0,0 +1,122 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCsvImportItems(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []importItem
+		wantErr string
+	}{
+		{
+			name: "header in any order and case",
+			data: "Short_Url, LONG_URL ,title\nabc,https://example.com/a,A\n,https://example.com/b,B\n",
+			want: []importItem{
+				{Line: 2, ShortUrl: "abc", LongUrl: "https://example.com/a"},
+				{Line: 3, LongUrl: "https://example.com/b"},
+			},
+		},
+		{
+			name: "without short urls",
+			data: "long_url\nhttps://example.com/a\n",
+			want: []importItem{{Line: 2, LongUrl: "https://example.com/a"}},
+		},
+		{
+			name: "trims values",
+			data: "long_url,short_url\n https://example.com/a , abc \n",
+			want: []importItem{{Line: 2, ShortUrl: "abc", LongUrl: "https://example.com/a"}},
+		},
+		{
+			name: "blank lines are skipped and counted",
+			data: "long_url\n\nhttps://example.com/a\n\n\nhttps://example.com/b",
+			want: []importItem{
+				{Line: 3, LongUrl: "https://example.com/a"},
+				{Line: 6, LongUrl: "https://example.com/b"},
+			},
+		},
+		{
+			name: "lines of quoted fields with newlines",
+			data: "long_url,note\n\"https://example.com/a\",\"two\nlines\"\nhttps://example.com/b,x\n",
+			want: []importItem{
+				{Line: 2, LongUrl: "https://example.com/a"},
+				{Line: 4, LongUrl: "https://example.com/b"},
+			},
+		},
+		{
+			name: "only a header",
+			data: "long_url,short_url\n",
+			want: []importItem{},
+		},
+		{name: "empty file", data: "", wantErr: "invalid csv header: EOF"},
+		{name: "no long_url column", data: "url,short_url\nhttps://example.com/a,abc\n", wantErr: "csv header must have a long_url column"},
+		{name: "row with missing fields", data: "long_url,short_url\nhttps://example.com/a,abc\nhttps://example.com/b\n", wantErr: "record on line 3: wrong number of fields"},
+		{name: "bad quotes", data: "long_url\n\"https://example.com/a\n", wantErr: "extraneous or missing \" in quoted-field"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCsvImportItems([]byte(tt.data))
+			if tt.wantErr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tt.wantErr)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseNdjsonImportItems(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []importItem
+		wantErr string
+	}{
+		{
+			name: "items",
+			data: `{"shortUrl":"abc","longUrl":"https://example.com/a","title":"A"}` + "\n" + `{"longUrl":"https://example.com/b"}` + "\n",
+			want: []importItem{
+				{Line: 1, ShortUrl: "abc", LongUrl: "https://example.com/a"},
+				{Line: 2, LongUrl: "https://example.com/b"},
+			},
+		},
+		{
+			name: "blank lines are skipped and counted",
+			data: "\n" + `{"longUrl":"https://example.com/a"}` + "\n  \n\r\n" + `{"longUrl":"https://example.com/b"}`,
+			want: []importItem{
+				{Line: 2, LongUrl: "https://example.com/a"},
+				{Line: 5, LongUrl: "https://example.com/b"},
+			},
+		},
+		{
+			name: "the line of the item is used, not the one in the file",
+			data: `{"line":10,"longUrl":"https://example.com/a"}`,
+			want: []importItem{{Line: 1, LongUrl: "https://example.com/a"}},
+		},
+		{name: "empty file", data: "", want: []importItem{}},
+		{name: "bad json", data: `{"longUrl":"https://example.com/a"}` + "\n\n" + `{"longUrl":`, wantErr: "invalid json at line 3"},
+		{name: "not an object", data: `["https://example.com/a"]`, wantErr: "invalid json at line 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseNdjsonImportItems([]byte(tt.data))
+			if tt.wantErr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tt.wantErr)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
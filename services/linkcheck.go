@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/assaidy/url_shortener/config"
+	"github.com/assaidy/url_shortener/db/postgres"
+	"github.com/assaidy/url_shortener/repository/postgres"
+	"github.com/assaidy/url_shortener/utils"
+)
+
+var LinkCheckServiceInstance = &LinkCheckService{}
+
+// LinkCheckService periodically probes the destinations of short urls so owners can find broken
+// ones. Due links are claimed in the db in batches, so every link is checked by a single process
+// when running with prefork.
+type LinkCheckService struct {
+	db      *sql.DB
+	queries *postgres_repo.Queries
+	checker *utils.LinkChecker
+
+	workerCtx    context.Context
+	workerCancel context.CancelFunc
+	workerDone   chan struct{}
+}
+
+func (me *LinkCheckService) Start() error {
+	me.db = postgres_db.DB
+	me.queries = postgres_repo.New(me.db)
+
+	me.checker = &utils.LinkChecker{
+		Client:      newDestinationHttpClient(config.Cfg.LinkCheck.Timeout),
+		Timeout:     config.Cfg.LinkCheck.Timeout,
+		Concurrency: config.Cfg.LinkCheck.Concurrency,
+		HostDelay:   config.Cfg.LinkCheck.HostDelay,
+		UserAgent:   config.Cfg.LinkCheck.UserAgent,
+	}
+
+	me.workerCtx, me.workerCancel = context.WithCancel(context.Background())
+	me.workerDone = make(chan struct{})
+	if config.Cfg.LinkCheck.Enabled {
+		me.startLinkCheckWorker()
+	} else {
+		close(me.workerDone)
+	}
+
+	return nil
+}
+
+func (me *LinkCheckService) Stop() {
+	me.workerCancel()
+	<-me.workerDone
+}
+
+func (me *LinkCheckService) startLinkCheckWorker() {
+	go func() {
+		defer close(me.workerDone)
+
+		ticker := time.NewTicker(config.Cfg.LinkCheck.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			me.runDueLinkChecks()
+
+			select {
+			case <-me.workerCtx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// runDueLinkChecks checks due links batch by batch until none are left.
+func (me *LinkCheckService) runDueLinkChecks() {
+	for me.workerCtx.Err() == nil {
+		checked, err := me.runLinkCheckBatch(me.workerCtx)
+		if err != nil {
+			if me.workerCtx.Err() == nil {
+				slog.Error("error running link checks", "err", err, "PID", os.Getpid())
+			}
+			return
+		}
+		if checked < config.Cfg.LinkCheck.BatchSize {
+			return
+		}
+	}
+}
+
+// linkCheckLease is how long claimed links are left to the claiming worker. Links of a worker
+// that died are claimed again after it.
+const linkCheckLease = 30 * time.Minute
+
+func (me *LinkCheckService) runLinkCheckBatch(ctx context.Context) (int, error) {
+	now := time.Now().UTC()
+	claimed, err := me.queries.ClaimLinkChecks(ctx, postgres_repo.ClaimLinkChecksParams{
+		Now:        now,
+		BatchSize:  int32(config.Cfg.LinkCheck.BatchSize),
+		LeaseUntil: now.Add(linkCheckLease),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error claiming link checks: %w", err)
+	}
+
+	urls := make([]string, len(claimed))
+	for i, it := range claimed {
+		urls[i] = it.CheckedUrl
+	}
+	results := me.checker.CheckAll(ctx, urls)
+
+	for i, it := range results {
+		if it.CheckedAt.IsZero() { // stopped before checking it, the lease will expire
+			continue
+		}
+		if err := me.queries.FinishLinkCheck(ctx, postgres_repo.FinishLinkCheckParams{
+			StatusCode:  int32(it.StatusCode),
+			FinalUrl:    it.FinalUrl,
+			Error:       it.Error,
+			Broken:      it.Broken,
+			CheckedAt:   sql.NullTime{Time: it.CheckedAt, Valid: true},
+			NextCheckAt: it.CheckedAt.Add(config.Cfg.LinkCheck.Interval),
+			Domain:      claimed[i].Domain,
+			ShortUrl:    claimed[i].ShortUrl,
+			CheckedUrl:  it.Url,
+		}); err != nil {
+			return 0, fmt.Errorf("error saving link check: %w", err)
+		}
+	}
+
+	return len(claimed), nil
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/assaidy/url_shortener/config"
+	"github.com/assaidy/url_shortener/db/postgres"
+	"github.com/assaidy/url_shortener/repository/postgres"
+	"github.com/assaidy/url_shortener/utils"
+)
+
+var MetadataServiceInstance = &MetadataService{}
+
+// MetadataService fetches the title, description and preview image of destinations in the
+// background, and lets owners override them. Links whose metadata is missing or was fetched for
+// another long url are claimed in the db, so each one is fetched by a single process with prefork.
+type MetadataService struct {
+	db      *sql.DB
+	queries *postgres_repo.Queries
+	fetcher *utils.PageMetadataFetcher
+
+	fetcherNotifyChan chan struct{}
+	fetcherCtx        context.Context
+	fetcherCancel     context.CancelFunc
+	fetcherDone       chan struct{}
+}
+
+func (me *MetadataService) Start() error {
+	me.db = postgres_db.DB
+	me.queries = postgres_repo.New(me.db)
+	me.fetcher = &utils.PageMetadataFetcher{
+		Client:    newDestinationHttpClient(config.Cfg.MetadataFetch.Timeout),
+		Timeout:   config.Cfg.MetadataFetch.Timeout,
+		MaxBytes:  int64(config.Cfg.MetadataFetch.MaxBytes),
+		UserAgent: config.Cfg.MetadataFetch.UserAgent,
+	}
+
+	me.fetcherNotifyChan = make(chan struct{}, 1)
+	me.fetcherCtx, me.fetcherCancel = context.WithCancel(context.Background())
+	me.fetcherDone = make(chan struct{})
+	if config.Cfg.MetadataFetch.Enabled {
+		me.startMetadataFetcher()
+	} else {
+		close(me.fetcherDone)
+	}
+
+	return nil
+}
+
+func (me *MetadataService) Stop() {
+	me.fetcherCancel()
+	<-me.fetcherDone
+}
+
+// notifyFetcher wakes the fetcher up after short urls were created or retargeted. It does nothing
+// when the service isn't started, like in the cli, the fetcher of the server picks the links up.
+func (me *MetadataService) notifyFetcher() {
+	select {
+	case me.fetcherNotifyChan <- struct{}{}:
+	default: // the fetcher is already notified
+	}
+}
+
+func (me *MetadataService) startMetadataFetcher() {
+	go func() {
+		defer close(me.fetcherDone)
+
+		ticker := time.NewTicker(config.Cfg.MetadataFetch.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			me.runDueMetadataFetches()
+
+			select {
+			case <-me.fetcherCtx.Done():
+				return
+			case <-me.fetcherNotifyChan:
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// runDueMetadataFetches fetches due links batch by batch until none are left.
+func (me *MetadataService) runDueMetadataFetches() {
+	for me.fetcherCtx.Err() == nil {
+		fetched, err := me.runMetadataFetchBatch(me.fetcherCtx)
+		if err != nil {
+			if me.fetcherCtx.Err() == nil {
+				slog.Error("error fetching metadata", "err", err, "PID", os.Getpid())
+			}
+			return
+		}
+		if fetched < config.Cfg.MetadataFetch.BatchSize {
+			return
+		}
+	}
+}
+
+// metadataFetchLease is how long claimed links are left to the claiming worker. Links of a worker
+// that died are claimed again after it.
+const metadataFetchLease = 10 * time.Minute
+
+func (me *MetadataService) runMetadataFetchBatch(ctx context.Context) (int, error) {
+	now := time.Now().UTC()
+	claimed, err := me.queries.ClaimMetadataFetches(ctx, postgres_repo.ClaimMetadataFetchesParams{
+		Now:          sql.NullTime{Time: now, Valid: true},
+		BatchSize:    int32(config.Cfg.MetadataFetch.BatchSize),
+		ClaimedUntil: sql.NullTime{Time: now.Add(metadataFetchLease), Valid: true},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error claiming metadata fetches: %w", err)
+	}
+
+	slots := make(chan struct{}, max(config.Cfg.MetadataFetch.Concurrency, 1))
+	var wg sync.WaitGroup
+	for _, it := range claimed {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			me.fetchMetadata(ctx, it.Domain, it.ShortUrl, it.LongUrl)
+		}()
+	}
+	wg.Wait()
+
+	return len(claimed), nil
+}
+
+func (me *MetadataService) fetchMetadata(ctx context.Context, domain string, shortUrl string, longUrl string) {
+	metadata, err := me.fetcher.Fetch(ctx, longUrl)
+	if ctx.Err() != nil { // stopped, the lease will expire
+		return
+	}
+	fetchError := ""
+	if err != nil {
+		// a failed fetch isn't retried until the long url changes, the owner can set the metadata instead
+		fetchError = err.Error()
+	}
+
+	if err := me.queries.FinishMetadataFetch(ctx, postgres_repo.FinishMetadataFetchParams{
+		FetchedUrl:  longUrl,
+		Title:       metadata.Title,
+		Description: metadata.Description,
+		ImageUrl:    metadata.ImageUrl,
+		FetchError:  fetchError,
+		FetchedAt:   sql.NullTime{Time: time.Now().UTC(), Valid: true},
+		Domain:      domain,
+		ShortUrl:    shortUrl,
+	}); err != nil {
+		slog.Error("error saving fetched metadata", "domain", domain, "shortUrl", shortUrl, "err", err, "PID", os.Getpid())
+		return
+	}
+
+	UrlServiceInstance.invalidateRedirectInfo(ctx, domain, shortUrl)
+}
+
+type ShortUrlMetadata struct {
+	Metadata   utils.PageMetadata `json:"metadata"` // what is shown, the custom fields win over the fetched ones
+	Fetched    utils.PageMetadata `json:"fetched"`
+	Custom     CustomMetadata     `json:"custom"`
+	FetchError string             `json:"fetchError,omitempty"`
+	FetchedAt  *time.Time         `json:"fetchedAt"` // nil until fetched
+}
+
+// CustomMetadata overrides the fetched fields that aren't nil.
+type CustomMetadata struct {
+	Title       *string `json:"title" validate:"omitnil,max=300"`
+	Description *string `json:"description" validate:"omitnil,max=1000"`
+	ImageUrl    *string `json:"imageUrl" validate:"omitnil,max=2048,eq=|http_url"` // an empty one hides the fetched image
+}
+
+func (me *MetadataService) GetShortUrlMetadata(ctx context.Context, username string, domain string, shortUrl string) (ShortUrlMetadata, error) {
+	if err := checkShortUrlOwner(ctx, me.queries, username, domain, shortUrl); err != nil {
+		return ShortUrlMetadata{}, err
+	}
+
+	row, err := me.queries.GetShortUrlMetadata(ctx, postgres_repo.GetShortUrlMetadataParams{Domain: domain, ShortUrl: shortUrl})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) { // not fetched yet
+			return ShortUrlMetadata{}, nil
+		}
+		return ShortUrlMetadata{}, fmt.Errorf("error getting short url metadata: %w", err)
+	}
+
+	metadata := ShortUrlMetadata{
+		Fetched: utils.PageMetadata{
+			Title:       row.Title,
+			Description: row.Description,
+			ImageUrl:    row.ImageUrl,
+		},
+		Custom: CustomMetadata{
+			Title:       nullStringPointer(row.CustomTitle),
+			Description: nullStringPointer(row.CustomDescription),
+			ImageUrl:    nullStringPointer(row.CustomImageUrl),
+		},
+		FetchError: row.FetchError,
+	}
+	metadata.Metadata = utils.PageMetadata{
+		Title:       valueOr(metadata.Custom.Title, row.Title),
+		Description: valueOr(metadata.Custom.Description, row.Description),
+		ImageUrl:    valueOr(metadata.Custom.ImageUrl, row.ImageUrl),
+	}
+	if row.FetchedAt.Valid {
+		metadata.FetchedAt = &row.FetchedAt.Time
+	}
+
+	return metadata, nil
+}
+
+type SetShortUrlMetadataParams struct {
+	Username string `validate:"required"`
+	Domain   string `validate:"omitempty,fqdn"`
+	ShortUrl string `validate:"required"`
+	Custom   CustomMetadata
+}
+
+// SetShortUrlMetadata replaces the custom metadata of a short url owned by username. An empty
+// string hides the fetched field, nil shows it again.
+func (me *MetadataService) SetShortUrlMetadata(ctx context.Context, params SetShortUrlMetadataParams) error {
+	if err := utils.ValidateStruct(params); err != nil {
+		return fmt.Errorf("%w: %w", ValidationErr, err)
+	}
+
+	if err := checkShortUrlOwner(ctx, me.queries, params.Username, params.Domain, params.ShortUrl); err != nil {
+		return err
+	}
+
+	if err := me.queries.SetShortUrlCustomMetadata(ctx, postgres_repo.SetShortUrlCustomMetadataParams{
+		Domain:            params.Domain,
+		ShortUrl:          params.ShortUrl,
+		CustomTitle:       pointerNullString(params.Custom.Title),
+		CustomDescription: pointerNullString(params.Custom.Description),
+		CustomImageUrl:    pointerNullString(params.Custom.ImageUrl),
+	}); err != nil {
+		return fmt.Errorf("error setting short url metadata: %w", err)
+	}
+
+	UrlServiceInstance.invalidateRedirectInfo(ctx, params.Domain, params.ShortUrl)
+
+	return nil
+}
+
+func nullStringPointer(value sql.NullString) *string {
+	if !value.Valid {
+		return nil
+	}
+	return &value.String
+}
+
+func pointerNullString(value *string) sql.NullString {
+	if value == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *value, Valid: true}
+}
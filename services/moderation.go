@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/assaidy/url_shortener/db/postgres"
+	"github.com/assaidy/url_shortener/repository/postgres"
+	"github.com/assaidy/url_shortener/utils"
+)
+
+var ModerationServiceInstance = &ModerationService{}
+
+// ModerationService takes abuse reports from the public and lets moderators disable short urls.
+type ModerationService struct {
+	db      *sql.DB
+	queries *postgres_repo.Queries
+}
+
+func (me *ModerationService) Start() error {
+	me.db = postgres_db.DB
+	me.queries = postgres_repo.New(me.db)
+
+	return nil
+}
+
+func (me *ModerationService) Stop() {}
+
+// statuses of abuse reports
+const (
+	AbuseReportStatusOpen      = "open"
+	AbuseReportStatusDismissed = "dismissed"
+	AbuseReportStatusActioned  = "actioned" // the short url was disabled
+)
+
+// DisabledReasonIllegal is the reason of short urls taken down for legal reasons, which are
+// served with 451 instead of 410.
+const DisabledReasonIllegal = "illegal"
+
+type ReportShortUrlParams struct {
+	Domain     string `validate:"omitempty,fqdn"`
+	ShortUrl   string `validate:"required"`
+	Reason     string `validate:"required,oneof=phishing malware spam illegal other"`
+	Details    string `validate:"max=1000"`
+	ReporterIp string `validate:"required"`
+}
+
+// ReportShortUrl adds a report to the queue. Reporting the same short url again while the
+// previous report of the same IP is open is accepted but ignored.
+func (me *ModerationService) ReportShortUrl(ctx context.Context, params ReportShortUrlParams) error {
+	if err := utils.ValidateStruct(params); err != nil {
+		return fmt.Errorf("%w: %w", ValidationErr, err)
+	}
+
+	if ok, err := me.queries.CheckShortUrl(ctx, postgres_repo.CheckShortUrlParams{Domain: params.Domain, ShortUrl: params.ShortUrl}); err != nil {
+		return fmt.Errorf("error checking short url: %w", err)
+	} else if !ok {
+		return fmt.Errorf("%w: url not found", NotFoundErr)
+	}
+
+	if _, err := me.queries.InsertAbuseReport(ctx, postgres_repo.InsertAbuseReportParams{
+		Domain:     params.Domain,
+		ShortUrl:   params.ShortUrl,
+		Reason:     params.Reason,
+		Details:    params.Details,
+		ReporterIp: params.ReporterIp,
+	}); err != nil {
+		return fmt.Errorf("error inserting abuse report: %w", err)
+	}
+
+	return nil
+}
+
+type AbuseReport struct {
+	ID         int64      `json:"id"`
+	Domain     string     `json:"domain,omitempty"` // empty for the main domain
+	ShortUrl   string     `json:"shortUrl"`
+	LongUrl    string     `json:"longUrl"`
+	Owner      string     `json:"owner"`
+	Disabled   bool       `json:"disabled"`
+	Reason     string     `json:"reason"`
+	Details    string     `json:"details"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ResolvedAt *time.Time `json:"resolvedAt,omitempty"`
+	ResolvedBy string     `json:"resolvedBy,omitempty"`
+}
+
+const abuseReportsPageSize = 100
+
+// GetAbuseReports returns a page of the reports with status, oldest first, starting after afterId.
+func (me *ModerationService) GetAbuseReports(ctx context.Context, status string, afterId int64) ([]AbuseReport, error) {
+	switch status {
+	case AbuseReportStatusOpen, AbuseReportStatusDismissed, AbuseReportStatusActioned:
+	default:
+		return nil, fmt.Errorf("%w: invalid status", ValidationErr)
+	}
+
+	rows, err := me.queries.GetAbuseReports(ctx, postgres_repo.GetAbuseReportsParams{
+		Status:   status,
+		AfterID:  afterId,
+		PageSize: abuseReportsPageSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting abuse reports: %w", err)
+	}
+
+	reports := make([]AbuseReport, len(rows))
+	for i, it := range rows {
+		reports[i] = AbuseReport{
+			ID:         it.ID,
+			Domain:     it.Domain,
+			ShortUrl:   it.ShortUrl,
+			LongUrl:    it.LongUrl,
+			Owner:      it.Owner,
+			Disabled:   it.Disabled,
+			Reason:     it.Reason,
+			Details:    it.Details,
+			Status:     it.Status,
+			CreatedAt:  it.CreatedAt,
+			ResolvedBy: it.ResolvedBy,
+		}
+		if it.ResolvedAt.Valid {
+			reports[i].ResolvedAt = &it.ResolvedAt.Time
+		}
+	}
+
+	return reports, nil
+}
+
+// DismissAbuseReport closes an open report without action.
+func (me *ModerationService) DismissAbuseReport(ctx context.Context, moderator string, id int64) error {
+	if numAffectedRows, err := me.queries.ResolveAbuseReport(ctx, postgres_repo.ResolveAbuseReportParams{
+		ID:         id,
+		Status:     AbuseReportStatusDismissed,
+		ResolvedBy: moderator,
+	}); err != nil {
+		return fmt.Errorf("error resolving abuse report: %w", err)
+	} else if numAffectedRows == 0 {
+		return fmt.Errorf("%w: open abuse report not found", NotFoundErr)
+	}
+
+	return nil
+}
+
+type DisableShortUrlParams struct {
+	Moderator string `validate:"required"`
+	Domain    string `validate:"omitempty,fqdn"`
+	ShortUrl  string `validate:"required"`
+	Reason    string `validate:"required,oneof=phishing malware spam illegal other"`
+}
+
+// DisableShortUrl stops the redirects of a short url right away and resolves its open reports.
+func (me *ModerationService) DisableShortUrl(ctx context.Context, params DisableShortUrlParams) error {
+	if err := utils.ValidateStruct(params); err != nil {
+		return fmt.Errorf("%w: %w", ValidationErr, err)
+	}
+
+	tx, err := me.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error beginning tx: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := me.queries.WithTx(tx)
+
+	if numAffectedRows, err := qtx.DisableShortUrl(ctx, postgres_repo.DisableShortUrlParams{
+		Domain:         params.Domain,
+		ShortUrl:       params.ShortUrl,
+		DisabledReason: params.Reason,
+	}); err != nil {
+		return fmt.Errorf("error disabling short url: %w", err)
+	} else if numAffectedRows == 0 {
+		return fmt.Errorf("%w: url not found", NotFoundErr)
+	}
+
+	if err := qtx.ResolveShortUrlAbuseReports(ctx, postgres_repo.ResolveShortUrlAbuseReportsParams{
+		Domain:     params.Domain,
+		ShortUrl:   params.ShortUrl,
+		Status:     AbuseReportStatusActioned,
+		ResolvedBy: params.Moderator,
+	}); err != nil {
+		return fmt.Errorf("error resolving abuse reports: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error commiting tx: %w", err)
+	}
+
+	UrlServiceInstance.invalidateRedirectInfo(ctx, params.Domain, params.ShortUrl)
+
+	return nil
+}
+
+func (me *ModerationService) EnableShortUrl(ctx context.Context, domain string, shortUrl string) error {
+	if numAffectedRows, err := me.queries.EnableShortUrl(ctx, postgres_repo.EnableShortUrlParams{Domain: domain, ShortUrl: shortUrl}); err != nil {
+		return fmt.Errorf("error enabling short url: %w", err)
+	} else if numAffectedRows == 0 {
+		return fmt.Errorf("%w: url not found", NotFoundErr)
+	}
+
+	UrlServiceInstance.invalidateRedirectInfo(ctx, domain, shortUrl)
+
+	return nil
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+
+	"github.com/assaidy/url_shortener/config"
+	"github.com/assaidy/url_shortener/db/postgres"
+	"github.com/assaidy/url_shortener/repository/postgres"
+	"github.com/assaidy/url_shortener/utils"
+)
+
+var QrCodeServiceInstance = &QrCodeService{}
+
+// QrCodeService renders QR codes of short urls, optionally with a logo downloaded from a url.
+type QrCodeService struct {
+	db         *sql.DB
+	queries    *postgres_repo.Queries
+	logoClient *http.Client
+}
+
+func (me *QrCodeService) Start() error {
+	me.db = postgres_db.DB
+	me.queries = postgres_repo.New(me.db)
+	me.logoClient = newDestinationHttpClient(config.Cfg.Qr.LogoFetchTimeout)
+	me.logoClient.Timeout = config.Cfg.Qr.LogoFetchTimeout
+
+	return nil
+}
+
+func (me *QrCodeService) Stop() {}
+
+const (
+	QrCodeFormatPng = "png"
+	QrCodeFormatSvg = "svg"
+)
+
+// larger logos are rejected before decoding them
+const qrLogoMaxDimension = 4096
+
+type RenderQrCodeParams struct {
+	Username   string `validate:"required"`
+	Domain     string `validate:"omitempty,fqdn"`
+	ShortUrl   string `validate:"required"`
+	Content    string `validate:"required,url"` // the link encoded in the code
+	Format     string `validate:"oneof=png svg"`
+	Size       int    `validate:"min=64,max=2048"`                 // in pixels
+	Margin     int    `validate:"min=0,max=16"`                    // in modules
+	Level      string `validate:"omitempty,oneof=L M Q H l m q h"` // defaults to M, or H with a logo
+	Foreground string `validate:"required"`                        // hex colors, see utils.ParseHexColor
+	Background string `validate:"required"`
+	LogoUrl    string `validate:"omitempty,http_url"`
+}
+
+// RenderQrCode renders the QR code of a short url owned by username.
+func (me *QrCodeService) RenderQrCode(ctx context.Context, params RenderQrCodeParams) ([]byte, error) {
+	if err := utils.ValidateStruct(params); err != nil {
+		return nil, fmt.Errorf("%w: %w", ValidationErr, err)
+	}
+
+	options := utils.QrCodeOptions{Size: params.Size, Margin: params.Margin}
+	foreground, err := utils.ParseHexColor(params.Foreground)
+	if err != nil {
+		return nil, fmt.Errorf("%w: foreground: %s", ValidationErr, err.Error())
+	}
+	background, err := utils.ParseHexColor(params.Background)
+	if err != nil {
+		return nil, fmt.Errorf("%w: background: %s", ValidationErr, err.Error())
+	}
+	options.Foreground, options.Background = foreground, background
+
+	level := params.Level
+	if level == "" {
+		level = "M"
+		if params.LogoUrl != "" {
+			level = "H"
+		}
+	}
+	if options.Level, err = utils.ParseQrRecoveryLevel(level); err != nil {
+		return nil, fmt.Errorf("%w: %w", ValidationErr, err)
+	}
+
+	if err := checkShortUrlOwner(ctx, me.queries, params.Username, params.Domain, params.ShortUrl); err != nil {
+		return nil, err
+	}
+
+	if params.LogoUrl != "" {
+		if options.Logo, err = me.fetchLogo(ctx, params.LogoUrl); err != nil {
+			return nil, err
+		}
+	}
+
+	var data []byte
+	switch params.Format {
+	case QrCodeFormatPng:
+		data, err = utils.QrCodePng(params.Content, options)
+	case QrCodeFormatSvg:
+		data, err = utils.QrCodeSvg(params.Content, options)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", UnprocessableErr, err.Error())
+	}
+
+	return data, nil
+}
+
+// fetchLogo downloads and decodes a PNG, JPEG or GIF logo. Failures are the caller's to fix, so
+// they are returned as UnprocessableErr.
+func (me *QrCodeService) fetchLogo(ctx context.Context, logoUrl string) (image.Image, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, logoUrl, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid logo url", ValidationErr)
+	}
+
+	resp, err := me.logoClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: error downloading logo: %s", UnprocessableErr, err.Error())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: error downloading logo: status %d", UnprocessableErr, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, int64(config.Cfg.Qr.LogoMaxBytes)+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: error downloading logo: %s", UnprocessableErr, err.Error())
+	}
+	if len(data) > config.Cfg.Qr.LogoMaxBytes {
+		return nil, fmt.Errorf("%w: logo is larger than %d bytes", UnprocessableErr, config.Cfg.Qr.LogoMaxBytes)
+	}
+
+	imageConfig, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: logo must be a png, jpeg or gif image", UnprocessableErr)
+	}
+	if imageConfig.Width > qrLogoMaxDimension || imageConfig.Height > qrLogoMaxDimension {
+		return nil, fmt.Errorf("%w: logo is larger than %dx%d pixels", UnprocessableErr, qrLogoMaxDimension, qrLogoMaxDimension)
+	}
+
+	logo, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: logo must be a png, jpeg or gif image", UnprocessableErr)
+	}
+	return logo, nil
+}
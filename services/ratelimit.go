@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/assaidy/url_shortener/cache"
+	"github.com/assaidy/url_shortener/config"
+	"github.com/assaidy/url_shortener/utils"
+	"github.com/valkey-io/valkey-go"
+)
+
+var RateLimitServiceInstance = &RateLimitService{}
+
+type RateLimitService struct {
+	cache     valkey.Client
+	allowlist []netip.Prefix
+
+	// used while valkey is unreachable, until fallbackUntil (unix nano) passes
+	fallback      *utils.MemoryRateLimiter
+	fallbackUntil atomic.Int64
+
+	janitorDone chan struct{}
+}
+
+func (me *RateLimitService) Start() error {
+	me.cache = cache.Valkey
+	me.fallback = utils.NewMemoryRateLimiter()
+
+	me.allowlist = []netip.Prefix{}
+	for _, it := range config.Cfg.RateLimit.Allowlist {
+		prefix, err := parseIpOrPrefix(it)
+		if err != nil {
+			return fmt.Errorf("invalid rate limit allowlist entry %q: %w", it, err)
+		}
+		me.allowlist = append(me.allowlist, prefix)
+	}
+
+	me.janitorDone = make(chan struct{})
+	me.startFallbackJanitor()
+
+	return nil
+}
+
+func (me *RateLimitService) Stop() {
+	close(me.janitorDone)
+}
+
+func parseIpOrPrefix(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		return netip.ParsePrefix(s)
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+func (me *RateLimitService) startFallbackJanitor() {
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-me.janitorDone:
+				return
+			case now := <-ticker.C:
+				me.fallback.DeleteExpired(now)
+			}
+		}
+	}()
+}
+
+// IsAllowlisted reports whether ip is exempt from rate limiting.
+func (me *RateLimitService) IsAllowlisted(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, it := range me.allowlist {
+		if it.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// increments the hits of the current window and returns them with the hits of the previous one
+var slidingWindowScript = valkey.NewLuaScript(`
+local curr = redis.call('INCR', KEYS[1])
+if curr == 1 then
+    redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+local prev = tonumber(redis.call('GET', KEYS[2]) or '0')
+return {prev, curr}
+`)
+
+// Hit records a request for key under the policy of group and reports whether it's allowed.
+func (me *RateLimitService) Hit(ctx context.Context, group string, key string) utils.RateLimitResult {
+	policy, _ := config.Cfg.RateLimit.Policies.Get(group)
+	now := time.Now()
+
+	if now.UnixNano() < me.fallbackUntil.Load() {
+		return me.fallback.Hit(group+":"+key, policy.Max, policy.Window, now)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.Cfg.RateLimit.ValkeyTimeout)
+	defer cancel()
+
+	index := utils.SlidingWindowIndex(policy.Window, now)
+	keyPrefix := "ratelimit:" + group + ":" + key + ":"
+	hits, err := slidingWindowScript.Exec(
+		ctx,
+		me.cache,
+		[]string{keyPrefix + strconv.FormatInt(index, 10), keyPrefix + strconv.FormatInt(index-1, 10)},
+		[]string{strconv.FormatInt((2 * policy.Window).Milliseconds(), 10)},
+	).AsIntSlice()
+	if err != nil || len(hits) != 2 {
+		if me.fallbackUntil.Swap(now.Add(config.Cfg.RateLimit.FallbackCooldown).UnixNano()) < now.UnixNano() {
+			slog.Warn("valkey rate limiter unreachable, using in-memory limiter", "err", err, "PID", os.Getpid())
+		}
+		return me.fallback.Hit(group+":"+key, policy.Max, policy.Window, now)
+	}
+
+	return utils.SlidingWindowResult(policy.Max, policy.Window, now, hits[0], hits[1])
+}
@@ -10,8 +10,10 @@ type Service interface {
 }
 
 var (
-	ConflictErr     = fmt.Errorf("Conflict Error")
-	ValidationErr   = fmt.Errorf("Validation Error")
-	NotFoundErr     = fmt.Errorf("NotFound Error")
-	UnauthorizedErr = fmt.Errorf("Unauthorized Error")
+	ConflictErr      = fmt.Errorf("Conflict Error")
+	ValidationErr    = fmt.Errorf("Validation Error")
+	NotFoundErr      = fmt.Errorf("NotFound Error")
+	UnauthorizedErr  = fmt.Errorf("Unauthorized Error")
+	UnprocessableErr = fmt.Errorf("Unprocessable Error")
+	ForbiddenErr     = fmt.Errorf("Forbidden Error")
 )
@@ -4,12 +4,18 @@ import (
 	"context"
 	"crypto/rand"
 	"database/sql"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"maps"
 	"math/big"
+	"net/http"
 	"os"
+	"slices"
+	"strconv"
 	"time"
 
 	"github.com/assaidy/url_shortener/cache"
@@ -49,9 +55,12 @@ func (me *UrlService) Stop() {
 }
 
 type UrlVisit struct {
+	Domain    string    `json:"domain"`
 	ShorUrl   string    `json:"shortUrl"`
 	VisitorIp string    `json:"visitorIp"`
 	VisitedAt time.Time `json:"visitedAt"`
+	Variant   string    `json:"variant"` // name of the served variant, if any
+	Source    string    `json:"source"`  // e.g. utils.VisitSourceQr, empty when unknown
 }
 
 func (me *UrlService) startUrlVisitWorker() {
@@ -93,14 +102,31 @@ func (me *UrlService) flushUrlVisitBuffer(buff []UrlVisit) {
 }
 
 type CreateShortUrlParams struct {
-	Username string `validate:"required"`
-	LongUrl  string `validate:"required,url"`
-	ShortUrl string `validate:"customShortUrl"`
+	Username        string                `validate:"required"`
+	Domain          string                `validate:"omitempty,fqdn"` // a verified custom domain of Username, empty for the main domain
+	LongUrl         string                `validate:"required,url"`
+	ShortUrl        string                `validate:"customShortUrl,customNotReserved"`
+	RedirectStatus  int                   `validate:"omitempty,oneof=301 302 307 308"` // defaults to 302
+	CachePolicy     string                `validate:"omitempty,oneof=no-store no-cache private"`
+	ForwardPath     bool                  // e.g. /docs/api/v2 redirects to long url + /api/v2
+	QueryForwarding string                `validate:"omitempty,oneof=incoming-wins destination-wins keep-both"` // see utils.QueryForwarding*
+	Campaign        string                `validate:"customSlug,max=50"`
+	UtmTemplate     string                `validate:"customSlug,max=50"`       // its params are added to LongUrl
+	Rules           []utils.RoutingRule   `validate:"max=20,dive"`             // evaluated in order, LongUrl is the fallback
+	Variants        []utils.Variant       `validate:"max=10,unique=Name,dive"` // replace LongUrl when no rule matches
+	Schedule        []utils.ScheduleEntry `validate:"max=20,unique=At,dive"`
+	Interstitial    bool                  // visitors see the destination with a countdown before the redirect
 }
 
 func (me *UrlService) CreateShortUrl(ctx context.Context, params CreateShortUrlParams) (string, error) {
 	if err := utils.ValidateStruct(params); err != nil {
-		return "", fmt.Errorf("%w: %s", ValidationErr, err.Error())
+		return "", fmt.Errorf("%w: %w", ValidationErr, err)
+	}
+	if err := validateRoutingRules(params.Rules); err != nil {
+		return "", err
+	}
+	if err := checkDestinations(params.LongUrl, params.Rules, params.Variants, params.Schedule); err != nil {
+		return "", err
 	}
 
 	tx, err := me.db.BeginTx(ctx, nil)
@@ -110,9 +136,13 @@ func (me *UrlService) CreateShortUrl(ctx context.Context, params CreateShortUrlP
 	defer tx.Rollback()
 	qtx := me.queries.WithTx(tx)
 
+	if err := checkDomainOwner(ctx, qtx, params.Username, params.Domain); err != nil {
+		return "", err
+	}
+
 	shortUrl := params.ShortUrl
 	if shortUrl != "" {
-		if ok, err := qtx.CheckShortUrl(ctx, shortUrl); err != nil {
+		if ok, err := qtx.CheckShortUrl(ctx, postgres_repo.CheckShortUrlParams{Domain: params.Domain, ShortUrl: shortUrl}); err != nil {
 			return "", fmt.Errorf("error checking short url: %w", err)
 		} else if ok {
 			return "", fmt.Errorf("%w: short url already exists", ConflictErr)
@@ -125,10 +155,10 @@ func (me *UrlService) CreateShortUrl(ctx context.Context, params CreateShortUrlP
 
 		success := false
 		for {
-			for i := 0; i < config.RandomUrlCollisionRetries && !success; i++ {
+			for i := 0; i < config.Cfg.Urls.RandomUrlCollisionRetries && !success; i++ {
 				shortUrl = generateRandomShortUrl(int(shortUrlLength))
 
-				if ok, err := qtx.CheckShortUrl(ctx, shortUrl); err != nil {
+				if ok, err := qtx.CheckShortUrl(ctx, postgres_repo.CheckShortUrlParams{Domain: params.Domain, ShortUrl: shortUrl}); err != nil {
 					return "", fmt.Errorf("error checking short url: %w", err)
 				} else if !ok {
 					success = true
@@ -145,21 +175,378 @@ func (me *UrlService) CreateShortUrl(ctx context.Context, params CreateShortUrlP
 		}
 	}
 
+	if params.RedirectStatus == 0 {
+		params.RedirectStatus = http.StatusFound
+	}
+
+	campaignId, utmParams, err := resolveCampaign(ctx, qtx, params.Username, params.Campaign, params.UtmTemplate)
+	if err != nil {
+		return "", err
+	}
+	if utmParams != nil {
+		if err := appendUtmParams(*utmParams, &params.LongUrl, params.Rules, params.Variants, params.Schedule); err != nil {
+			return "", err
+		}
+	}
+
 	if err := qtx.InsertShortUrl(ctx, postgres_repo.InsertShortUrlParams{
-		Username: params.Username,
-		LongUrl:  params.LongUrl,
-		ShortUrl: shortUrl,
+		Username:        params.Username,
+		Domain:          params.Domain,
+		LongUrl:         params.LongUrl,
+		ShortUrl:        shortUrl,
+		RedirectStatus:  int32(params.RedirectStatus),
+		CachePolicy:     params.CachePolicy,
+		ForwardPath:     params.ForwardPath,
+		QueryForwarding: params.QueryForwarding,
+		CampaignID:      campaignId,
+		Interstitial:    params.Interstitial,
 	}); err != nil {
 		return "", fmt.Errorf("error inserting short url: %w", err)
 	}
 
+	if err := insertRoutingRules(ctx, qtx, params.Domain, shortUrl, params.Rules); err != nil {
+		return "", err
+	}
+	if err := insertVariants(ctx, qtx, params.Domain, shortUrl, params.Variants); err != nil {
+		return "", err
+	}
+	if err := insertSchedule(ctx, qtx, params.Domain, shortUrl, params.Schedule); err != nil {
+		return "", err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return "", fmt.Errorf("error commiting tx: %w", err)
 	}
 
+	MetadataServiceInstance.notifyFetcher()
+
 	return shortUrl, nil
 }
 
+// appendUtmParams adds utmParams to the long url, when not nil, and all the other destinations of a short url.
+func appendUtmParams(utmParams utils.UtmParams, longUrl *string, rules []utils.RoutingRule, variants []utils.Variant, schedule []utils.ScheduleEntry) error {
+	var err error
+	if longUrl != nil {
+		if *longUrl, err = utils.AppendUtmParams(*longUrl, utmParams); err != nil {
+			return fmt.Errorf("%w: %w", ValidationErr, err)
+		}
+	}
+	for i := range rules {
+		if rules[i].LongUrl, err = utils.AppendUtmParams(rules[i].LongUrl, utmParams); err != nil {
+			return fmt.Errorf("%w: %w", ValidationErr, err)
+		}
+	}
+	for i := range variants {
+		if variants[i].LongUrl, err = utils.AppendUtmParams(variants[i].LongUrl, utmParams); err != nil {
+			return fmt.Errorf("%w: %w", ValidationErr, err)
+		}
+	}
+	for i := range schedule {
+		if schedule[i].LongUrl == "" {
+			continue
+		}
+		if schedule[i].LongUrl, err = utils.AppendUtmParams(schedule[i].LongUrl, utmParams); err != nil {
+			return fmt.Errorf("%w: %w", ValidationErr, err)
+		}
+	}
+	return nil
+}
+
+type CreateShortUrlsParams struct {
+	Username string
+	Domain   string // of all the items, see CreateShortUrlParams.Domain
+	Items    []CreateShortUrlsItem
+}
+
+// CreateShortUrlsItem has the same options as CreateShortUrlParams.
+type CreateShortUrlsItem struct {
+	LongUrl         string
+	ShortUrl        string
+	RedirectStatus  int
+	CachePolicy     string
+	ForwardPath     bool
+	QueryForwarding string
+	Campaign        string
+	UtmTemplate     string
+	Rules           []utils.RoutingRule
+	Variants        []utils.Variant
+	Schedule        []utils.ScheduleEntry
+	Interstitial    bool
+}
+
+type CreateShortUrlsResult struct {
+	ShortUrl string
+	Err      error // validation or conflict error of this item only
+}
+
+// used to insert many short urls with a single query
+type shortUrlInsert struct {
+	Username        string `json:"username"`
+	Domain          string `json:"domain"`
+	LongUrl         string `json:"longUrl"`
+	ShortUrl        string `json:"shortUrl"`
+	RedirectStatus  int    `json:"redirectStatus"`
+	CachePolicy     string `json:"cachePolicy"`
+	ForwardPath     bool   `json:"forwardPath"`
+	QueryForwarding string `json:"queryForwarding"`
+	CampaignId      *int64 `json:"campaignId"`
+	Interstitial    bool   `json:"interstitial"`
+}
+
+// validateCreateShortUrlsItem checks what CreateShortUrl checks before its transaction.
+func validateCreateShortUrlsItem(username string, item CreateShortUrlsItem) error {
+	if err := utils.ValidateStruct(CreateShortUrlParams{
+		Username:        username,
+		LongUrl:         item.LongUrl,
+		ShortUrl:        item.ShortUrl,
+		RedirectStatus:  item.RedirectStatus,
+		CachePolicy:     item.CachePolicy,
+		ForwardPath:     item.ForwardPath,
+		QueryForwarding: item.QueryForwarding,
+		Campaign:        item.Campaign,
+		UtmTemplate:     item.UtmTemplate,
+		Rules:           item.Rules,
+		Variants:        item.Variants,
+		Schedule:        item.Schedule,
+		Interstitial:    item.Interstitial,
+	}); err != nil {
+		return fmt.Errorf("%w: %w", ValidationErr, err)
+	}
+	if err := validateRoutingRules(item.Rules); err != nil {
+		return err
+	}
+	return checkDestinations(item.LongUrl, item.Rules, item.Variants, item.Schedule)
+}
+
+type resolvedCampaign struct {
+	id        sql.NullInt64
+	utmParams *utils.UtmParams
+	err       error
+}
+
+// CreateShortUrls creates many short urls in a single transaction with as few queries as possible.
+// Items that are invalid or conflict don't fail the batch; their error is set on the result
+// with the same index instead.
+func (me *UrlService) CreateShortUrls(ctx context.Context, params CreateShortUrlsParams) ([]CreateShortUrlsResult, error) {
+	tx, err := me.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error beginning tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	results, err := createShortUrls(ctx, me.queries.WithTx(tx), params)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error commiting tx: %w", err)
+	}
+
+	MetadataServiceInstance.notifyFetcher()
+
+	return results, nil
+}
+
+// generateRandomShortUrls returns count distinct random short urls that are free on domain and that
+// taken doesn't reject. It locks the length of random short urls until the transaction of qtx ends,
+// growing it when the short urls of its length keep colliding.
+func generateRandomShortUrls(ctx context.Context, qtx *postgres_repo.Queries, domain string, count int, taken func(string) bool) ([]string, error) {
+	shortUrlLength, err := qtx.GetShortUrlLength(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting short url length: %w", err)
+	}
+
+	shortUrls := make([]string, 0, count)
+	picked := map[string]bool{}
+	for retries := 0; len(shortUrls) != count; retries++ {
+		if retries == config.Cfg.Urls.RandomUrlCollisionRetries {
+			if shortUrlLength, err = qtx.IncrementShortUrlLength(ctx); err != nil {
+				return nil, fmt.Errorf("error incrementing short url length: %w", err)
+			}
+			retries = 0
+		}
+
+		candidates := map[string]bool{}
+		for range count - len(shortUrls) {
+			candidate := generateRandomShortUrl(int(shortUrlLength))
+			if !picked[candidate] && !taken(candidate) {
+				candidates[candidate] = true
+			}
+		}
+
+		existing, err := qtx.GetExistingShortUrls(ctx, postgres_repo.GetExistingShortUrlsParams{
+			Domain:    domain,
+			ShortUrls: slices.Collect(maps.Keys(candidates)),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error checking short urls: %w", err)
+		}
+		for _, it := range existing {
+			delete(candidates, it)
+		}
+
+		for candidate := range candidates {
+			shortUrls = append(shortUrls, candidate)
+			picked[candidate] = true
+		}
+	}
+
+	return shortUrls, nil
+}
+
+// createShortUrls is CreateShortUrls in the transaction of qtx, so callers can create several batches
+// atomically.
+func createShortUrls(ctx context.Context, qtx *postgres_repo.Queries, params CreateShortUrlsParams) ([]CreateShortUrlsResult, error) {
+	if len(params.Items) == 0 || len(params.Items) > config.Cfg.Urls.BatchCreateMaxItems {
+		return nil, fmt.Errorf("%w: number of items must be between 1 and %d", ValidationErr, config.Cfg.Urls.BatchCreateMaxItems)
+	}
+
+	items := slices.Clone(params.Items) // utm params are added to the destinations of the copies
+	results := make([]CreateShortUrlsResult, len(items))
+	for i, it := range items {
+		results[i].Err = validateCreateShortUrlsItem(params.Username, it)
+	}
+
+	if err := checkDomainOwner(ctx, qtx, params.Username, params.Domain); err != nil {
+		return nil, err
+	}
+
+	campaignIds := make([]sql.NullInt64, len(items))
+	campaigns := map[[2]string]resolvedCampaign{} // campaign and utm template -> their resolution
+	for i := range items {
+		it := &items[i]
+		if results[i].Err != nil || (it.Campaign == "" && it.UtmTemplate == "") {
+			continue
+		}
+
+		key := [2]string{it.Campaign, it.UtmTemplate}
+		campaign, ok := campaigns[key]
+		if !ok {
+			campaign.id, campaign.utmParams, campaign.err = resolveCampaign(ctx, qtx, params.Username, it.Campaign, it.UtmTemplate)
+			if campaign.err != nil && !errors.Is(campaign.err, ValidationErr) {
+				return nil, campaign.err
+			}
+			campaigns[key] = campaign
+		}
+		if campaign.err != nil {
+			results[i].Err = campaign.err
+			continue
+		}
+
+		campaignIds[i] = campaign.id
+		if campaign.utmParams != nil {
+			it.Rules, it.Variants, it.Schedule = slices.Clone(it.Rules), slices.Clone(it.Variants), slices.Clone(it.Schedule)
+			results[i].Err = appendUtmParams(*campaign.utmParams, &it.LongUrl, it.Rules, it.Variants, it.Schedule)
+		}
+	}
+
+	customIndexes := map[string]int{} // custom short url -> index of the item that requested it
+	randomIndexes := []int{}
+	for i, it := range items {
+		if results[i].Err != nil {
+			continue
+		}
+		if it.ShortUrl == "" {
+			randomIndexes = append(randomIndexes, i)
+		} else if _, ok := customIndexes[it.ShortUrl]; ok {
+			results[i].Err = fmt.Errorf("%w: short url is duplicated in the batch", ConflictErr)
+		} else {
+			customIndexes[it.ShortUrl] = i
+			results[i].ShortUrl = it.ShortUrl
+		}
+	}
+
+	if len(customIndexes) != 0 {
+		existing, err := qtx.GetExistingShortUrls(ctx, postgres_repo.GetExistingShortUrlsParams{
+			Domain:    params.Domain,
+			ShortUrls: slices.Collect(maps.Keys(customIndexes)),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error checking short urls: %w", err)
+		}
+		for _, it := range existing {
+			results[customIndexes[it]] = CreateShortUrlsResult{Err: fmt.Errorf("%w: short url already exists", ConflictErr)}
+		}
+	}
+
+	if len(randomIndexes) != 0 {
+		shortUrls, err := generateRandomShortUrls(ctx, qtx, params.Domain, len(randomIndexes), func(shortUrl string) bool {
+			_, ok := customIndexes[shortUrl]
+			return ok
+		})
+		if err != nil {
+			return nil, err
+		}
+		for i, it := range randomIndexes {
+			results[it].ShortUrl = shortUrls[i]
+		}
+	}
+
+	inserts := []shortUrlInsert{}
+	for i, it := range items {
+		if results[i].Err != nil {
+			continue
+		}
+		insert := shortUrlInsert{
+			Username:        params.Username,
+			Domain:          params.Domain,
+			LongUrl:         it.LongUrl,
+			ShortUrl:        results[i].ShortUrl,
+			RedirectStatus:  it.RedirectStatus,
+			CachePolicy:     it.CachePolicy,
+			ForwardPath:     it.ForwardPath,
+			QueryForwarding: it.QueryForwarding,
+			Interstitial:    it.Interstitial,
+		}
+		if insert.RedirectStatus == 0 {
+			insert.RedirectStatus = http.StatusFound
+		}
+		if campaignIds[i].Valid {
+			insert.CampaignId = &campaignIds[i].Int64
+		}
+		inserts = append(inserts, insert)
+	}
+	if len(inserts) == 0 {
+		return results, nil
+	}
+
+	rawJson, err := json.Marshal(inserts)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling short urls: %w", err)
+	}
+
+	inserted, err := qtx.InsertShortUrls(ctx, rawJson)
+	if err != nil {
+		return nil, fmt.Errorf("error inserting short urls: %w", err)
+	}
+	// rows inserted concurrently by another transaction are skipped by the insert
+	if len(inserted) != len(inserts) {
+		for i := range results {
+			if results[i].Err == nil && !slices.Contains(inserted, results[i].ShortUrl) {
+				results[i] = CreateShortUrlsResult{Err: fmt.Errorf("%w: short url already exists", ConflictErr)}
+			}
+		}
+	}
+
+	for i, it := range items {
+		if results[i].Err != nil {
+			continue
+		}
+		if err := insertRoutingRules(ctx, qtx, params.Domain, results[i].ShortUrl, it.Rules); err != nil {
+			return nil, err
+		}
+		if err := insertVariants(ctx, qtx, params.Domain, results[i].ShortUrl, it.Variants); err != nil {
+			return nil, err
+		}
+		if err := insertSchedule(ctx, qtx, params.Domain, results[i].ShortUrl, it.Schedule); err != nil {
+			return nil, err
+		}
+	}
+
+	return results, nil
+}
+
 func generateRandomShortUrl(length int) string {
 	charRange := "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 	charRangeLength := len(charRange)
@@ -170,35 +557,735 @@ func generateRandomShortUrl(length int) string {
 	}
 	return string(buf)
 }
-func (me *UrlService) GetLongUrl(ctx context.Context, shortUrl string) (string, error) {
-	val, err := me.cache.Do(ctx, me.cache.B().Get().Key(shortUrl).Build()).AsBytes()
-	if err == nil && val != nil {
-		return string(val), nil
+
+// RedirectInfo is everything needed to redirect a short url. It's cached as json so
+// redirects don't hit the db on cache hits.
+type RedirectInfo struct {
+	LongUrl         string              `json:"longUrl"`
+	RedirectStatus  int                 `json:"redirectStatus"`
+	CachePolicy     string              `json:"cachePolicy"`
+	ForwardPath     bool                `json:"forwardPath"`
+	QueryForwarding string              `json:"queryForwarding"`
+	Rules           []utils.RoutingRule `json:"rules,omitempty"`
+	Variants        []utils.Variant     `json:"variants,omitempty"`
+	Interstitial    bool                `json:"interstitial"`
+	Disabled        bool                `json:"disabled"`
+	DisabledReason  string              `json:"disabledReason,omitempty"`
+	State           string              `json:"state,omitempty"`     // current utils.ScheduleState*, empty without a schedule
+	StateUntil      time.Time           `json:"stateUntil,omitzero"` // next schedule boundary, if any
+	Metadata        utils.PageMetadata  `json:"metadata,omitzero"`   // shown to link preview crawlers
+}
+
+// short urls can't contain "/", so the keys of custom domains can't clash with the main one's
+func redirectInfoCacheKey(domain string, shortUrl string) string {
+	if domain == "" {
+		return "short_url:" + shortUrl
 	}
+	return "short_url:" + domain + "/" + shortUrl
+}
 
-	slog.Warn("cache miss", "key", shortUrl)
+// GetLongUrl returns the redirect info of a short url on domain, empty for the main domain.
+func (me *UrlService) GetLongUrl(ctx context.Context, domain string, shortUrl string) (RedirectInfo, error) {
+	cacheKey := redirectInfoCacheKey(domain, shortUrl)
 
-	longUrl, err := me.queries.GetLongUrl(ctx, shortUrl)
+	var info RedirectInfo
+	if err := me.cache.Do(ctx, me.cache.B().Get().Key(cacheKey).Build()).DecodeJSON(&info); err == nil {
+		return info, nil
+	}
+
+	slog.Warn("cache miss", "key", cacheKey)
+
+	row, err := me.queries.GetLongUrl(ctx, postgres_repo.GetLongUrlParams{Domain: domain, ShortUrl: shortUrl})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return "", fmt.Errorf("%w: url not found", NotFoundErr)
+			return RedirectInfo{}, fmt.Errorf("%w: url not found", NotFoundErr)
 		}
-		return "", fmt.Errorf("error getting long url: %w", err)
+		return RedirectInfo{}, fmt.Errorf("error getting long url: %w", err)
+	}
+
+	info = RedirectInfo{
+		LongUrl:         row.LongUrl,
+		RedirectStatus:  int(row.RedirectStatus),
+		CachePolicy:     row.CachePolicy,
+		ForwardPath:     row.ForwardPath,
+		QueryForwarding: row.QueryForwarding,
+		Interstitial:    row.Interstitial,
+		Disabled:        row.Disabled,
+		DisabledReason:  row.DisabledReason,
+		Metadata: utils.PageMetadata{
+			Title:       row.Title,
+			Description: row.Description,
+			ImageUrl:    row.ImageUrl,
+		},
+	}
+
+	rules, err := me.queries.GetShortUrlRules(ctx, postgres_repo.GetShortUrlRulesParams{Domain: domain, ShortUrl: shortUrl})
+	if err != nil {
+		return RedirectInfo{}, fmt.Errorf("error getting short url rules: %w", err)
+	}
+	for _, it := range rules {
+		info.Rules = append(info.Rules, utils.RoutingRule{
+			Device:    it.Device,
+			Os:        it.Os,
+			Language:  it.Language,
+			Countries: it.Countries,
+			LongUrl:   it.LongUrl,
+		})
+	}
+
+	variants, err := me.queries.GetShortUrlVariants(ctx, postgres_repo.GetShortUrlVariantsParams{Domain: domain, ShortUrl: shortUrl})
+	if err != nil {
+		return RedirectInfo{}, fmt.Errorf("error getting short url variants: %w", err)
+	}
+	for _, it := range variants {
+		info.Variants = append(info.Variants, utils.Variant{
+			Name:    it.Name,
+			LongUrl: it.LongUrl,
+			Weight:  int(it.Weight),
+		})
+	}
+
+	schedule, err := me.getShortUrlSchedule(ctx, domain, shortUrl)
+	if err != nil {
+		return RedirectInfo{}, err
+	}
+
+	// the current phase of the schedule is resolved here, so the cached info must expire with it
+	now := time.Now()
+	ttl := config.Cfg.Valkey.CacheTTL
+	if entry, ok := utils.CurrentScheduleEntry(schedule, now); ok {
+		info.State = entry.State
+		if entry.LongUrl != "" {
+			info.LongUrl = entry.LongUrl
+		}
+	}
+	if next, ok := utils.NextScheduleBoundary(schedule, now); ok {
+		info.StateUntil = next
+		ttl = min(ttl, next.Sub(now))
+	}
+	if ttl < time.Millisecond {
+		return info, nil
+	}
+
+	rawInfo, err := json.Marshal(info)
+	if err != nil {
+		return RedirectInfo{}, fmt.Errorf("error marshaling redirect info: %w", err)
 	}
 
 	if _, err := me.cache.Do(
 		ctx,
 		me.cache.B().
 			Set().
-			Key(shortUrl).
-			Value(longUrl).
-			Ex(config.CacheTTL).
+			Key(cacheKey).
+			Value(string(rawInfo)).
+			Px(ttl).
 			Build(),
 	).AsBytes(); err != nil {
-		slog.Error("error setting cache", "key", shortUrl, "value", longUrl, "err", err)
+		slog.Error("error setting cache", "key", cacheKey, "value", string(rawInfo), "err", err)
+	}
+
+	return info, nil
+}
+
+// getShortUrlSchedule returns the schedule entries of a short url sorted by their time.
+func (me *UrlService) getShortUrlSchedule(ctx context.Context, domain string, shortUrl string) ([]utils.ScheduleEntry, error) {
+	rows, err := me.queries.GetShortUrlSchedule(ctx, postgres_repo.GetShortUrlScheduleParams{Domain: domain, ShortUrl: shortUrl})
+	if err != nil {
+		return nil, fmt.Errorf("error getting short url schedule: %w", err)
+	}
+	schedule := make([]utils.ScheduleEntry, len(rows))
+	for i, it := range rows {
+		schedule[i] = utils.ScheduleEntry{At: it.At, State: it.State, LongUrl: it.LongUrl}
+	}
+	return schedule, nil
+}
+
+// UpdateShortUrlParams changes the non nil fields of a short url owned by Username.
+type UpdateShortUrlParams struct {
+	Username        string
+	Domain          string
+	ShortUrl        string
+	LongUrl         *string
+	RedirectStatus  *int
+	CachePolicy     *string
+	ForwardPath     *bool
+	QueryForwarding *string
+	Interstitial    *bool
+}
+
+func (me *UrlService) UpdateShortUrl(ctx context.Context, params UpdateShortUrlParams) error {
+	tx, err := me.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error beginning tx: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := me.queries.WithTx(tx)
+
+	row, err := qtx.GetShortUrlForUpdate(ctx, postgres_repo.GetShortUrlForUpdateParams{Domain: params.Domain, ShortUrl: params.ShortUrl})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: url not found", NotFoundErr)
+		}
+		return fmt.Errorf("error getting short url: %w", err)
+	}
+	if row.Username != params.Username {
+		return fmt.Errorf("%w: url not found", NotFoundErr)
+	}
+
+	// the result is validated with the same constraints as on creation
+	updated := CreateShortUrlParams{
+		Username:        row.Username,
+		LongUrl:         valueOr(params.LongUrl, row.LongUrl),
+		RedirectStatus:  valueOr(params.RedirectStatus, int(row.RedirectStatus)),
+		CachePolicy:     valueOr(params.CachePolicy, row.CachePolicy),
+		ForwardPath:     valueOr(params.ForwardPath, row.ForwardPath),
+		QueryForwarding: valueOr(params.QueryForwarding, row.QueryForwarding),
+		Interstitial:    valueOr(params.Interstitial, row.Interstitial),
+	}
+	if params.LongUrl != nil {
+		utmParams, err := campaignUtmParams(ctx, qtx, row.Username, row.CampaignID)
+		if err != nil {
+			return err
+		}
+		if utmParams != nil {
+			if err := appendUtmParams(*utmParams, &updated.LongUrl, nil, nil, nil); err != nil {
+				return err
+			}
+		}
+	}
+	if err := utils.ValidateStruct(updated); err != nil {
+		return fmt.Errorf("%w: %w", ValidationErr, err)
+	}
+	if params.LongUrl != nil {
+		if err := DestinationServiceInstance.CheckDestination(updated.LongUrl); err != nil {
+			return err
+		}
+	}
+
+	if err := qtx.UpdateShortUrl(ctx, postgres_repo.UpdateShortUrlParams{
+		Domain:          params.Domain,
+		ShortUrl:        params.ShortUrl,
+		LongUrl:         updated.LongUrl,
+		RedirectStatus:  int32(updated.RedirectStatus),
+		CachePolicy:     updated.CachePolicy,
+		ForwardPath:     updated.ForwardPath,
+		QueryForwarding: updated.QueryForwarding,
+		Interstitial:    updated.Interstitial,
+	}); err != nil {
+		return fmt.Errorf("error updating short url: %w", err)
+	}
+
+	retargeted := updated.LongUrl != row.LongUrl
+	if retargeted {
+		// the new destination is checked on the next run of the link checker
+		if err := qtx.DeleteLinkCheck(ctx, postgres_repo.DeleteLinkCheckParams{Domain: params.Domain, ShortUrl: params.ShortUrl}); err != nil {
+			return fmt.Errorf("error deleting link check: %w", err)
+		}
+		// and its metadata fetched again
+		if err := qtx.ClearFetchedMetadata(ctx, postgres_repo.ClearFetchedMetadataParams{Domain: params.Domain, ShortUrl: params.ShortUrl}); err != nil {
+			return fmt.Errorf("error clearing fetched metadata: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error commiting tx: %w", err)
+	}
+
+	me.invalidateRedirectInfo(ctx, params.Domain, params.ShortUrl)
+	if retargeted {
+		MetadataServiceInstance.notifyFetcher()
+	}
+
+	return nil
+}
+
+func valueOr[T any](value *T, defaultValue T) T {
+	if value != nil {
+		return *value
+	}
+	return defaultValue
+}
+
+// ShortUrlPreview is what visitors see about a short url before following it.
+type ShortUrlPreview struct {
+	ShortUrl       string
+	LongUrl        string
+	Owner          string
+	CreatedAt      time.Time
+	Visits         int64
+	Disabled       bool
+	DisabledReason string
+	// the long url is only the default destination when rules or variants send visitors elsewhere
+	HasOtherDestinations bool
+	State                string // current utils.ScheduleState*, empty without a schedule
+}
+
+func (me *UrlService) GetShortUrlPreview(ctx context.Context, domain string, shortUrl string) (ShortUrlPreview, error) {
+	row, err := me.queries.GetShortUrlPreview(ctx, postgres_repo.GetShortUrlPreviewParams{Domain: domain, ShortUrl: shortUrl})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ShortUrlPreview{}, fmt.Errorf("%w: url not found", NotFoundErr)
+		}
+		return ShortUrlPreview{}, fmt.Errorf("error getting short url preview: %w", err)
+	}
+
+	preview := ShortUrlPreview{
+		ShortUrl:             shortUrl,
+		LongUrl:              row.LongUrl,
+		Owner:                row.Username,
+		CreatedAt:            row.CreatedAt,
+		Visits:               row.Visits,
+		Disabled:             row.Disabled,
+		DisabledReason:       row.DisabledReason,
+		HasOtherDestinations: row.HasOtherDestinations,
+	}
+
+	// the preview shows where the short url redirects now, like GetLongUrl
+	schedule, err := me.getShortUrlSchedule(ctx, domain, shortUrl)
+	if err != nil {
+		return ShortUrlPreview{}, err
+	}
+	if entry, ok := utils.CurrentScheduleEntry(schedule, time.Now()); ok {
+		preview.State = entry.State
+		if entry.LongUrl != "" {
+			preview.LongUrl = entry.LongUrl
+		}
+	}
+
+	return preview, nil
+}
+
+type SetShortUrlRulesParams struct {
+	Username string              `validate:"required"`
+	Domain   string              `validate:"omitempty,fqdn"`
+	ShortUrl string              `validate:"required"`
+	Rules    []utils.RoutingRule `validate:"max=20,dive"`
+}
+
+// SetShortUrlRules replaces the routing rules of a short url owned by username. Empty rules remove them.
+func (me *UrlService) SetShortUrlRules(ctx context.Context, params SetShortUrlRulesParams) error {
+	if err := utils.ValidateStruct(params); err != nil {
+		return fmt.Errorf("%w: %w", ValidationErr, err)
+	}
+	if err := checkDestinations("", params.Rules, nil, nil); err != nil {
+		return err
+	}
+	if err := validateRoutingRules(params.Rules); err != nil {
+		return err
+	}
+
+	tx, err := me.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error beginning tx: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := me.queries.WithTx(tx)
+
+	utmParams, err := shortUrlUtmParams(ctx, qtx, params.Username, params.Domain, params.ShortUrl)
+	if err != nil {
+		return err
+	}
+	if utmParams != nil {
+		if err := appendUtmParams(*utmParams, nil, params.Rules, nil, nil); err != nil {
+			return err
+		}
+	}
+
+	if err := qtx.DeleteShortUrlRules(ctx, postgres_repo.DeleteShortUrlRulesParams{Domain: params.Domain, ShortUrl: params.ShortUrl}); err != nil {
+		return fmt.Errorf("error deleting short url rules: %w", err)
+	}
+	if err := insertRoutingRules(ctx, qtx, params.Domain, params.ShortUrl, params.Rules); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error commiting tx: %w", err)
+	}
+
+	me.invalidateRedirectInfo(ctx, params.Domain, params.ShortUrl)
+
+	return nil
+}
+
+// checkDestinations checks every destination a short url can redirect to, an empty longUrl is skipped.
+func checkDestinations(longUrl string, rules []utils.RoutingRule, variants []utils.Variant, schedule []utils.ScheduleEntry) error {
+	destinations := []string{}
+	if longUrl != "" {
+		destinations = append(destinations, longUrl)
+	}
+	for _, it := range rules {
+		destinations = append(destinations, it.LongUrl)
+	}
+	for _, it := range variants {
+		destinations = append(destinations, it.LongUrl)
+	}
+	for _, it := range schedule {
+		if it.LongUrl != "" {
+			destinations = append(destinations, it.LongUrl)
+		}
+	}
+
+	for _, it := range destinations {
+		if err := DestinationServiceInstance.CheckDestination(it); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// a rule without conditions would always match, making the rules after it and the fallback useless
+func validateRoutingRules(rules []utils.RoutingRule) error {
+	for i, it := range rules {
+		if it.Device == "" && it.Os == "" && it.Language == "" && len(it.Countries) == 0 {
+			return fmt.Errorf("%w: rule %d has no conditions", ValidationErr, i)
+		}
+	}
+	return nil
+}
+
+func insertRoutingRules(ctx context.Context, queries *postgres_repo.Queries, domain string, shortUrl string, rules []utils.RoutingRule) error {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	rawJson, err := json.Marshal(rules)
+	if err != nil {
+		return fmt.Errorf("error marshaling short url rules: %w", err)
+	}
+
+	if err := queries.InsertShortUrlRules(ctx, postgres_repo.InsertShortUrlRulesParams{
+		JsonRules: rawJson,
+		Domain:    domain,
+		ShortUrl:  shortUrl,
+	}); err != nil {
+		return fmt.Errorf("error inserting short url rules: %w", err)
+	}
+
+	return nil
+}
+
+type SetShortUrlVariantsParams struct {
+	Username string          `validate:"required"`
+	Domain   string          `validate:"omitempty,fqdn"`
+	ShortUrl string          `validate:"required"`
+	Variants []utils.Variant `validate:"max=10,unique=Name,dive"`
+}
+
+// SetShortUrlVariants replaces the split test variants of a short url owned by username. Empty variants
+// remove them. Keeping the names while changing weights keeps visitors on their variant.
+func (me *UrlService) SetShortUrlVariants(ctx context.Context, params SetShortUrlVariantsParams) error {
+	if err := utils.ValidateStruct(params); err != nil {
+		return fmt.Errorf("%w: %w", ValidationErr, err)
+	}
+	if err := checkDestinations("", nil, params.Variants, nil); err != nil {
+		return err
+	}
+
+	tx, err := me.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error beginning tx: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := me.queries.WithTx(tx)
+
+	utmParams, err := shortUrlUtmParams(ctx, qtx, params.Username, params.Domain, params.ShortUrl)
+	if err != nil {
+		return err
+	}
+	if utmParams != nil {
+		if err := appendUtmParams(*utmParams, nil, nil, params.Variants, nil); err != nil {
+			return err
+		}
+	}
+
+	if err := qtx.DeleteShortUrlVariants(ctx, postgres_repo.DeleteShortUrlVariantsParams{Domain: params.Domain, ShortUrl: params.ShortUrl}); err != nil {
+		return fmt.Errorf("error deleting short url variants: %w", err)
+	}
+	if err := insertVariants(ctx, qtx, params.Domain, params.ShortUrl, params.Variants); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error commiting tx: %w", err)
+	}
+
+	me.invalidateRedirectInfo(ctx, params.Domain, params.ShortUrl)
+
+	return nil
+}
+
+func insertVariants(ctx context.Context, queries *postgres_repo.Queries, domain string, shortUrl string, variants []utils.Variant) error {
+	if len(variants) == 0 {
+		return nil
+	}
+
+	rawJson, err := json.Marshal(variants)
+	if err != nil {
+		return fmt.Errorf("error marshaling short url variants: %w", err)
+	}
+
+	if err := queries.InsertShortUrlVariants(ctx, postgres_repo.InsertShortUrlVariantsParams{
+		JsonVariants: rawJson,
+		Domain:       domain,
+		ShortUrl:     shortUrl,
+	}); err != nil {
+		return fmt.Errorf("error inserting short url variants: %w", err)
+	}
+
+	return nil
+}
+
+type SetShortUrlScheduleParams struct {
+	Username string                `validate:"required"`
+	Domain   string                `validate:"omitempty,fqdn"`
+	ShortUrl string                `validate:"required"`
+	Schedule []utils.ScheduleEntry `validate:"max=20,unique=At,dive"`
+}
+
+// SetShortUrlSchedule replaces the schedule of a short url owned by username. An empty schedule removes it.
+// Before the first entry and without a schedule, the short url just redirects to its long url.
+func (me *UrlService) SetShortUrlSchedule(ctx context.Context, params SetShortUrlScheduleParams) error {
+	if err := utils.ValidateStruct(params); err != nil {
+		return fmt.Errorf("%w: %w", ValidationErr, err)
+	}
+	if err := checkDestinations("", nil, nil, params.Schedule); err != nil {
+		return err
+	}
+
+	tx, err := me.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error beginning tx: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := me.queries.WithTx(tx)
+
+	utmParams, err := shortUrlUtmParams(ctx, qtx, params.Username, params.Domain, params.ShortUrl)
+	if err != nil {
+		return err
+	}
+	if utmParams != nil {
+		if err := appendUtmParams(*utmParams, nil, nil, nil, params.Schedule); err != nil {
+			return err
+		}
+	}
+
+	if err := qtx.DeleteShortUrlSchedule(ctx, postgres_repo.DeleteShortUrlScheduleParams{Domain: params.Domain, ShortUrl: params.ShortUrl}); err != nil {
+		return fmt.Errorf("error deleting short url schedule: %w", err)
+	}
+	if err := insertSchedule(ctx, qtx, params.Domain, params.ShortUrl, params.Schedule); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error commiting tx: %w", err)
+	}
+
+	me.invalidateRedirectInfo(ctx, params.Domain, params.ShortUrl)
+
+	return nil
+}
+
+func insertSchedule(ctx context.Context, queries *postgres_repo.Queries, domain string, shortUrl string, schedule []utils.ScheduleEntry) error {
+	if len(schedule) == 0 {
+		return nil
+	}
+
+	rawJson, err := json.Marshal(schedule)
+	if err != nil {
+		return fmt.Errorf("error marshaling short url schedule: %w", err)
+	}
+
+	if err := queries.InsertShortUrlSchedule(ctx, postgres_repo.InsertShortUrlScheduleParams{
+		JsonSchedule: rawJson,
+		Domain:       domain,
+		ShortUrl:     shortUrl,
+	}); err != nil {
+		return fmt.Errorf("error inserting short url schedule: %w", err)
+	}
+
+	return nil
+}
+
+// invalidateRedirectInfo drops the cached redirect info, so the next redirect reads the changes from the db.
+func (me *UrlService) invalidateRedirectInfo(ctx context.Context, domain string, shortUrl string) {
+	cacheKey := redirectInfoCacheKey(domain, shortUrl)
+	if err := me.cache.Do(ctx, me.cache.B().Del().Key(cacheKey).Build()).Error(); err != nil {
+		slog.Error("error deleting cache", "key", cacheKey, "err", err)
+	}
+}
+
+// shortUrlUtmParams locks a short url owned by username and returns the utm params of its campaign,
+// other users' short urls are reported as not found like in checkShortUrlOwner.
+func shortUrlUtmParams(ctx context.Context, queries *postgres_repo.Queries, username string, domain string, shortUrl string) (*utils.UtmParams, error) {
+	row, err := queries.GetShortUrlForUpdate(ctx, postgres_repo.GetShortUrlForUpdateParams{Domain: domain, ShortUrl: shortUrl})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: url not found", NotFoundErr)
+		}
+		return nil, fmt.Errorf("error getting short url: %w", err)
+	}
+	if row.Username != username {
+		return nil, fmt.Errorf("%w: url not found", NotFoundErr)
+	}
+	return campaignUtmParams(ctx, queries, username, row.CampaignID)
+}
+
+// short urls of other users are reported as not found, to not leak which ones exist
+func checkShortUrlOwner(ctx context.Context, queries *postgres_repo.Queries, username string, domain string, shortUrl string) error {
+	owner, err := queries.GetShortUrlOwner(ctx, postgres_repo.GetShortUrlOwnerParams{Domain: domain, ShortUrl: shortUrl})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: url not found", NotFoundErr)
+		}
+		return fmt.Errorf("error getting short url owner: %w", err)
+	}
+	if owner != username {
+		return fmt.Errorf("%w: url not found", NotFoundErr)
+	}
+	return nil
+}
+
+type ExportedShortUrl struct {
+	ShortUrl  string    `json:"shortUrl"`
+	LongUrl   string    `json:"longUrl"`
+	CreatedAt time.Time `json:"createdAt"`
+	Visits    *int64    `json:"visits,omitempty"` // only set when exported with visits
+}
+
+const exportPageSize = 1000
+
+// ExportShortUrls calls yield for every short url of username on domain, reading them from the db page by page.
+func (me *UrlService) ExportShortUrls(ctx context.Context, username string, domain string, withVisits bool, yield func(ExportedShortUrl) error) error {
+	afterShortUrl := ""
+	for {
+		page, err := me.queries.GetShortUrlsByUsername(ctx, postgres_repo.GetShortUrlsByUsernameParams{
+			WithVisits:    withVisits,
+			Username:      username,
+			Domain:        domain,
+			AfterShortUrl: afterShortUrl,
+			PageSize:      exportPageSize,
+		})
+		if err != nil {
+			return fmt.Errorf("error getting short urls: %w", err)
+		}
+
+		for _, it := range page {
+			exported := ExportedShortUrl{
+				ShortUrl:  it.ShortUrl,
+				LongUrl:   it.LongUrl,
+				CreatedAt: it.CreatedAt,
+			}
+			if withVisits {
+				exported.Visits = &it.Visits
+			}
+			if err := yield(exported); err != nil {
+				return err
+			}
+		}
+
+		if len(page) < exportPageSize {
+			return nil
+		}
+		afterShortUrl = page[len(page)-1].ShortUrl
+	}
+}
+
+// WriteExportedShortUrls exports the short urls of username on domain to w as csv or ndjson.
+func (me *UrlService) WriteExportedShortUrls(ctx context.Context, w io.Writer, username string, domain string, format string, withVisits bool) error {
+	if format == ImportFormatNdjson {
+		encoder := json.NewEncoder(w)
+		return me.ExportShortUrls(ctx, username, domain, withVisits, func(it ExportedShortUrl) error {
+			return encoder.Encode(it)
+		})
+	}
+
+	csvWriter := csv.NewWriter(w)
+	header := []string{"short_url", "long_url", "created_at"}
+	if withVisits {
+		header = append(header, "visits")
+	}
+	csvWriter.Write(header)
+
+	if err := me.ExportShortUrls(ctx, username, domain, withVisits, func(it ExportedShortUrl) error {
+		record := []string{it.ShortUrl, it.LongUrl, it.CreatedAt.Format(time.RFC3339)}
+		if it.Visits != nil {
+			record = append(record, strconv.FormatInt(*it.Visits, 10))
+		}
+		return csvWriter.Write(record)
+	}); err != nil {
+		return err
+	}
+	csvWriter.Flush()
+
+	return csvWriter.Error()
+}
+
+// ListedShortUrl is a short url with the metadata of its destination and the result of its last check.
+type ListedShortUrl struct {
+	ShortUrl  string             `json:"shortUrl"`
+	LongUrl   string             `json:"longUrl"`
+	CreatedAt time.Time          `json:"createdAt"`
+	Disabled  bool               `json:"disabled"`
+	Metadata  utils.PageMetadata `json:"metadata"`
+	LinkCheck *LinkCheck         `json:"linkCheck"` // nil until the destination is checked
+}
+
+type LinkCheck struct {
+	StatusCode int       `json:"statusCode,omitempty"`
+	FinalUrl   string    `json:"finalUrl,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Broken     bool      `json:"broken"`
+	CheckedAt  time.Time `json:"checkedAt"`
+}
+
+type ListShortUrlsParams struct {
+	Username      string
+	Domain        string
+	AfterShortUrl string
+	BrokenOnly    bool
+}
+
+const listPageSize = 100
+
+// ListShortUrls returns a page of the short urls of username on params.Domain ordered by short url,
+// starting after params.AfterShortUrl.
+func (me *UrlService) ListShortUrls(ctx context.Context, params ListShortUrlsParams) ([]ListedShortUrl, error) {
+	rows, err := me.queries.GetShortUrlsForListing(ctx, postgres_repo.GetShortUrlsForListingParams{
+		Username:      params.Username,
+		Domain:        params.Domain,
+		AfterShortUrl: params.AfterShortUrl,
+		BrokenOnly:    params.BrokenOnly,
+		PageSize:      listPageSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting short urls: %w", err)
+	}
+
+	listed := make([]ListedShortUrl, len(rows))
+	for i, it := range rows {
+		listed[i] = ListedShortUrl{
+			ShortUrl:  it.ShortUrl,
+			LongUrl:   it.LongUrl,
+			CreatedAt: it.CreatedAt,
+			Disabled:  it.Disabled,
+			Metadata: utils.PageMetadata{
+				Title:       it.Title,
+				Description: it.Description,
+				ImageUrl:    it.ImageUrl,
+			},
+		}
+		if it.CheckedAt.Valid {
+			listed[i].LinkCheck = &LinkCheck{
+				StatusCode: int(it.StatusCode),
+				FinalUrl:   it.FinalUrl,
+				Error:      it.Error,
+				Broken:     it.Broken,
+				CheckedAt:  it.CheckedAt.Time,
+			}
+		}
 	}
 
-	return longUrl, nil
+	return listed, nil
 }
 
 func (me *UrlService) StoreUrlVisit(visit UrlVisit) {
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/assaidy/url_shortener/config"
@@ -34,11 +35,22 @@ func (me *UserService) Stop() {}
 type CreateUserParams struct {
 	Username string `validate:"required,customUsername,max=20"`
 	Password string `validate:"required,customNoOuterSpaces,min=8,max=50"`
+	Role     string `validate:"omitempty,oneof=user moderator admin"` // defaults to user, set by operators only
+
+	// the user registers themselves, so the usernames of config.Cfg.Auth.AdminUsernames are reserved
+	// for the accounts operators create
+	SelfRegistered bool
 }
 
 func (me *UserService) CreateUser(ctx context.Context, params CreateUserParams) error {
 	if err := utils.ValidateStruct(params); err != nil {
-		return fmt.Errorf("%w: %s", ValidationErr, err.Error())
+		return fmt.Errorf("%w: %w", ValidationErr, err)
+	}
+	if params.SelfRegistered && slices.Contains(config.Cfg.Auth.AdminUsernames, params.Username) {
+		return fmt.Errorf("%w: %s", ConflictErr, "username is reserved")
+	}
+	if params.Role == "" {
+		params.Role = RoleUser
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(params.Password), bcrypt.DefaultCost)
@@ -49,7 +61,11 @@ func (me *UserService) CreateUser(ctx context.Context, params CreateUserParams)
 	numAffectedRows, err := me.queries.InsertUser(ctx, postgres_repo.InsertUserParams{
 		Username:       params.Username,
 		HashedPassword: string(hashedPassword),
+		Role:           params.Role,
 	})
+	if err != nil {
+		return fmt.Errorf("error inserting user: %w", err)
+	}
 	if numAffectedRows == 0 {
 		return fmt.Errorf("%w: %s", ConflictErr, "username already exists")
 	}
@@ -74,7 +90,7 @@ func (me *UserService) AuthenticateUser(ctx context.Context, username string, pa
 	token, err := generateJWTAccessToken(JwtClaims{
 		Username: user.Username,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(config.JwtTokenExpiration) * time.Minute)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(config.Cfg.Auth.JwtTokenExpiration)),
 		},
 	})
 	if err != nil {
@@ -91,7 +107,7 @@ type JwtClaims struct {
 
 func generateJWTAccessToken(claims JwtClaims) (string, error) {
 	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return jwtToken.SignedString([]byte(config.SecretKey))
+	return jwtToken.SignedString([]byte(config.Cfg.Auth.SecretKey))
 }
 
 func (me *UserService) ParseJwtTokenString(tokenString string) (*JwtClaims, error) {
@@ -99,7 +115,7 @@ func (me *UserService) ParseJwtTokenString(tokenString string) (*JwtClaims, erro
 		if token.Method.Alg() != jwt.SigningMethodHS256.Name {
 			return nil, fmt.Errorf("unexpected signing method")
 		}
-		return []byte(config.SecretKey), nil
+		return []byte(config.Cfg.Auth.SecretKey), nil
 	})
 	if err != nil {
 		return nil, jwt.ErrTokenSignatureInvalid
@@ -123,6 +139,81 @@ func (me *UserService) DeleteUser(ctx context.Context, username string) error {
 	return nil
 }
 
+// roles of users, moderators handle abuse reports and admins also manage roles
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// GetUserRole returns the role of username. Users listed in config.Cfg.Auth.AdminUsernames are always admins,
+// so the first admin doesn't need to be set in the db.
+func (me *UserService) GetUserRole(ctx context.Context, username string) (string, error) {
+	if slices.Contains(config.Cfg.Auth.AdminUsernames, username) {
+		return RoleAdmin, nil
+	}
+
+	role, err := me.queries.GetUserRole(ctx, username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", fmt.Errorf("%w: user not found", NotFoundErr)
+		}
+		return "", fmt.Errorf("error getting user role: %w", err)
+	}
+	return role, nil
+}
+
+type SetUserRoleParams struct {
+	Username string `validate:"required"`
+	Role     string `validate:"required,oneof=user moderator admin"`
+}
+
+func (me *UserService) SetUserRole(ctx context.Context, params SetUserRoleParams) error {
+	if err := utils.ValidateStruct(params); err != nil {
+		return fmt.Errorf("%w: %w", ValidationErr, err)
+	}
+
+	if numAffectedRows, err := me.queries.SetUserRole(ctx, postgres_repo.SetUserRoleParams{
+		Username: params.Username,
+		Role:     params.Role,
+	}); err != nil {
+		return fmt.Errorf("error setting user role: %w", err)
+	} else if numAffectedRows == 0 {
+		return fmt.Errorf("%w: user not found", NotFoundErr)
+	}
+
+	return nil
+}
+
+type ListedUser struct {
+	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+const usersPageSize = 100
+
+// GetUsers returns a page of the users ordered by username, starting after afterUsername.
+func (me *UserService) GetUsers(ctx context.Context, afterUsername string) ([]ListedUser, error) {
+	rows, err := me.queries.GetUsers(ctx, postgres_repo.GetUsersParams{
+		AfterUsername: afterUsername,
+		PageSize:      usersPageSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting users: %w", err)
+	}
+
+	users := make([]ListedUser, len(rows))
+	for i, it := range rows {
+		users[i] = ListedUser{Username: it.Username, Role: it.Role, CreatedAt: it.CreatedAt}
+		if slices.Contains(config.Cfg.Auth.AdminUsernames, it.Username) {
+			users[i].Role = RoleAdmin
+		}
+	}
+
+	return users, nil
+}
+
 func (me *UserService) CheckUsername(ctx context.Context, username string) (bool, error) {
 	ok, err := me.queries.CheckUsername(ctx, username)
 	if err != nil {
@@ -0,0 +1,173 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// DestinationChecker rejects destinations of short urls, returning the reason as the error.
+type DestinationChecker interface {
+	CheckDestination(destination *url.URL) error
+}
+
+// DestinationCheckerFunc adapts a function to a DestinationChecker.
+type DestinationCheckerFunc func(destination *url.URL) error
+
+func (me DestinationCheckerFunc) CheckDestination(destination *url.URL) error {
+	return me(destination)
+}
+
+// CheckDestination parses rawUrl and runs it through checkers in order, returning the first rejection.
+func CheckDestination(rawUrl string, checkers ...DestinationChecker) error {
+	destination, err := url.Parse(rawUrl)
+	if err != nil {
+		return fmt.Errorf("invalid url")
+	}
+	for _, it := range checkers {
+		if err := it.CheckDestination(destination); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SchemeAllowlist rejects destinations whose scheme isn't one of schemes.
+func SchemeAllowlist(schemes ...string) DestinationChecker {
+	return DestinationCheckerFunc(func(destination *url.URL) error {
+		if !slices.Contains(schemes, strings.ToLower(destination.Scheme)) {
+			return fmt.Errorf("scheme %q is not allowed", destination.Scheme)
+		}
+		return nil
+	})
+}
+
+// SelfReference rejects destinations on hosts (our own, ports are ignored), which would redirect to
+// another short url or loop back to the same one.
+func SelfReference(hosts ...string) DestinationChecker {
+	return DestinationCheckerFunc(func(destination *url.URL) error {
+		host := normalizeHost(destination.Hostname())
+		for _, it := range hosts {
+			if hostOnly, _, err := net.SplitHostPort(it); err == nil {
+				it = hostOnly
+			}
+			if host == normalizeHost(it) {
+				return fmt.Errorf("destination points back at this service")
+			}
+		}
+		return nil
+	})
+}
+
+// PrivateAddress rejects destinations on loopback, private, link local or unspecified IPs and on
+// local only host names. Host names aren't resolved, so it can't catch public names of private IPs.
+func PrivateAddress() DestinationChecker {
+	return DestinationCheckerFunc(func(destination *url.URL) error {
+		host := normalizeHost(destination.Hostname())
+		if host == "" {
+			return fmt.Errorf("destination has no host")
+		}
+
+		if addr, err := netip.ParseAddr(host); err == nil {
+			if IsPrivateAddr(addr) {
+				return fmt.Errorf("destination is a private address")
+			}
+			return nil
+		}
+
+		if host == "localhost" || !strings.Contains(host, ".") {
+			return fmt.Errorf("destination is a local host")
+		}
+		for _, it := range []string{".localhost", ".local", ".internal", ".lan", ".home.arpa"} {
+			if strings.HasSuffix(host, it) {
+				return fmt.Errorf("destination is a local host")
+			}
+		}
+		return nil
+	})
+}
+
+// IsPrivateAddr reports whether addr is a loopback, private, link local, unspecified or multicast IP.
+func IsPrivateAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsUnspecified() || addr.IsMulticast() || addr.IsInterfaceLocalMulticast()
+}
+
+// Blocklist holds blocked domains, which also block their subdomains, and blocked url prefixes.
+type Blocklist struct {
+	domains     map[string]struct{}
+	urlPrefixes []string
+}
+
+// ParseBlocklist reads one entry per line: a domain (e.g. "evil.com") or a url prefix
+// (e.g. "https://example.com/phishing/"). Empty lines and lines starting with # are ignored.
+func ParseBlocklist(r io.Reader) (*Blocklist, error) {
+	blocklist := &Blocklist{domains: map[string]struct{}{}}
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.Contains(line, "://") {
+			prefix, err := url.Parse(line)
+			if err != nil || prefix.Host == "" {
+				return nil, fmt.Errorf("invalid url at line %d", lineNumber)
+			}
+			prefix.Scheme = strings.ToLower(prefix.Scheme)
+			prefix.Host = normalizeHost(prefix.Host)
+			blocklist.urlPrefixes = append(blocklist.urlPrefixes, prefix.String())
+			continue
+		}
+
+		blocklist.domains[normalizeHost(line)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading blocklist: %w", err)
+	}
+
+	return blocklist, nil
+}
+
+func (me *Blocklist) Len() int {
+	return len(me.domains) + len(me.urlPrefixes)
+}
+
+func (me *Blocklist) CheckDestination(destination *url.URL) error {
+	host := normalizeHost(destination.Hostname())
+	for domain := host; domain != ""; {
+		if _, ok := me.domains[domain]; ok {
+			return fmt.Errorf("domain %s is blocked", domain)
+		}
+		_, parent, found := strings.Cut(domain, ".")
+		if !found {
+			break
+		}
+		domain = parent
+	}
+
+	normalized := *destination
+	normalized.Scheme = strings.ToLower(normalized.Scheme)
+	normalized.Host = normalizeHost(normalized.Host)
+	rawUrl := normalized.String()
+	for _, it := range me.urlPrefixes {
+		if strings.HasPrefix(rawUrl, it) {
+			return fmt.Errorf("url is blocked")
+		}
+	}
+
+	return nil
+}
+
+// lower cased, without the trailing dot of fully qualified names
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
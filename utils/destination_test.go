@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckDestination(t *testing.T) {
+	blocklist, err := ParseBlocklist(strings.NewReader(`
+# phishing
+evil.com
+bad.example.org.
+https://example.com/phishing/
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, blocklist.Len())
+
+	checkers := []DestinationChecker{
+		SchemeAllowlist("http", "https"),
+		SelfReference("sho.rt", "localhost:8080"),
+		PrivateAddress(),
+		blocklist,
+	}
+
+	tests := []struct {
+		name    string
+		url     string
+		allowed bool
+	}{
+		{"public", "https://example.com/page", true},
+		{"http", "http://example.com", true},
+		{"javascript scheme", "javascript:alert(1)", false},
+		{"ftp scheme", "ftp://example.com/file", false},
+		{"self reference", "https://sho.rt/urls/abc", false},
+		{"self reference with trailing dot", "https://SHO.RT./urls/abc", false},
+		{"loopback ip", "http://127.0.0.1:8080/admin", false},
+		{"private ip", "http://10.1.2.3/", false},
+		{"ipv6 loopback", "http://[::1]/", false},
+		{"mapped private ip", "http://[::ffff:192.168.1.1]/", false},
+		{"link local metadata", "http://169.254.169.254/latest/meta-data", false},
+		{"public ip", "http://93.184.216.34/", true},
+		{"localhost", "http://localhost/", false},
+		{"local name", "http://printer.local/", false},
+		{"single label", "http://intranet/", false},
+		{"blocked domain", "https://evil.com/login", false},
+		{"blocked subdomain", "https://login.EVIL.com/", false},
+		{"blocked fqdn entry", "https://bad.example.org/", false},
+		{"other domain suffix", "https://notevil.com/", true},
+		{"blocked prefix", "https://example.com/phishing/login", false},
+		{"blocked prefix other case host", "https://EXAMPLE.com/phishing/login", false},
+		{"outside blocked prefix", "https://example.com/phishing", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckDestination(tt.url, checkers...)
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestParseBlocklistInvalidUrl(t *testing.T) {
+	_, err := ParseBlocklist(strings.NewReader("evil.com\nhttps://\n"))
+	assert.ErrorContains(t, err, "line 2")
+}
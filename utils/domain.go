@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// TxtResolver looks up TXT records. *net.Resolver is the real one, FakeTxtResolver stands in for
+// it in tests and local setups.
+type TxtResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// FakeTxtResolver answers TXT lookups from a map of names to records instead of the dns.
+type FakeTxtResolver map[string][]string
+
+func (me FakeTxtResolver) LookupTXT(_ context.Context, name string) ([]string, error) {
+	records, ok := me[NormalizeDomain(name)]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+// ParseFakeTxtRecords parses "name=value" pairs into a FakeTxtResolver. Values may contain "=".
+func ParseFakeTxtRecords(pairs []string) (FakeTxtResolver, error) {
+	resolver := FakeTxtResolver{}
+	for _, it := range pairs {
+		name, value, ok := strings.Cut(it, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid txt record %q, expected name=value", it)
+		}
+		name = NormalizeDomain(name)
+		resolver[name] = append(resolver[name], value)
+	}
+	return resolver, nil
+}
+
+// NormalizeDomain lower cases domain and drops the trailing dot of fully qualified names.
+func NormalizeDomain(domain string) string {
+	return normalizeHost(strings.TrimSpace(domain))
+}
+
+// DomainVerificationRecord is the TXT record proving the ownership of a domain.
+type DomainVerificationRecord struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func NewDomainVerificationRecord(domain string, token string) DomainVerificationRecord {
+	return DomainVerificationRecord{
+		Name:  "_url-shortener." + domain,
+		Value: "url-shortener-verification=" + token,
+	}
+}
+
+// VerifyDomainRecord reports whether record is among the TXT records of its name. A name without
+// records isn't an error, the record is just missing.
+func VerifyDomainRecord(ctx context.Context, resolver TxtResolver, record DomainVerificationRecord) (bool, error) {
+	records, err := resolver.LookupTXT(ctx, record.Name)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return false, nil
+		}
+		return false, fmt.Errorf("error looking up %s: %w", record.Name, err)
+	}
+
+	for _, it := range records {
+		if strings.TrimSpace(it) == record.Value {
+			return true, nil
+		}
+	}
+	return false, nil
+}
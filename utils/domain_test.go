@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyDomainRecord(t *testing.T) {
+	resolver, err := ParseFakeTxtRecords([]string{
+		"_url-shortener.go.example.com=url-shortener-verification=abc",
+		"_url-shortener.Brand.Link.=v=spf1 -all",
+		"_url-shortener.brand.link= url-shortener-verification=def ",
+	})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		domain   string
+		token    string
+		expected bool
+	}{
+		{"matching record", "go.example.com", "abc", true},
+		{"other token", "go.example.com", "xyz", false},
+		{"among other records", "brand.link", "def", true},
+		{"no records", "other.example.com", "abc", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verified, err := VerifyDomainRecord(context.Background(), resolver, NewDomainVerificationRecord(tt.domain, tt.token))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, verified)
+		})
+	}
+}
+
+type failingTxtResolver struct{}
+
+func (failingTxtResolver) LookupTXT(context.Context, string) ([]string, error) {
+	return nil, errors.New("server misbehaving")
+}
+
+func TestVerifyDomainRecordLookupError(t *testing.T) {
+	_, err := VerifyDomainRecord(context.Background(), failingTxtResolver{}, NewDomainVerificationRecord("go.example.com", "abc"))
+	assert.Error(t, err)
+}
+
+func TestParseFakeTxtRecords(t *testing.T) {
+	_, err := ParseFakeTxtRecords([]string{"no-value"})
+	assert.Error(t, err)
+	_, err = ParseFakeTxtRecords([]string{"=value"})
+	assert.Error(t, err)
+}
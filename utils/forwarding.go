@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// query forwarding modes, deciding which value wins when both urls have the same key
+const (
+	QueryForwardingOff             = ""
+	QueryForwardingIncomingWins    = "incoming-wins"
+	QueryForwardingDestinationWins = "destination-wins"
+	QueryForwardingKeepBoth        = "keep-both"
+)
+
+// ForwardUrl appends the escaped forwardedPath to longUrl (when not empty) and merges rawQuery
+// into its query according to queryForwarding.
+func ForwardUrl(longUrl string, forwardedPath string, rawQuery string, queryForwarding string) (string, error) {
+	if forwardedPath == "" && (rawQuery == "" || queryForwarding == QueryForwardingOff) {
+		return longUrl, nil
+	}
+
+	destination, err := url.Parse(longUrl)
+	if err != nil {
+		return "", fmt.Errorf("invalid long url: %w", err)
+	}
+
+	if forwardedPath != "" {
+		// dot segments are cleaned before joining, so the path can't climb above the long url path
+		cleanPath := path.Clean("/" + forwardedPath)
+		if strings.HasSuffix(forwardedPath, "/") && cleanPath != "/" {
+			cleanPath += "/"
+		}
+		destination = destination.JoinPath(cleanPath)
+	}
+
+	if rawQuery != "" && queryForwarding != QueryForwardingOff {
+		if _, err := url.ParseQuery(rawQuery); err != nil {
+			return "", fmt.Errorf("invalid query: %w", err)
+		}
+		destination.RawQuery = mergeRawQuery(destination.RawQuery, rawQuery, queryForwarding)
+	}
+
+	return destination.String(), nil
+}
+
+// mergeRawQuery merges the pairs of incoming into destination as they are written, so the pairs of
+// the long url keep their order and escaping. Pairs of destination are only rewritten when incoming
+// wins their key.
+func mergeRawQuery(destination string, incoming string, queryForwarding string) string {
+	incomingPairs := splitRawQuery(incoming)
+	destinationPairs := splitRawQuery(destination)
+
+	incomingKeys := map[string]bool{}
+	for _, it := range incomingPairs {
+		incomingKeys[rawQueryKey(it)] = true
+	}
+	destinationKeys := map[string]bool{}
+	for _, it := range destinationPairs {
+		destinationKeys[rawQueryKey(it)] = true
+	}
+
+	pairs := []string{}
+	switch queryForwarding {
+	case QueryForwardingIncomingWins:
+		// the incoming values of a key take the place of its first value in destination
+		written := map[string]bool{}
+		for _, it := range destinationPairs {
+			key := rawQueryKey(it)
+			if !incomingKeys[key] {
+				pairs = append(pairs, it)
+				continue
+			}
+			if !written[key] {
+				written[key] = true
+				for _, incomingPair := range incomingPairs {
+					if rawQueryKey(incomingPair) == key {
+						pairs = append(pairs, incomingPair)
+					}
+				}
+			}
+		}
+		for _, it := range incomingPairs {
+			if !destinationKeys[rawQueryKey(it)] {
+				pairs = append(pairs, it)
+			}
+		}
+	case QueryForwardingDestinationWins:
+		pairs = append(pairs, destinationPairs...)
+		for _, it := range incomingPairs {
+			if !destinationKeys[rawQueryKey(it)] {
+				pairs = append(pairs, it)
+			}
+		}
+	case QueryForwardingKeepBoth:
+		pairs = append(append(pairs, destinationPairs...), incomingPairs...)
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+func splitRawQuery(rawQuery string) []string {
+	pairs := []string{}
+	for it := range strings.SplitSeq(rawQuery, "&") {
+		if it != "" {
+			pairs = append(pairs, it)
+		}
+	}
+	return pairs
+}
+
+// rawQueryKey returns the unescaped key of a pair, or the key as written when it can't be unescaped.
+func rawQueryKey(pair string) string {
+	key, _, _ := strings.Cut(pair, "=")
+	if unescaped, err := url.QueryUnescape(key); err == nil {
+		return unescaped
+	}
+	return key
+}
+
+// VisitSourceParam marks how visitors got a short url, e.g. ?src=qr on the urls encoded in QR codes.
+const (
+	VisitSourceParam = "src"
+	VisitSourceQr    = "qr"
+)
+
+// CutVisitSource removes a known source marker from rawQuery, so it isn't forwarded, and returns
+// it. Other values of the param are left in place since they may be meant for the destination.
+func CutVisitSource(rawQuery string) (source string, rest string) {
+	params := strings.Split(rawQuery, "&")
+	for i, it := range params {
+		if it == VisitSourceParam+"="+VisitSourceQr {
+			return VisitSourceQr, strings.Join(append(params[:i:i], params[i+1:]...), "&")
+		}
+	}
+	return "", rawQuery
+}
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForwardUrl(t *testing.T) {
+	tests := []struct {
+		name            string
+		longUrl         string
+		path            string
+		rawQuery        string
+		queryForwarding string
+		want            string
+	}{
+		{"nothing to forward", "https://example.com/docs?a=1", "", "", QueryForwardingIncomingWins, "https://example.com/docs?a=1"},
+		{"query forwarding off", "https://example.com/docs?a=1", "", "b=2", QueryForwardingOff, "https://example.com/docs?a=1"},
+		{"path", "https://example.com/docs", "api/v2", "", QueryForwardingOff, "https://example.com/docs/api/v2"},
+		{"path with trailing slash", "https://example.com/docs/", "api/v2/", "", QueryForwardingOff, "https://example.com/docs/api/v2/"},
+		{"path keeps long url query", "https://example.com/docs?a=1", "api", "", QueryForwardingOff, "https://example.com/docs/api?a=1"},
+		{"path can't escape long url path", "https://example.com/docs", "../../admin", "", QueryForwardingOff, "https://example.com/docs/admin"},
+		{"escaped path", "https://example.com/docs", "a%20b", "", QueryForwardingOff, "https://example.com/docs/a%20b"},
+		{"incoming wins", "https://example.com/?a=1&b=2", "", "a=3&c=4", QueryForwardingIncomingWins, "https://example.com/?a=3&b=2&c=4"},
+		{"destination wins", "https://example.com/?a=1&b=2", "", "a=3&c=4", QueryForwardingDestinationWins, "https://example.com/?a=1&b=2&c=4"},
+		{"keep both", "https://example.com/?a=1", "", "a=3", QueryForwardingKeepBoth, "https://example.com/?a=1&a=3"},
+		{"path and query", "https://example.com/docs", "api", "utm_source=x", QueryForwardingIncomingWins, "https://example.com/docs/api?utm_source=x"},
+		{"long url query keeps its order", "https://example.com/?b=2&a=1", "", "c=3", QueryForwardingIncomingWins, "https://example.com/?b=2&a=1&c=3"},
+		{"long url query keeps its escaping", "https://example.com/?q=a%20b&r=x+y", "", "c=3", QueryForwardingDestinationWins, "https://example.com/?q=a%20b&r=x+y&c=3"},
+		{"incoming query keeps its escaping", "https://example.com/?a=1", "", "q=a%20b", QueryForwardingKeepBoth, "https://example.com/?a=1&q=a%20b"},
+		{"incoming wins every value of a key", "https://example.com/?a=1&b=2&a=5", "", "a=3&a=4", QueryForwardingIncomingWins, "https://example.com/?a=3&a=4&b=2"},
+		{"escaped keys are the same key", "https://example.com/?utm%5Fsource=a", "", "utm_source=b", QueryForwardingDestinationWins, "https://example.com/?utm%5Fsource=a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ForwardUrl(tt.longUrl, tt.path, tt.rawQuery, tt.queryForwarding)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCutVisitSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		rawQuery string
+		source   string
+		rest     string
+	}{
+		{"no query", "", "", ""},
+		{"only marker", "src=qr", VisitSourceQr, ""},
+		{"marker first", "src=qr&a=1", VisitSourceQr, "a=1"},
+		{"marker in the middle", "a=1&src=qr&b=2", VisitSourceQr, "a=1&b=2"},
+		{"other source is forwarded", "src=newsletter&a=1", "", "src=newsletter&a=1"},
+		{"other params", "a=1&b=%20", "", "a=1&b=%20"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, rest := CutVisitSource(tt.rawQuery)
+			assert.Equal(t, tt.source, source)
+			assert.Equal(t, tt.rest, rest)
+		})
+	}
+}
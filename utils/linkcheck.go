@@ -0,0 +1,147 @@
+package utils
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// LinkCheckResult is the outcome of probing a destination. A zero CheckedAt means the check
+// didn't run because the context was done.
+type LinkCheckResult struct {
+	Url        string
+	StatusCode int    // of the final response, 0 when there was none
+	FinalUrl   string // after following redirects
+	Error      string
+	Broken     bool
+	CheckedAt  time.Time
+}
+
+// LinkChecker probes destinations with HEAD, falling back to GET when HEAD fails with an error or
+// status, since many servers don't handle it properly.
+type LinkChecker struct {
+	Client      *http.Client  // http.DefaultClient when nil
+	Timeout     time.Duration // of each request, no timeout when 0
+	Concurrency int           // hosts checked at once
+	HostDelay   time.Duration // between two checks of the same host
+	UserAgent   string
+}
+
+// statuses sites use to turn away bots, which don't say anything about the destination
+var inconclusiveStatuses = []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests}
+
+func (me *LinkChecker) Check(ctx context.Context, rawUrl string) LinkCheckResult {
+	resp, err := me.request(ctx, http.MethodHead, rawUrl)
+	if ctx.Err() == nil && (err != nil || resp.StatusCode >= 400) {
+		resp, err = me.request(ctx, http.MethodGet, rawUrl)
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return LinkCheckResult{Url: rawUrl}
+		}
+		return LinkCheckResult{Url: rawUrl, Error: err.Error(), Broken: true, CheckedAt: time.Now().UTC()}
+	}
+
+	result := LinkCheckResult{
+		Url:        rawUrl,
+		StatusCode: resp.StatusCode,
+		FinalUrl:   resp.Request.URL.String(),
+		CheckedAt:  time.Now().UTC(),
+	}
+	if resp.StatusCode >= 400 {
+		result.Broken = true
+		for _, it := range inconclusiveStatuses {
+			if resp.StatusCode == it {
+				result.Broken = false
+			}
+		}
+	}
+	return result
+}
+
+// request sends a request and closes the response body, only the status and the final url are needed.
+func (me *LinkChecker) request(ctx context.Context, method string, rawUrl string) (*http.Response, error) {
+	if me.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, me.Timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rawUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	if me.UserAgent != "" {
+		req.Header.Set("User-Agent", me.UserAgent)
+	}
+
+	client := me.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	// drain a little so the connection can be reused
+	io.CopyN(io.Discard, resp.Body, 64*1024)
+	resp.Body.Close()
+
+	return resp, nil
+}
+
+// CheckAll probes urls and returns their results in the same order. Urls on the same host (and
+// port) are checked one after the other, HostDelay apart, and at most Concurrency hosts at once.
+func (me *LinkChecker) CheckAll(ctx context.Context, urls []string) []LinkCheckResult {
+	results := make([]LinkCheckResult, len(urls))
+	for i, it := range urls {
+		results[i].Url = it
+	}
+
+	hosts := []string{}
+	urlsByHost := map[string][]int{}
+	for i, it := range urls {
+		host := ""
+		if parsed, err := url.Parse(it); err == nil {
+			host = normalizeHost(parsed.Host)
+		}
+		if _, ok := urlsByHost[host]; !ok {
+			hosts = append(hosts, host)
+		}
+		urlsByHost[host] = append(urlsByHost[host], i)
+	}
+
+	slots := make(chan struct{}, max(me.Concurrency, 1))
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for _, host := range hosts {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return results
+		}
+
+		wg.Add(1)
+		go func(indexes []int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			for n, i := range indexes {
+				if n != 0 && me.HostDelay > 0 {
+					select {
+					case <-time.After(me.HostDelay):
+					case <-ctx.Done():
+						return
+					}
+				}
+				results[i] = me.Check(ctx, urls[i])
+			}
+		}(urlsByHost[host])
+	}
+
+	return results
+}
@@ -0,0 +1,187 @@
+package utils
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinkCheckerCheck(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/ok", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	})
+	mux.HandleFunc("/error", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	mux.HandleFunc("/forbidden", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+	mux.HandleFunc("/no-head", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/drops-head", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+		}
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	})
+	mux.HandleFunc("/user-agent", func(w http.ResponseWriter, r *http.Request) {
+		if r.UserAgent() != "link checker" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	closedServer := httptest.NewServer(mux)
+	closedServer.Close()
+
+	checker := &LinkChecker{Timeout: 100 * time.Millisecond, UserAgent: "link checker"}
+
+	tests := []struct {
+		name       string
+		url        string
+		statusCode int
+		finalUrl   string
+		broken     bool
+		hasError   bool
+	}{
+		{"ok", server.URL + "/ok", 200, server.URL + "/ok", false, false},
+		{"redirect", server.URL + "/old", 200, server.URL + "/ok", false, false},
+		{"not found", server.URL + "/missing", 404, server.URL + "/missing", true, false},
+		{"gone", server.URL + "/gone", 410, server.URL + "/gone", true, false},
+		{"server error", server.URL + "/error", 500, server.URL + "/error", true, false},
+		{"forbidden is inconclusive", server.URL + "/forbidden", 403, server.URL + "/forbidden", false, false},
+		{"falls back to get", server.URL + "/no-head", 200, server.URL + "/no-head", false, false},
+		{"falls back to get when head is dropped", server.URL + "/drops-head", 200, server.URL + "/drops-head", false, false},
+		{"sends user agent", server.URL + "/user-agent", 200, server.URL + "/user-agent", false, false},
+		{"redirect loop", server.URL + "/loop", 0, "", true, true},
+		{"timeout", server.URL + "/slow", 0, "", true, true},
+		{"connection refused", closedServer.URL + "/ok", 0, "", true, true},
+		{"invalid url", "http://[::1", 0, "", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := checker.Check(context.Background(), tt.url)
+			assert.Equal(t, tt.url, result.Url)
+			assert.Equal(t, tt.statusCode, result.StatusCode)
+			assert.Equal(t, tt.finalUrl, result.FinalUrl)
+			assert.Equal(t, tt.broken, result.Broken)
+			assert.Equal(t, tt.hasError, result.Error != "")
+			assert.False(t, result.CheckedAt.IsZero())
+		})
+	}
+}
+
+func TestLinkCheckerCheckCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	result := (&LinkChecker{}).Check(ctx, server.URL)
+	assert.True(t, result.CheckedAt.IsZero())
+	assert.False(t, result.Broken)
+}
+
+func TestLinkCheckerCheckAllHostDelay(t *testing.T) {
+	var mu sync.Mutex
+	requestTimes := []time.Time{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requestTimes = append(requestTimes, time.Now())
+		mu.Unlock()
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	checker := &LinkChecker{Concurrency: 4, HostDelay: 50 * time.Millisecond}
+	results := checker.CheckAll(context.Background(), []string{server.URL + "/a", server.URL + "/missing", server.URL + "/b"})
+
+	assert.Len(t, results, 3)
+	assert.Equal(t, server.URL+"/a", results[0].Url)
+	assert.False(t, results[0].Broken)
+	assert.Equal(t, server.URL+"/missing", results[1].Url)
+	assert.True(t, results[1].Broken)
+	assert.Equal(t, server.URL+"/b", results[2].Url)
+	assert.False(t, results[2].Broken)
+
+	// HEAD and GET of /missing count as one check
+	assert.Len(t, requestTimes, 4)
+	assert.GreaterOrEqual(t, requestTimes[1].Sub(requestTimes[0]), 50*time.Millisecond)
+	assert.GreaterOrEqual(t, requestTimes[3].Sub(requestTimes[2]), 50*time.Millisecond)
+}
+
+func TestLinkCheckerCheckAllConcurrency(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			current := maxInFlight.Load()
+			if n <= current || maxInFlight.CompareAndSwap(current, n) {
+				break
+			}
+		}
+		time.Sleep(30 * time.Millisecond)
+	})
+
+	// every server listens on its own port, so it counts as its own host
+	urls := []string{}
+	for range 5 {
+		server := httptest.NewServer(handler)
+		defer server.Close()
+		urls = append(urls, server.URL)
+	}
+
+	checker := &LinkChecker{Concurrency: 2}
+	results := checker.CheckAll(context.Background(), urls)
+
+	for i, it := range results {
+		assert.Equal(t, urls[i], it.Url)
+		assert.Equal(t, 200, it.StatusCode)
+	}
+	assert.Equal(t, int32(2), maxInFlight.Load())
+}
+
+func TestLinkCheckerCheckAllCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := (&LinkChecker{}).CheckAll(ctx, []string{server.URL + "/a", server.URL + "/b"})
+	assert.Len(t, results, 2)
+	for _, it := range results {
+		assert.True(t, it.CheckedAt.IsZero())
+	}
+}
@@ -0,0 +1,206 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// PageMetadata is what link previews show about a page.
+type PageMetadata struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	ImageUrl    string `json:"imageUrl,omitempty"`
+}
+
+func (me PageMetadata) IsZero() bool {
+	return me == PageMetadata{}
+}
+
+// limits of the metadata fields, longer values are cut
+const (
+	MaxMetadataTitleLength       = 300
+	MaxMetadataDescriptionLength = 1000
+)
+
+// ParsePageMetadata reads the og:title, og:description and og:image meta tags of an html page,
+// falling back to the <title> and the description meta tag. Relative image urls are resolved
+// against pageUrl. Parsing stops at <body>, since the tags belong to the head.
+func ParsePageMetadata(r io.Reader, pageUrl *url.URL) PageMetadata {
+	var og PageMetadata
+	title, description := "", ""
+	inTitle := false
+
+	tokenizer := html.NewTokenizer(r)
+parsing:
+	for {
+		tokenType := tokenizer.Next()
+		switch tokenType {
+		case html.ErrorToken: // EOF, or the body was cut short
+			break parsing
+		case html.TextToken:
+			if inTitle {
+				title += string(tokenizer.Text())
+			}
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			switch string(name) {
+			case "title":
+				inTitle = false
+			case "head":
+				break parsing
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttrs := tokenizer.TagName()
+			switch string(name) {
+			case "body":
+				break parsing
+			case "title":
+				inTitle = tokenType == html.StartTagToken && title == ""
+			case "meta":
+				property, content := "", ""
+				for hasAttrs {
+					var key, value []byte
+					key, value, hasAttrs = tokenizer.TagAttr()
+					switch string(key) {
+					case "property", "name":
+						property = strings.ToLower(strings.TrimSpace(string(value)))
+					case "content":
+						content = string(value)
+					}
+				}
+				switch property {
+				case "og:title":
+					og.Title = firstNonEmpty(og.Title, content)
+				case "og:description":
+					og.Description = firstNonEmpty(og.Description, content)
+				case "og:image", "og:image:url", "og:image:secure_url":
+					og.ImageUrl = firstNonEmpty(og.ImageUrl, content)
+				case "description":
+					description = firstNonEmpty(description, content)
+				}
+			}
+		}
+	}
+
+	return PageMetadata{
+		Title:       cleanMetadataText(firstNonEmpty(og.Title, title), MaxMetadataTitleLength),
+		Description: cleanMetadataText(firstNonEmpty(og.Description, description), MaxMetadataDescriptionLength),
+		ImageUrl:    resolveImageUrl(og.ImageUrl, pageUrl),
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, it := range values {
+		if strings.TrimSpace(it) != "" {
+			return it
+		}
+	}
+	return ""
+}
+
+// collapses whitespace and cuts the text to maxLength runes
+func cleanMetadataText(text string, maxLength int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > maxLength {
+		text = strings.TrimSpace(string(runes[:maxLength-1])) + "…"
+	}
+	return text
+}
+
+// only absolute http(s) image urls are kept
+func resolveImageUrl(rawUrl string, pageUrl *url.URL) string {
+	imageUrl, err := url.Parse(strings.TrimSpace(rawUrl))
+	if err != nil || rawUrl == "" {
+		return ""
+	}
+	if pageUrl != nil {
+		imageUrl = pageUrl.ResolveReference(imageUrl)
+	}
+	if imageUrl.Scheme != "http" && imageUrl.Scheme != "https" {
+		return ""
+	}
+	return imageUrl.String()
+}
+
+// PageMetadataFetcher downloads pages within a time and size budget to read their metadata.
+type PageMetadataFetcher struct {
+	Client    *http.Client  // http.DefaultClient when nil
+	Timeout   time.Duration // of the whole fetch, no timeout when 0
+	MaxBytes  int64         // of the body that is read, the metadata is in the head anyway
+	UserAgent string
+}
+
+// Fetch returns the metadata of the page at rawUrl. Pages that aren't html have none.
+func (me *PageMetadataFetcher) Fetch(ctx context.Context, rawUrl string) (PageMetadata, error) {
+	if me.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, me.Timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawUrl, nil)
+	if err != nil {
+		return PageMetadata{}, err
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.1")
+	if me.UserAgent != "" {
+		req.Header.Set("User-Agent", me.UserAgent)
+	}
+
+	client := me.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return PageMetadata{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return PageMetadata{}, fmt.Errorf("destination responded with status %d", resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return PageMetadata{}, nil
+	}
+
+	var body io.Reader = resp.Body
+	if me.MaxBytes > 0 {
+		body = io.LimitReader(body, me.MaxBytes)
+	}
+	// decodes other charsets, from the header or the page itself, to utf-8
+	if decoded, err := charset.NewReader(body, contentType); err == nil {
+		body = decoded
+	}
+
+	return ParsePageMetadata(body, resp.Request.URL), nil
+}
+
+// user agent tokens of the crawlers that build link previews for social networks and chat apps
+var previewCrawlers = []string{
+	"facebookexternalhit", "facebot", "twitterbot", "linkedinbot", "slackbot", "discordbot",
+	"telegrambot", "whatsapp", "pinterest", "redditbot", "embedly", "skypeuripreview", "vkshare",
+	"iframely", "mastodon", "bluesky", "applebot", "google-pagerenderer", "snapchat", "viber",
+}
+
+// IsPreviewCrawler reports whether userAgent belongs to a known link preview crawler.
+func IsPreviewCrawler(userAgent string) bool {
+	userAgent = strings.ToLower(userAgent)
+	for _, it := range previewCrawlers {
+		if strings.Contains(userAgent, it) {
+			return true
+		}
+	}
+	return false
+}
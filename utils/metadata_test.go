@@ -0,0 +1,166 @@
+package utils
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePageMetadata(t *testing.T) {
+	pageUrl, _ := url.Parse("https://example.com/blog/post")
+
+	tests := []struct {
+		name     string
+		html     string
+		expected PageMetadata
+	}{
+		{
+			"open graph",
+			`<html><head>
+				<title>Page title</title>
+				<meta property="og:title" content="OG &amp; title">
+				<meta property="og:description" content="OG description">
+				<meta property="og:image" content="https://cdn.example.com/image.png">
+			</head><body></body></html>`,
+			PageMetadata{"OG & title", "OG description", "https://cdn.example.com/image.png"},
+		},
+		{
+			"falls back to title and description",
+			`<html><head><title>
+				Page   title
+			</title><meta name="Description" content="Plain description"></head></html>`,
+			PageMetadata{"Page title", "Plain description", ""},
+		},
+		{
+			"relative image",
+			`<head><meta property="og:image" content="/images/cover.jpg"></head>`,
+			PageMetadata{"", "", "https://example.com/images/cover.jpg"},
+		},
+		{
+			"non http image",
+			`<head><meta property="og:image" content="data:image/png;base64,AAAA"></head>`,
+			PageMetadata{},
+		},
+		{
+			"first tag wins",
+			`<head><meta property="og:title" content="First"><meta property="og:title" content="Second"></head>`,
+			PageMetadata{"First", "", ""},
+		},
+		{
+			"stops at body",
+			`<head><title>Head</title></head><body><meta property="og:title" content="Body"></body>`,
+			PageMetadata{"Head", "", ""},
+		},
+		{
+			"no head",
+			`<p>just text</p>`,
+			PageMetadata{},
+		},
+		{
+			"cut short",
+			`<head><title>Unfinished`,
+			PageMetadata{"Unfinished", "", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ParsePageMetadata(strings.NewReader(tt.html), pageUrl))
+		})
+	}
+}
+
+func TestParsePageMetadataLimits(t *testing.T) {
+	html := `<head><title>` + strings.Repeat("a", 500) + `</title></head>`
+	metadata := ParsePageMetadata(strings.NewReader(html), nil)
+	assert.Len(t, []rune(metadata.Title), MaxMetadataTitleLength)
+	assert.True(t, strings.HasSuffix(metadata.Title, "…"))
+}
+
+func TestPageMetadataFetcherFetch(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<head><title>Page</title><meta property="og:image" content="/cover.png"></head>`))
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/page", http.StatusFound)
+	})
+	mux.HandleFunc("/latin1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=iso-8859-1")
+		w.Write([]byte("<head><title>Caf\xe9</title></head>"))
+	})
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<head><!-- ` + strings.Repeat("x", 4096) + ` --><title>Too far</title></head>`))
+	})
+	mux.HandleFunc("/image", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("<head><title>Not html</title></head>"))
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	fetcher := &PageMetadataFetcher{Timeout: 100 * time.Millisecond, MaxBytes: 1024}
+
+	tests := []struct {
+		name     string
+		path     string
+		expected PageMetadata
+		hasError bool
+	}{
+		{"html", "/page", PageMetadata{"Page", "", server.URL + "/cover.png"}, false},
+		{"image resolved against final url", "/moved", PageMetadata{"Page", "", server.URL + "/cover.png"}, false},
+		{"other charset", "/latin1", PageMetadata{"Café", "", ""}, false},
+		{"over the size budget", "/large", PageMetadata{}, false},
+		{"not html", "/image", PageMetadata{}, false},
+		{"not found", "/missing", PageMetadata{}, true},
+		{"over the time budget", "/slow", PageMetadata{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := fetcher.Fetch(context.Background(), server.URL+tt.path)
+			if tt.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, metadata)
+		})
+	}
+}
+
+func TestIsPreviewCrawler(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		expected  bool
+	}{
+		{"facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)", true},
+		{"Twitterbot/1.0", true},
+		{"Mozilla/5.0 (compatible; Discordbot/2.0; +https://discordapp.com)", true},
+		{"Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)", true},
+		{"WhatsApp/2.23.20.0", true},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0 Safari/537.36", false},
+		{"curl/8.4.0", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.userAgent, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsPreviewCrawler(tt.userAgent))
+		})
+	}
+}
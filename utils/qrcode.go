@@ -0,0 +1,203 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// QrCodeOptions controls how QR codes are rendered.
+type QrCodeOptions struct {
+	Size       int // width and height of PNGs in pixels, grown to at least one pixel per module
+	Margin     int // quiet zone around the code, in modules
+	Level      qrcode.RecoveryLevel
+	Foreground color.Color
+	Background color.Color
+	Logo       image.Image // drawn in the middle, a higher Level keeps the code readable
+}
+
+// the logo covers at most this fraction of the code width, so error correction can make up for it
+const qrLogoMaxFraction = 0.2
+
+// ParseQrRecoveryLevel parses the error correction levels L (7%), M (15%), Q (25%) and H (30%).
+func ParseQrRecoveryLevel(level string) (qrcode.RecoveryLevel, error) {
+	switch strings.ToUpper(level) {
+	case "L":
+		return qrcode.Low, nil
+	case "M":
+		return qrcode.Medium, nil
+	case "Q":
+		return qrcode.High, nil
+	case "H":
+		return qrcode.Highest, nil
+	}
+	return 0, fmt.Errorf("error correction level must be L, M, Q or H")
+}
+
+// ParseHexColor parses colors like "f80", "ff8800" or "ff880080" (with alpha), with or without a leading #.
+func ParseHexColor(value string) (color.NRGBA, error) {
+	value = strings.TrimPrefix(value, "#")
+	if len(value) == 3 {
+		value = string([]byte{value[0], value[0], value[1], value[1], value[2], value[2]})
+	}
+	if len(value) == 6 {
+		value += "ff"
+	}
+
+	decoded, err := hex.DecodeString(value)
+	if err != nil || len(decoded) != 4 {
+		return color.NRGBA{}, fmt.Errorf("invalid color %q", value)
+	}
+	return color.NRGBA{R: decoded[0], G: decoded[1], B: decoded[2], A: decoded[3]}, nil
+}
+
+// qrModules returns the dark modules of the code for content, without the quiet zone.
+func qrModules(content string, level qrcode.RecoveryLevel) ([][]bool, error) {
+	code, err := qrcode.New(content, level)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding qr code: %w", err)
+	}
+	code.DisableBorder = true
+	return code.Bitmap(), nil
+}
+
+// QrCodePng renders content as a PNG of options.Size pixels. Modules are whole pixels, the
+// rest of the size is added to the margin.
+func QrCodePng(content string, options QrCodeOptions) ([]byte, error) {
+	modules, err := qrModules(content, options.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	totalModules := len(modules) + 2*options.Margin
+	size := max(options.Size, totalModules)
+	moduleSize := size / totalModules
+	offset := (size-moduleSize*totalModules)/2 + options.Margin*moduleSize
+
+	var img draw.Image
+	if options.Logo == nil {
+		img = image.NewPaletted(image.Rect(0, 0, size, size), color.Palette{options.Background, options.Foreground})
+	} else {
+		img = image.NewNRGBA(image.Rect(0, 0, size, size))
+	}
+	draw.Draw(img, img.Bounds(), image.NewUniform(options.Background), image.Point{}, draw.Src)
+
+	foreground := image.NewUniform(options.Foreground)
+	for y, row := range modules {
+		for x, dark := range row {
+			if dark {
+				rect := image.Rect(0, 0, moduleSize, moduleSize).Add(image.Pt(offset+x*moduleSize, offset+y*moduleSize))
+				draw.Draw(img, rect, foreground, image.Point{}, draw.Src)
+			}
+		}
+	}
+
+	if options.Logo != nil {
+		codeSize := len(modules) * moduleSize
+		logo := scaleImage(options.Logo, int(float64(codeSize)*qrLogoMaxFraction))
+		logoRect := logo.Bounds().Add(image.Pt(offset+(codeSize-logo.Bounds().Dx())/2, offset+(codeSize-logo.Bounds().Dy())/2))
+		// a padding of one module keeps the logo apart from the modules around it
+		draw.Draw(img, logoRect.Inset(-moduleSize), image.NewUniform(options.Background), image.Point{}, draw.Src)
+		draw.Draw(img, logoRect, logo, logo.Bounds().Min, draw.Over)
+	}
+
+	var buff bytes.Buffer
+	if err := png.Encode(&buff, img); err != nil {
+		return nil, fmt.Errorf("error encoding png: %w", err)
+	}
+	return buff.Bytes(), nil
+}
+
+// QrCodeSvg renders content as an SVG with one unit per module, sized to options.Size pixels.
+func QrCodeSvg(content string, options QrCodeOptions) ([]byte, error) {
+	modules, err := qrModules(content, options.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	totalModules := len(modules) + 2*options.Margin
+
+	var buff bytes.Buffer
+	fmt.Fprintf(&buff, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="%d" height="%d" shape-rendering="crispEdges">`,
+		totalModules, totalModules, options.Size, options.Size)
+	fmt.Fprintf(&buff, `<rect width="%d" height="%d" %s/>`, totalModules, totalModules, svgFill(options.Background))
+
+	// dark modules of each row are merged into runs
+	fmt.Fprintf(&buff, `<path %s d="`, svgFill(options.Foreground))
+	for y, row := range modules {
+		for x := 0; x < len(row); {
+			if !row[x] {
+				x++
+				continue
+			}
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			fmt.Fprintf(&buff, "M%d %dh%dv1h-%dz", start+options.Margin, y+options.Margin, x-start, x-start)
+		}
+	}
+	buff.WriteString(`"/>`)
+
+	if options.Logo != nil {
+		var logoPng bytes.Buffer
+		if err := png.Encode(&logoPng, options.Logo); err != nil {
+			return nil, fmt.Errorf("error encoding logo: %w", err)
+		}
+
+		bounds := options.Logo.Bounds()
+		logoSize := float64(len(modules)) * qrLogoMaxFraction
+		width, height := logoSize, logoSize
+		if bounds.Dx() > bounds.Dy() {
+			height = logoSize * float64(bounds.Dy()) / float64(bounds.Dx())
+		} else {
+			width = logoSize * float64(bounds.Dx()) / float64(bounds.Dy())
+		}
+		x := float64(totalModules)/2 - width/2
+		y := float64(totalModules)/2 - height/2
+
+		fmt.Fprintf(&buff, `<rect x="%g" y="%g" width="%g" height="%g" %s/>`, x-1, y-1, width+2, height+2, svgFill(options.Background))
+		fmt.Fprintf(&buff, `<image x="%g" y="%g" width="%g" height="%g" href="data:image/png;base64,%s"/>`,
+			x, y, width, height, base64.StdEncoding.EncodeToString(logoPng.Bytes()))
+	}
+
+	buff.WriteString(`</svg>`)
+	return buff.Bytes(), nil
+}
+
+func svgFill(c color.Color) string {
+	nrgba := color.NRGBAModel.Convert(c).(color.NRGBA)
+	fill := fmt.Sprintf(`fill="#%02x%02x%02x"`, nrgba.R, nrgba.G, nrgba.B)
+	if nrgba.A != 255 {
+		fill += fmt.Sprintf(` fill-opacity="%.3g"`, float64(nrgba.A)/255)
+	}
+	return fill
+}
+
+// scaleImage fits img in a square of maxSize pixels, keeping its aspect ratio. Nearest neighbor
+// scaling is enough for logos this small.
+func scaleImage(img image.Image, maxSize int) image.Image {
+	bounds := img.Bounds()
+	width, height := maxSize, maxSize
+	if bounds.Dx() > bounds.Dy() {
+		height = max(maxSize*bounds.Dy()/bounds.Dx(), 1)
+	} else {
+		width = max(maxSize*bounds.Dx()/bounds.Dy(), 1)
+	}
+
+	scaled := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			scaled.Set(x, y, img.At(bounds.Min.X+x*bounds.Dx()/width, bounds.Min.Y+y*bounds.Dy()/height))
+		}
+	}
+	return scaled
+}
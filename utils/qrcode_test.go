@@ -0,0 +1,140 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/skip2/go-qrcode"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHexColor(t *testing.T) {
+	tests := []struct {
+		value string
+		want  color.NRGBA
+		valid bool
+	}{
+		{"000000", color.NRGBA{0, 0, 0, 255}, true},
+		{"#ff8800", color.NRGBA{255, 136, 0, 255}, true},
+		{"f80", color.NRGBA{255, 136, 0, 255}, true},
+		{"ff880080", color.NRGBA{255, 136, 0, 128}, true},
+		{"FFFFFF", color.NRGBA{255, 255, 255, 255}, true},
+		{"", color.NRGBA{}, false},
+		{"red", color.NRGBA{}, false},
+		{"ff88", color.NRGBA{}, false},
+		{"gg0000", color.NRGBA{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseHexColor(tt.value)
+			if tt.valid {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestParseQrRecoveryLevel(t *testing.T) {
+	for level, want := range map[string]qrcode.RecoveryLevel{"L": qrcode.Low, "m": qrcode.Medium, "Q": qrcode.High, "H": qrcode.Highest} {
+		got, err := ParseQrRecoveryLevel(level)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+	_, err := ParseQrRecoveryLevel("X")
+	assert.Error(t, err)
+}
+
+var (
+	black = color.NRGBA{0, 0, 0, 255}
+	white = color.NRGBA{255, 255, 255, 255}
+	red   = color.NRGBA{255, 0, 0, 255}
+)
+
+func decodePng(t *testing.T, data []byte) image.Image {
+	img, err := png.Decode(bytes.NewReader(data))
+	assert.NoError(t, err)
+	return img
+}
+
+func colorAt(img image.Image, x, y int) color.NRGBA {
+	return color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+}
+
+func TestQrCodePng(t *testing.T) {
+	const content = "https://sho.rt/urls/abc?src=qr"
+	modules, err := qrModules(content, qrcode.Medium)
+	assert.NoError(t, err)
+
+	t.Run("one pixel per module", func(t *testing.T) {
+		data, err := QrCodePng(content, QrCodeOptions{Margin: 4, Level: qrcode.Medium, Foreground: black, Background: white})
+		assert.NoError(t, err)
+
+		img := decodePng(t, data)
+		assert.Equal(t, len(modules)+8, img.Bounds().Dx())
+		assert.Equal(t, white, colorAt(img, 3, 3))
+		// corner of the top left finder pattern
+		assert.Equal(t, black, colorAt(img, 4, 4))
+	})
+
+	t.Run("size and colors", func(t *testing.T) {
+		data, err := QrCodePng(content, QrCodeOptions{Size: 300, Margin: 0, Level: qrcode.Medium, Foreground: red, Background: black})
+		assert.NoError(t, err)
+
+		img := decodePng(t, data)
+		assert.Equal(t, 300, img.Bounds().Dx())
+		assert.Equal(t, 300, img.Bounds().Dy())
+		moduleSize := 300 / len(modules)
+		offset := (300 - moduleSize*len(modules)) / 2
+		assert.Equal(t, black, colorAt(img, offset-1, offset-1))
+		assert.Equal(t, red, colorAt(img, offset, offset))
+		assert.Equal(t, red, colorAt(img, offset+moduleSize-1, offset+moduleSize-1))
+	})
+
+	t.Run("logo", func(t *testing.T) {
+		logo := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+		for y := range 20 {
+			for x := range 40 {
+				logo.Set(x, y, red)
+			}
+		}
+
+		data, err := QrCodePng(content, QrCodeOptions{Size: 400, Margin: 4, Level: qrcode.Highest, Foreground: black, Background: white, Logo: logo})
+		assert.NoError(t, err)
+
+		img := decodePng(t, data)
+		assert.Equal(t, red, colorAt(img, 200, 200))
+		assert.Equal(t, white, colorAt(img, 0, 0))
+	})
+}
+
+func TestQrCodeSvg(t *testing.T) {
+	const content = "https://sho.rt/urls/abc?src=qr"
+	modules, err := qrModules(content, qrcode.Medium)
+	assert.NoError(t, err)
+	total := len(modules) + 2
+
+	data, err := QrCodeSvg(content, QrCodeOptions{Size: 256, Margin: 1, Level: qrcode.Medium, Foreground: red, Background: color.NRGBA{255, 255, 255, 0}})
+	assert.NoError(t, err)
+
+	svg := string(data)
+	assert.True(t, strings.HasPrefix(svg, "<svg "))
+	assert.True(t, strings.HasSuffix(svg, "</svg>"))
+	assert.Contains(t, svg, fmt.Sprintf(`viewBox="0 0 %d %d" width="256" height="256"`, total, total))
+	assert.Contains(t, svg, `fill="#ffffff" fill-opacity="0"`)
+	assert.Contains(t, svg, `<path fill="#ff0000" d="M1 1h7v1h-7z`) // top row of the finder pattern
+	assert.NotContains(t, svg, "<image")
+
+	logo := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	data, err = QrCodeSvg(content, QrCodeOptions{Size: 256, Margin: 1, Level: qrcode.Highest, Foreground: black, Background: white, Logo: logo})
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `href="data:image/png;base64,`)
+}
@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	Reset     time.Duration // time left until the current window ends
+}
+
+// SlidingWindowResult approximates the number of hits in the last window by weighting the hits
+// of the previous fixed window with how much of it still overlaps the sliding one.
+// currHits must already include the hit being checked.
+func SlidingWindowResult(limit int, window time.Duration, now time.Time, prevHits, currHits int64) RateLimitResult {
+	elapsed := time.Duration(now.UnixNano() % int64(window))
+	weight := float64(window-elapsed) / float64(window)
+	rate := int(math.Ceil(float64(prevHits)*weight)) + int(currHits)
+
+	return RateLimitResult{
+		Allowed:   rate <= limit,
+		Limit:     limit,
+		Remaining: max(limit-rate, 0),
+		Reset:     window - elapsed,
+	}
+}
+
+// SlidingWindowIndex returns the index of the fixed window that contains now.
+func SlidingWindowIndex(window time.Duration, now time.Time) int64 {
+	return now.UnixNano() / int64(window)
+}
+
+// MemoryRateLimiter is a process local sliding window limiter. It is meant as a fallback
+// when the shared store is not reachable, so limits are per process while it is in use.
+type MemoryRateLimiter struct {
+	mu      sync.Mutex
+	windows map[string]*memoryWindow
+}
+
+type memoryWindow struct {
+	window   time.Duration
+	index    int64
+	prevHits int64
+	currHits int64
+}
+
+func NewMemoryRateLimiter() *MemoryRateLimiter {
+	return &MemoryRateLimiter{windows: map[string]*memoryWindow{}}
+}
+
+func (me *MemoryRateLimiter) Hit(key string, limit int, window time.Duration, now time.Time) RateLimitResult {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	index := SlidingWindowIndex(window, now)
+	it, ok := me.windows[key]
+	if !ok || it.window != window {
+		it = &memoryWindow{window: window, index: index}
+		me.windows[key] = it
+	}
+
+	switch index - it.index {
+	case 0:
+	case 1:
+		it.prevHits, it.currHits = it.currHits, 0
+	default:
+		it.prevHits, it.currHits = 0, 0
+	}
+	it.index = index
+	it.currHits += 1
+
+	return SlidingWindowResult(limit, window, now, it.prevHits, it.currHits)
+}
+
+// DeleteExpired removes keys that have no hits in the current or the previous window.
+func (me *MemoryRateLimiter) DeleteExpired(now time.Time) {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	for key, it := range me.windows {
+		if SlidingWindowIndex(it.window, now)-it.index > 1 {
+			delete(me.windows, key)
+		}
+	}
+}
+
+func (me *MemoryRateLimiter) Len() int {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+	return len(me.windows)
+}
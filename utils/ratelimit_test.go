@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlidingWindowResult(t *testing.T) {
+	window := time.Minute
+	windowStart := time.Unix(0, 0).Add(1000 * window)
+
+	tests := []struct {
+		name      string
+		elapsed   time.Duration
+		prevHits  int64
+		currHits  int64
+		allowed   bool
+		remaining int
+	}{
+		{"first hit", 0, 0, 1, true, 9},
+		{"at limit", 30 * time.Second, 0, 10, true, 0},
+		{"over limit", 30 * time.Second, 0, 11, false, 0},
+		{"previous window fully weighted", 0, 10, 1, false, 0},
+		{"previous window half weighted", 30 * time.Second, 10, 5, true, 0},
+		{"previous window almost gone", 59 * time.Second, 10, 5, true, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := SlidingWindowResult(10, window, windowStart.Add(tt.elapsed), tt.prevHits, tt.currHits)
+			assert.Equal(t, tt.allowed, result.Allowed)
+			assert.Equal(t, tt.remaining, result.Remaining)
+			assert.Equal(t, 10, result.Limit)
+			assert.Equal(t, window-tt.elapsed, result.Reset)
+		})
+	}
+}
+
+func TestMemoryRateLimiter(t *testing.T) {
+	window := time.Minute
+	now := time.Unix(0, 0).Add(1000 * window)
+
+	t.Run("limits per key", func(t *testing.T) {
+		limiter := NewMemoryRateLimiter()
+		for range 3 {
+			assert.True(t, limiter.Hit("a", 3, window, now).Allowed)
+		}
+		assert.False(t, limiter.Hit("a", 3, window, now).Allowed)
+		assert.True(t, limiter.Hit("b", 3, window, now).Allowed)
+	})
+
+	t.Run("slides into next window", func(t *testing.T) {
+		limiter := NewMemoryRateLimiter()
+		for range 3 {
+			limiter.Hit("a", 3, window, now)
+		}
+		assert.False(t, limiter.Hit("a", 3, window, now.Add(window)).Allowed)
+		assert.True(t, limiter.Hit("a", 3, window, now.Add(3*window)).Allowed)
+	})
+
+	t.Run("deletes expired keys", func(t *testing.T) {
+		limiter := NewMemoryRateLimiter()
+		limiter.Hit("a", 3, window, now)
+		limiter.DeleteExpired(now.Add(window))
+		assert.Equal(t, 1, limiter.Len())
+		limiter.DeleteExpired(now.Add(2 * window))
+		assert.Equal(t, 0, limiter.Len())
+	})
+}
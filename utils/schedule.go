@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"slices"
+	"time"
+)
+
+// states a short url can be scheduled to
+const (
+	ScheduleStateActive     = "active"      // redirects, to the entry long url when set
+	ScheduleStateComingSoon = "coming-soon" // shows a coming soon page
+	ScheduleStateExpired    = "expired"     // shows a gone page
+)
+
+// ScheduleEntry puts a short url in State starting At, until the next entry.
+type ScheduleEntry struct {
+	At      time.Time `json:"at" validate:"required"`
+	State   string    `json:"state" validate:"required,oneof=active coming-soon expired"`
+	LongUrl string    `json:"longUrl,omitempty" validate:"omitempty,url,excluded_unless=State active"`
+}
+
+// SortSchedule sorts entries by time.
+func SortSchedule(entries []ScheduleEntry) {
+	slices.SortFunc(entries, func(a, b ScheduleEntry) int { return a.At.Compare(b.At) })
+}
+
+// CurrentScheduleEntry returns the latest entry of the sorted entries that started at now.
+// It returns false before the first entry, when the short url behaves as if it had no schedule.
+func CurrentScheduleEntry(entries []ScheduleEntry, now time.Time) (ScheduleEntry, bool) {
+	index, found := slices.BinarySearchFunc(entries, now, func(it ScheduleEntry, t time.Time) int { return it.At.Compare(t) })
+	if found {
+		// entries can't share a time, so this is the one starting exactly now
+		return entries[index], true
+	}
+	if index == 0 {
+		return ScheduleEntry{}, false
+	}
+	return entries[index-1], true
+}
+
+// NextScheduleBoundary returns the time of the first of the sorted entries that starts after now.
+func NextScheduleBoundary(entries []ScheduleEntry, now time.Time) (time.Time, bool) {
+	for _, it := range entries {
+		if it.At.After(now) {
+			return it.At, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedule(t *testing.T) {
+	launch := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	entries := []ScheduleEntry{
+		{At: launch.Add(30 * 24 * time.Hour), State: ScheduleStateActive, LongUrl: "https://example.com/v2"},
+		{At: launch.Add(-7 * 24 * time.Hour), State: ScheduleStateComingSoon},
+		{At: launch, State: ScheduleStateActive},
+	}
+	SortSchedule(entries)
+
+	tests := []struct {
+		name    string
+		now     time.Time
+		state   string
+		ok      bool
+		next    time.Time
+		nextOk  bool
+		longUrl string
+	}{
+		{"before schedule", launch.Add(-8 * 24 * time.Hour), "", false, launch.Add(-7 * 24 * time.Hour), true, ""},
+		{"coming soon", launch.Add(-time.Second), ScheduleStateComingSoon, true, launch, true, ""},
+		{"at launch", launch, ScheduleStateActive, true, launch.Add(30 * 24 * time.Hour), true, ""},
+		{"new destination", launch.Add(31 * 24 * time.Hour), ScheduleStateActive, true, time.Time{}, false, "https://example.com/v2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, ok := CurrentScheduleEntry(entries, tt.now)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.state, entry.State)
+			assert.Equal(t, tt.longUrl, entry.LongUrl)
+
+			next, nextOk := NextScheduleBoundary(entries, tt.now)
+			assert.Equal(t, tt.nextOk, nextOk)
+			assert.Equal(t, tt.next, next)
+		})
+	}
+}
+
+func TestScheduleEntryValidation(t *testing.T) {
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	assert.NoError(t, ValidateStruct(ScheduleEntry{At: at, State: ScheduleStateActive, LongUrl: "https://example.com"}))
+	assert.NoError(t, ValidateStruct(ScheduleEntry{At: at, State: ScheduleStateExpired}))
+	assert.Error(t, ValidateStruct(ScheduleEntry{At: at, State: ScheduleStateComingSoon, LongUrl: "https://example.com"}))
+	assert.Error(t, ValidateStruct(ScheduleEntry{At: at, State: "paused"}))
+	assert.Error(t, ValidateStruct(ScheduleEntry{State: ScheduleStateActive}))
+}
@@ -0,0 +1,179 @@
+package utils
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// devices a routing rule can target
+const (
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceDesktop = "desktop"
+)
+
+// operating systems a routing rule can target
+const (
+	OsIos     = "ios"
+	OsAndroid = "android"
+	OsWindows = "windows"
+	OsMacos   = "macos"
+	OsLinux   = "linux"
+)
+
+// CountryGroupEu can be used in the countries of a routing rule to match all EU member states.
+const CountryGroupEu = "EU"
+
+var euCountries = []string{
+	"AT", "BE", "BG", "HR", "CY", "CZ", "DK", "EE", "FI", "FR", "DE", "GR", "HU", "IE",
+	"IT", "LV", "LT", "LU", "MT", "NL", "PL", "PT", "RO", "SK", "SI", "ES", "SE",
+}
+
+// RoutingRule sends visitors matching all of its non empty conditions to LongUrl.
+type RoutingRule struct {
+	Device    string   `json:"device,omitempty" validate:"omitempty,oneof=mobile tablet desktop"`
+	Os        string   `json:"os,omitempty" validate:"omitempty,oneof=ios android windows macos linux"`
+	Language  string   `json:"language,omitempty" validate:"omitempty,bcp47_language_tag,max=35"` // e.g. "fr" matches "fr" and "fr-CA"
+	Countries []string `json:"countries,omitempty" validate:"max=50,dive,iso3166_1_alpha2|eq=EU"` // matches any of them
+	LongUrl   string   `json:"longUrl" validate:"required,url"`
+}
+
+// VisitorAttributes are the request attributes routing rules are evaluated against.
+type VisitorAttributes struct {
+	Device   string
+	Os       string
+	Language string // most preferred language, lower cased
+	Country  string // ISO 3166-1 alpha-2 code, empty when unknown
+}
+
+// NewVisitorAttributes reads the attributes from the User-Agent and Accept-Language headers.
+// The country is resolved by the caller from the visitor IP.
+func NewVisitorAttributes(userAgent string, acceptLanguage string, country string) VisitorAttributes {
+	device, os := ParseUserAgent(userAgent)
+	return VisitorAttributes{
+		Device:   device,
+		Os:       os,
+		Language: PreferredLanguage(acceptLanguage),
+		Country:  strings.ToUpper(country),
+	}
+}
+
+// ParseUserAgent detects the device type and the os of a User-Agent. The os is empty when unknown.
+func ParseUserAgent(userAgent string) (device string, os string) {
+	switch {
+	case strings.Contains(userAgent, "iPhone"), strings.Contains(userAgent, "iPad"), strings.Contains(userAgent, "iPod"):
+		os = OsIos
+	case strings.Contains(userAgent, "Android"):
+		os = OsAndroid
+	case strings.Contains(userAgent, "Windows"):
+		os = OsWindows
+	case strings.Contains(userAgent, "Macintosh"), strings.Contains(userAgent, "Mac OS X"):
+		os = OsMacos
+	case strings.Contains(userAgent, "Linux"), strings.Contains(userAgent, "X11"):
+		os = OsLinux
+	}
+
+	switch {
+	case strings.Contains(userAgent, "iPad"), strings.Contains(userAgent, "Tablet"),
+		os == OsAndroid && !strings.Contains(userAgent, "Mobile"):
+		device = DeviceTablet
+	case strings.Contains(userAgent, "Mobi"), strings.Contains(userAgent, "iPhone"), strings.Contains(userAgent, "iPod"):
+		device = DeviceMobile
+	default:
+		device = DeviceDesktop
+	}
+
+	return device, os
+}
+
+// PreferredLanguage returns the lower cased language with the highest weight in an Accept-Language
+// header, or an empty string when there is none. Ties keep the header order.
+func PreferredLanguage(acceptLanguage string) string {
+	type weighted struct {
+		tag    string
+		weight float64
+	}
+
+	languages := []weighted{}
+	for it := range strings.SplitSeq(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(it, ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		weight := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			weight = parsed
+		}
+		if weight > 0 {
+			languages = append(languages, weighted{tag, weight})
+		}
+	}
+
+	if len(languages) == 0 {
+		return ""
+	}
+	slices.SortStableFunc(languages, func(a, b weighted) int {
+		switch {
+		case a.weight > b.weight:
+			return -1
+		case a.weight < b.weight:
+			return 1
+		}
+		return 0
+	})
+	return languages[0].tag
+}
+
+func (me RoutingRule) Matches(attrs VisitorAttributes) bool {
+	if me.Device != "" && me.Device != attrs.Device {
+		return false
+	}
+	if me.Os != "" && me.Os != attrs.Os {
+		return false
+	}
+	if me.Language != "" {
+		language := strings.ToLower(me.Language)
+		if attrs.Language != language && !strings.HasPrefix(attrs.Language, language+"-") {
+			return false
+		}
+	}
+	if len(me.Countries) != 0 && !me.matchesCountry(attrs.Country) {
+		return false
+	}
+	return true
+}
+
+func (me RoutingRule) matchesCountry(country string) bool {
+	if country == "" {
+		return false
+	}
+	for _, it := range me.Countries {
+		if it == country || (it == CountryGroupEu && slices.Contains(euCountries, country)) {
+			return true
+		}
+	}
+	return false
+}
+
+// UsesCountries reports whether any of the rules has a country condition, so the
+// visitor country only needs to be looked up then.
+func UsesCountries(rules []RoutingRule) bool {
+	return slices.ContainsFunc(rules, func(it RoutingRule) bool { return len(it.Countries) != 0 })
+}
+
+// MatchRoutingRules returns the long url of the first rule matching attrs.
+func MatchRoutingRules(rules []RoutingRule, attrs VisitorAttributes) (string, bool) {
+	for _, it := range rules {
+		if it.Matches(attrs) {
+			return it.LongUrl, true
+		}
+	}
+	return "", false
+}
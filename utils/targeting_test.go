@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseUserAgent(t *testing.T) {
+	tests := []struct {
+		name      string
+		userAgent string
+		device    string
+		os        string
+	}{
+		{"iphone", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148", DeviceMobile, OsIos},
+		{"ipad", "Mozilla/5.0 (iPad; CPU OS 17_5 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148", DeviceTablet, OsIos},
+		{"android phone", "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 Chrome/126.0 Mobile Safari/537.36", DeviceMobile, OsAndroid},
+		{"android tablet", "Mozilla/5.0 (Linux; Android 14; SM-X710) AppleWebKit/537.36 Chrome/126.0 Safari/537.36", DeviceTablet, OsAndroid},
+		{"windows", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/126.0 Safari/537.36", DeviceDesktop, OsWindows},
+		{"macos", "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_5) AppleWebKit/605.1.15 Version/17.5 Safari/605.1.15", DeviceDesktop, OsMacos},
+		{"linux", "Mozilla/5.0 (X11; Linux x86_64; rv:127.0) Gecko/20100101 Firefox/127.0", DeviceDesktop, OsLinux},
+		{"unknown", "curl/8.8.0", DeviceDesktop, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			device, os := ParseUserAgent(tt.userAgent)
+			assert.Equal(t, tt.device, device)
+			assert.Equal(t, tt.os, os)
+		})
+	}
+}
+
+func TestPreferredLanguage(t *testing.T) {
+	tests := []struct {
+		acceptLanguage string
+		want           string
+	}{
+		{"", ""},
+		{"*", ""},
+		{"fr-CA", "fr-ca"},
+		{"en;q=0.8, de", "de"},
+		{"de-AT, de;q=0.9, en;q=0.8", "de-at"},
+		{"en, fr", "en"},
+		{"fr;q=0, en;q=0.1", "en"},
+		{"fr;q=abc, en;q=0.5", "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.acceptLanguage, func(t *testing.T) {
+			assert.Equal(t, tt.want, PreferredLanguage(tt.acceptLanguage))
+		})
+	}
+}
+
+func TestMatchRoutingRules(t *testing.T) {
+	rules := []RoutingRule{
+		{Os: OsIos, LongUrl: "https://apps.apple.com/app"},
+		{Os: OsAndroid, LongUrl: "https://play.google.com/app"},
+		{Device: DeviceDesktop, Language: "fr", LongUrl: "https://example.com/fr"},
+		{Language: "pt-BR", LongUrl: "https://example.com/pt-br"},
+		{Countries: []string{CountryGroupEu, "CH"}, LongUrl: "https://example.com/gdpr"},
+	}
+
+	tests := []struct {
+		name  string
+		attrs VisitorAttributes
+		want  string
+		ok    bool
+	}{
+		{"ios", VisitorAttributes{Device: DeviceMobile, Os: OsIos, Language: "fr"}, "https://apps.apple.com/app", true},
+		{"android", VisitorAttributes{Device: DeviceTablet, Os: OsAndroid}, "https://play.google.com/app", true},
+		{"language prefix", VisitorAttributes{Device: DeviceDesktop, Os: OsWindows, Language: "fr-ca"}, "https://example.com/fr", true},
+		{"language needs all conditions", VisitorAttributes{Device: DeviceMobile, Language: "fr"}, "", false},
+		{"language with region", VisitorAttributes{Device: DeviceDesktop, Language: "pt-br"}, "https://example.com/pt-br", true},
+		{"other region", VisitorAttributes{Device: DeviceDesktop, Language: "pt-pt"}, "", false},
+		{"eu country", VisitorAttributes{Device: DeviceDesktop, Language: "en", Country: "DE"}, "https://example.com/gdpr", true},
+		{"listed country", VisitorAttributes{Device: DeviceDesktop, Country: "CH"}, "https://example.com/gdpr", true},
+		{"other country", VisitorAttributes{Device: DeviceDesktop, Country: "US"}, "", false},
+		{"unknown country", VisitorAttributes{Device: DeviceDesktop}, "", false},
+		{"no match", VisitorAttributes{Device: DeviceDesktop, Os: OsLinux, Language: "en"}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := MatchRoutingRules(rules, tt.attrs)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"fmt"
+	"net/url"
+)
+
+type UtmParams struct {
+	Source   string
+	Medium   string
+	Campaign string
+	Term     string
+	Content  string
+}
+
+// AppendUtmParams sets the non empty utm parameters on the query of longUrl,
+// replacing the ones it already has.
+func AppendUtmParams(longUrl string, params UtmParams) (string, error) {
+	destination, err := url.Parse(longUrl)
+	if err != nil {
+		return "", fmt.Errorf("invalid long url: %w", err)
+	}
+
+	query := destination.Query()
+	for key, value := range map[string]string{
+		"utm_source":   params.Source,
+		"utm_medium":   params.Medium,
+		"utm_campaign": params.Campaign,
+		"utm_term":     params.Term,
+		"utm_content":  params.Content,
+	} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	destination.RawQuery = query.Encode()
+
+	return destination.String(), nil
+}
@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendUtmParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		longUrl string
+		params  UtmParams
+		want    string
+	}{
+		{"no query", "https://example.com/sale", UtmParams{Source: "newsletter", Medium: "email"}, "https://example.com/sale?utm_medium=email&utm_source=newsletter"},
+		{"keeps other params", "https://example.com/?ref=1", UtmParams{Source: "x"}, "https://example.com/?ref=1&utm_source=x"},
+		{"replaces utm params", "https://example.com/?utm_source=old&utm_term=shoes", UtmParams{Source: "new"}, "https://example.com/?utm_source=new&utm_term=shoes"},
+		{"escapes values", "https://example.com/", UtmParams{Source: "x", Campaign: "spring sale"}, "https://example.com/?utm_campaign=spring+sale&utm_source=x"},
+		{"all params", "https://example.com/", UtmParams{"s", "m", "c", "t", "co"}, "https://example.com/?utm_campaign=c&utm_content=co&utm_medium=m&utm_source=s&utm_term=t"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AppendUtmParams(tt.longUrl, tt.params)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -1,9 +1,9 @@
 package utils
 
 import (
-	"errors"
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
@@ -14,12 +14,19 @@ var (
 	usernameRegex      = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
 	noOuterSpacesRegex = regexp.MustCompile(`^\S.*\S$|^\S+$`)
 	shortUrlRegex      = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+	slugRegex          = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
 )
 
+// ReservedShortUrls are the first path segments of other routes, short urls are mounted at the root
+// next to them. Matched case-insensitively.
+var ReservedShortUrls = []string{"admin", "analytics", "api", "campaigns", "domains", "healthz", "urls", "users"}
+
 func init() {
 	validatorInstance.RegisterValidation("customUsername", customUsername)
 	validatorInstance.RegisterValidation("customNoOuterSpaces", customNoOuterSpaces)
 	validatorInstance.RegisterValidation("customShortUrl", customShortUrl)
+	validatorInstance.RegisterValidation("customSlug", customSlug)
+	validatorInstance.RegisterValidation("customNotReserved", customNotReserved)
 }
 
 func customUsername(fl validator.FieldLevel) bool {
@@ -37,13 +44,60 @@ func customShortUrl(fl validator.FieldLevel) bool {
 	return val == "" || shortUrlRegex.MatchString(val)
 }
 
+func customSlug(fl validator.FieldLevel) bool {
+	val := fl.Field().String()
+	return val == "" || slugRegex.MatchString(val)
+}
+
+func customNotReserved(fl validator.FieldLevel) bool {
+	val := strings.ToLower(fl.Field().String())
+	return !slices.Contains(ReservedShortUrls, val)
+}
+
+// FieldError is a constraint a field of a validated struct violates.
+type FieldError struct {
+	Field      string `json:"field"`           // path of the field with lower cased names, e.g. rules[0].longUrl
+	Constraint string `json:"constraint"`      // e.g. required
+	Param      string `json:"param,omitempty"` // of the constraint, e.g. 50 for max=50
+
+	structField string // e.g. LongUrl, named in Error
+}
+
+// ValidationErrors is the error of ValidateStruct, with an item for each violated constraint.
+type ValidationErrors []FieldError
+
+func (me ValidationErrors) Error() string {
+	errs := make([]string, len(me))
+	for i, it := range me {
+		errs[i] = fmt.Sprintf("%s: violation in constraint '%s'", it.structField, it.Constraint)
+	}
+	return strings.Join(errs, ";")
+}
+
+// ValidateStruct returns ValidationErrors when s violates its constraints.
 func ValidateStruct(s any) error {
 	if err := validatorInstance.Struct(s); err != nil {
-		errs := []string{}
+		errs := ValidationErrors{}
 		for _, err := range err.(validator.ValidationErrors) {
-			errs = append(errs, fmt.Sprintf("%s: violation in constraint '%s'", err.Field(), err.Tag()))
+			errs = append(errs, FieldError{
+				Field:      fieldPath(err.Namespace()),
+				Constraint: err.Tag(),
+				Param:      err.Param(),
+
+				structField: err.Field(),
+			})
 		}
-		return errors.New(strings.Join(errs, ";"))
+		return errs
 	}
 	return nil
 }
+
+// fieldPath turns a namespace like CreateShortUrlParams.Rules[0].LongUrl into rules[0].longUrl,
+// the names used in json bodies.
+func fieldPath(namespace string) string {
+	segments := strings.Split(namespace, ".")[1:]
+	for i, it := range segments {
+		segments[i] = strings.ToLower(it[:1]) + it[1:]
+	}
+	return strings.Join(segments, ".")
+}
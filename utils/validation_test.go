@@ -18,6 +18,14 @@ type testUrl struct {
 	ShortCode string `validate:"customShortUrl"`
 }
 
+type testReservedUrl struct {
+	ShortCode string `validate:"customShortUrl,customNotReserved"`
+}
+
+type testSlug struct {
+	Name string `validate:"customSlug"`
+}
+
 type testMultiField struct {
 	Username  string `validate:"customUsername"`
 	Title     string `validate:"customNoOuterSpaces"`
@@ -95,6 +103,68 @@ func TestCustomShortUrl(t *testing.T) {
 	}
 }
 
+func TestCustomNotReserved(t *testing.T) {
+	tests := []struct {
+		name      string
+		shortCode string
+		want      bool
+	}{
+		{"valid not reserved", "apis", true},
+		{"valid empty", "", true},
+		{"invalid reserved", "api", false},
+		{"invalid reserved other case", "Healthz", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := testReservedUrl{ShortCode: tt.shortCode}
+			err := ValidateStruct(u)
+			assert.Equal(t, tt.want, err == nil)
+		})
+	}
+}
+
+func TestValidateStructFieldErrors(t *testing.T) {
+	type testRule struct {
+		LongUrl string `validate:"required,url"`
+	}
+	type testParams struct {
+		Name  string     `validate:"max=3"`
+		Rules []testRule `validate:"dive"`
+	}
+
+	err := ValidateStruct(testParams{Name: "abcd", Rules: []testRule{{LongUrl: "https://example.com"}, {}}})
+
+	var fieldErrs ValidationErrors
+	assert.ErrorAs(t, err, &fieldErrs)
+	assert.Equal(t, []FieldError{
+		{Field: "name", Constraint: "max", Param: "3", structField: "Name"},
+		{Field: "rules[1].longUrl", Constraint: "required", structField: "LongUrl"},
+	}, []FieldError(fieldErrs))
+}
+
+func TestCustomSlug(t *testing.T) {
+	tests := []struct {
+		name string
+		slug string
+		want bool
+	}{
+		{"valid alphanumeric", "spring2025", true},
+		{"valid with hyphen and underscore", "spring-sale_eu", true},
+		{"valid empty", "", true},
+		{"invalid with space", "spring sale", false},
+		{"invalid with slash", "spring/sale", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := testSlug{Name: tt.slug}
+			err := ValidateStruct(s)
+			assert.Equal(t, tt.want, err == nil)
+		})
+	}
+}
+
 func TestValidateStruct(t *testing.T) {
 	t.Run("valid struct", func(t *testing.T) {
 		s := testMultiField{
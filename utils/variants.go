@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"hash/fnv"
+)
+
+// Variant is one of the weighted destinations of a short url split test.
+type Variant struct {
+	Name    string `json:"name" validate:"required,customSlug,max=50"`
+	LongUrl string `json:"longUrl" validate:"required,url"`
+	Weight  int    `json:"weight" validate:"min=0,max=1000"` // 0 stops sending new visitors to it
+}
+
+// PickVariant returns the variant a visitor is assigned to. The visitor keeps the variant named
+// sticky (e.g. from a cookie) while it still receives traffic, otherwise one is chosen according
+// to the weights from a hash of visitorKey (e.g. the IP), so the same visitor gets the same variant.
+// It returns false when no variant has a positive weight.
+func PickVariant(variants []Variant, sticky string, visitorKey string) (Variant, bool) {
+	totalWeight := 0
+	for _, it := range variants {
+		if sticky != "" && it.Name == sticky && it.Weight > 0 {
+			return it, true
+		}
+		totalWeight += it.Weight
+	}
+	if totalWeight == 0 {
+		return Variant{}, false
+	}
+
+	hash := fnv.New64a()
+	hash.Write([]byte(visitorKey))
+	point := int(hash.Sum64() % uint64(totalWeight))
+
+	for _, it := range variants {
+		if point < it.Weight {
+			return it, true
+		}
+		point -= it.Weight
+	}
+	panic("unreachable")
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPickVariant(t *testing.T) {
+	variants := []Variant{
+		{Name: "a", LongUrl: "https://example.com/a", Weight: 75},
+		{Name: "b", LongUrl: "https://example.com/b", Weight: 25},
+		{Name: "c", LongUrl: "https://example.com/c", Weight: 0},
+	}
+
+	t.Run("sticky", func(t *testing.T) {
+		got, ok := PickVariant(variants, "b", "1.2.3.4")
+		assert.True(t, ok)
+		assert.Equal(t, "b", got.Name)
+	})
+
+	t.Run("sticky without weight is reassigned", func(t *testing.T) {
+		got, ok := PickVariant(variants, "c", "1.2.3.4")
+		assert.True(t, ok)
+		assert.NotEqual(t, "c", got.Name)
+	})
+
+	t.Run("same visitor gets same variant", func(t *testing.T) {
+		first, _ := PickVariant(variants, "", "short:1.2.3.4")
+		for range 10 {
+			got, _ := PickVariant(variants, "", "short:1.2.3.4")
+			assert.Equal(t, first, got)
+		}
+	})
+
+	t.Run("follows weights", func(t *testing.T) {
+		counts := map[string]int{}
+		for i := range 10_000 {
+			got, _ := PickVariant(variants, "", fmt.Sprintf("10.0.%d.%d", i/256, i%256))
+			counts[got.Name]++
+		}
+		assert.InDelta(t, 7500, counts["a"], 300)
+		assert.InDelta(t, 2500, counts["b"], 300)
+		assert.Zero(t, counts["c"])
+	})
+
+	t.Run("no weights", func(t *testing.T) {
+		_, ok := PickVariant([]Variant{{Name: "a", Weight: 0}}, "", "1.2.3.4")
+		assert.False(t, ok)
+		_, ok = PickVariant(nil, "", "1.2.3.4")
+		assert.False(t, ok)
+	})
+}